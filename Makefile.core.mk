@@ -60,14 +60,18 @@ annotations_path := annotation
 annotations_pb_go := $(annotations_path)/annotations.gen.go
 annotations_pb_doc := $(annotations_path)/annotations.pb.html
 annotations_yaml := $(annotations_path)/annotations.yaml
+annotations_metadata_go := $(annotations_path)/metadata.gen.go
 
 $(annotations_pb_go) $(annotations_pb_doc): $(annotations_yaml)
 	@$(annotations_prep) --input $(annotations_yaml) --output $(annotations_pb_go) --html_output $(annotations_pb_doc) --collection_type annotation
 
-generate-annotations: $(annotations_pb_go) $(annotations_pb_doc)
+$(annotations_metadata_go): $(annotations_yaml) $(annotations_pb_go)
+	@cd kubernetes && go run ./cmd/metadata-gen --input ../$(annotations_yaml) --instances ../$(annotations_pb_go) --output ../$(annotations_metadata_go) --collection_type annotation
+
+generate-annotations: $(annotations_pb_go) $(annotations_pb_doc) $(annotations_metadata_go)
 
 clean-annotations:
-	@rm -fr $(annotations_pb_go) $(annotations_pb_doc)
+	@rm -fr $(annotations_pb_go) $(annotations_pb_doc) $(annotations_metadata_go)
 
 #####################
 # label/...
@@ -77,14 +81,18 @@ labels_path := label
 labels_pb_go := $(labels_path)/labels.gen.go
 labels_pb_doc := $(labels_path)/labels.pb.html
 labels_yaml := $(labels_path)/labels.yaml
+labels_metadata_go := $(labels_path)/metadata.gen.go
 
 $(labels_pb_go) $(labels_pb_doc): $(labels_yaml)
 	@$(annotations_prep) --input $(labels_yaml) --output $(labels_pb_go) --html_output $(labels_pb_doc) --collection_type label
 
-generate-labels: $(labels_pb_go) $(labels_pb_doc)
+$(labels_metadata_go): $(labels_yaml) $(labels_pb_go)
+	@cd kubernetes && go run ./cmd/metadata-gen --input ../$(labels_yaml) --instances ../$(labels_pb_go) --output ../$(labels_metadata_go) --collection_type label
+
+generate-labels: $(labels_pb_go) $(labels_pb_doc) $(labels_metadata_go)
 
 clean-labels:
-	@rm -fr $(labels_pb_go) $(labels_pb_doc)
+	@rm -fr $(labels_pb_go) $(labels_pb_doc) $(labels_metadata_go)
 
 #####################
 # Misc
@@ -100,6 +108,8 @@ lint: lint-dockerfiles lint-scripts lint-yaml lint-helm lint-copyright-banner li
  	@$(htmlproofer) . --url-swap "istio.io:preliminary.istio.io" --assume-extension --check-html --check-external-hash --check-opengraph --timeframe 2d --storage-dir $(repo_dir)/.htmlproofer --url-ignore "/localhost/"
 
 test: breaking
+	go test ./...
+	(pushd kubernetes && go test -v ./...)
 	(pushd tests && go test -v ./...)
 
 fmt: format-python
@@ -0,0 +1,338 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// istioCodePrefix is the prefix of the codes of the messages reported by Istio's analyzers, which
+// are documented on istio.io. Codes with another prefix, such as those of the route analyzer, have
+// no documentation URL.
+const istioCodePrefix = "IST"
+
+var (
+	codeRegexp             = regexp.MustCompile(`^[A-Z]{3}[0-9]{4}$`)
+	documentationURLRegexp = regexp.MustCompile(`^http(s)?://(preliminary\.)?istio.io/docs/reference/config/analysis/`)
+)
+
+var catalog = struct {
+	sync.RWMutex
+	once  sync.Once
+	types map[string]*AnalysisMessageWeakSchema
+}{types: map[string]*AnalysisMessageWeakSchema{}}
+
+// loadBuiltins registers the built-in message types. It is called lazily, as the message
+// descriptors are not initialized yet when the init functions of this file run.
+func loadBuiltins() {
+	catalog.once.Do(func() {
+		for _, s := range builtinMessageTypes() {
+			if err := validateSchema(s); err != nil {
+				panic(err)
+			}
+			catalog.types[s.GetMessageBase().GetType().GetCode()] = s
+		}
+	})
+}
+
+// RegisterMessageType adds a message type to the catalog, so that messages of this type can be
+// constructed and rendered. The code, level and template of the schema are validated, and the
+// template must have one verb per argument. Codes starting with IST must link to their page on
+// istio.io, and other codes must not.
+func RegisterMessageType(s *AnalysisMessageWeakSchema) error {
+	if err := validateSchema(s); err != nil {
+		return err
+	}
+	loadBuiltins()
+	catalog.Lock()
+	defer catalog.Unlock()
+	code := s.GetMessageBase().GetType().GetCode()
+	if _, f := catalog.types[code]; f {
+		return fmt.Errorf("message type %s is already registered", code)
+	}
+	catalog.types[code] = proto.Clone(s).(*AnalysisMessageWeakSchema)
+	return nil
+}
+
+// LookupMessageType returns the schema of the message type with the given code, such as "IST0101".
+func LookupMessageType(code string) (*AnalysisMessageWeakSchema, bool) {
+	loadBuiltins()
+	catalog.RLock()
+	defer catalog.RUnlock()
+	s, f := catalog.types[code]
+	if !f {
+		return nil, false
+	}
+	return proto.Clone(s).(*AnalysisMessageWeakSchema), true
+}
+
+// MessageTypes returns the schemas of all the registered message types, sorted by code.
+func MessageTypes() []*AnalysisMessageWeakSchema {
+	loadBuiltins()
+	catalog.RLock()
+	defer catalog.RUnlock()
+	out := make([]*AnalysisMessageWeakSchema, 0, len(catalog.types))
+	for _, s := range catalog.types {
+		out = append(out, proto.Clone(s).(*AnalysisMessageWeakSchema))
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].GetMessageBase().GetType().GetCode() < out[j].GetMessageBase().GetType().GetCode()
+	})
+	return out
+}
+
+func validateSchema(s *AnalysisMessageWeakSchema) error {
+	base := s.GetMessageBase()
+	code := base.GetType().GetCode()
+	if !codeRegexp.MatchString(code) {
+		return fmt.Errorf("invalid message code %q", code)
+	}
+	if base.GetType().GetName() == "" {
+		return fmt.Errorf("%s: name is required", code)
+	}
+	if base.GetLevel() == AnalysisMessageBase_UNKNOWN {
+		return fmt.Errorf("%s: level is required", code)
+	}
+	if url := base.GetDocumentationUrl(); strings.HasPrefix(code, istioCodePrefix) && !documentationURLRegexp.MatchString(url) {
+		return fmt.Errorf("%s: invalid documentation url %q", code, url)
+	} else if !strings.HasPrefix(code, istioCodePrefix) && documentationURLRegexp.MatchString(url) {
+		return fmt.Errorf("%s: only %s codes are documented on istio.io, got %q", code, istioCodePrefix, url)
+	}
+	if s.GetTemplate() == "" {
+		return fmt.Errorf("%s: template is required", code)
+	}
+	if n := countVerbs(s.GetTemplate()); n != len(s.GetArgs()) {
+		return fmt.Errorf("%s: template has %d verbs but %d args are declared", code, n, len(s.GetArgs()))
+	}
+	names := map[string]bool{}
+	for _, a := range s.GetArgs() {
+		if a.GetName() == "" || a.GetGoType() == "" {
+			return fmt.Errorf("%s: args must have a name and a go type", code)
+		}
+		if names[a.GetName()] {
+			return fmt.Errorf("%s: duplicate arg %q", code, a.GetName())
+		}
+		names[a.GetName()] = true
+	}
+	return nil
+}
+
+// countVerbs returns the number of formatting verbs of a fmt template.
+func countVerbs(template string) int {
+	n := 0
+	for i := 0; i < len(template); i++ {
+		if template[i] != '%' {
+			continue
+		}
+		if i+1 < len(template) && template[i+1] == '%' {
+			i++
+			continue
+		}
+		n++
+	}
+	return n
+}
+
+// NewMessageBase returns the AnalysisMessageBase of the registered message type with the given code.
+func NewMessageBase(code string) (*AnalysisMessageBase, error) {
+	s, f := LookupMessageType(code)
+	if !f {
+		return nil, fmt.Errorf("unknown message type %q", code)
+	}
+	return s.GetMessageBase(), nil
+}
+
+// NewMessage returns a message of the registered type with the given code. args are the arguments
+// of the message type, in the order of its schema, and must match their declared go types.
+// At least one resource path, of the form (NAMESPACE/)?RESOURCETYPE/NAME, is required.
+func NewMessage(code string, resourcePaths []string, args ...any) (*GenericAnalysisMessage, error) {
+	s, f := LookupMessageType(code)
+	if !f {
+		return nil, fmt.Errorf("unknown message type %q", code)
+	}
+	if len(resourcePaths) == 0 {
+		return nil, fmt.Errorf("%s: at least one resource path is required", code)
+	}
+	if len(args) != len(s.GetArgs()) {
+		return nil, fmt.Errorf("%s: got %d args, want %d", code, len(args), len(s.GetArgs()))
+	}
+	fields := map[string]*structpb.Value{}
+	for i, a := range s.GetArgs() {
+		v, err := argValue(a.GetGoType(), args[i])
+		if err != nil {
+			return nil, fmt.Errorf("%s: arg %s: %v", code, a.GetName(), err)
+		}
+		fields[a.GetName()] = v
+	}
+	m := &GenericAnalysisMessage{
+		MessageBase:   s.GetMessageBase(),
+		ResourcePaths: append([]string(nil), resourcePaths...),
+	}
+	if len(fields) > 0 {
+		m.Args = &structpb.Struct{Fields: fields}
+	}
+	return m, nil
+}
+
+// argValue converts an arg to a Value, checking it has the declared go type.
+func argValue(goType string, arg any) (*structpb.Value, error) {
+	if got := fmt.Sprintf("%T", arg); got != goType {
+		switch goType {
+		case "string", "int", "bool", "float64", "[]string", "[]int":
+			return nil, fmt.Errorf("got %s, want %s", got, goType)
+		}
+	}
+	switch a := arg.(type) {
+	case []string:
+		l := make([]any, len(a))
+		for i, e := range a {
+			l[i] = e
+		}
+		arg = l
+	case []int:
+		l := make([]any, len(a))
+		for i, e := range a {
+			l[i] = e
+		}
+		arg = l
+	case fmt.Stringer:
+		arg = a.String()
+	case error:
+		arg = a.Error()
+	}
+	return structpb.NewValue(arg)
+}
+
+// Validate returns an error if m is not a valid message of a registered type: its base must match
+// the registered one, it must have at least one resource path, and its args must match the schema.
+func (x *GenericAnalysisMessage) Validate() error {
+	code := x.GetMessageBase().GetType().GetCode()
+	s, f := LookupMessageType(code)
+	if !f {
+		return fmt.Errorf("unknown message type %q", code)
+	}
+	if !proto.Equal(s.GetMessageBase(), x.GetMessageBase()) {
+		return fmt.Errorf("%s: message base does not match the registered one", code)
+	}
+	if len(x.GetResourcePaths()) == 0 {
+		return fmt.Errorf("%s: at least one resource path is required", code)
+	}
+	_, err := x.args(s)
+	return err
+}
+
+// Text returns the message formatted according to the template of its type.
+func (x *GenericAnalysisMessage) Text() (string, error) {
+	code := x.GetMessageBase().GetType().GetCode()
+	s, f := LookupMessageType(code)
+	if !f {
+		return "", fmt.Errorf("unknown message type %q", code)
+	}
+	args, err := x.args(s)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(s.GetTemplate(), args...), nil
+}
+
+// Render returns a human-readable line describing the message, such as
+//
+//	Error [IST0101] (default/VirtualService/reviews) Referenced host not found: "ratings"
+func (x *GenericAnalysisMessage) Render() (string, error) {
+	text, err := x.Text()
+	if err != nil {
+		return "", err
+	}
+	level := x.GetMessageBase().GetLevel().String()
+	level = level[:1] + strings.ToLower(level[1:])
+	return fmt.Sprintf("%s [%s] (%s) %s", level, x.GetMessageBase().GetType().GetCode(),
+		strings.Join(x.GetResourcePaths(), ", "), text), nil
+}
+
+// args returns the args of x in schema order, converted back to their go types.
+func (x *GenericAnalysisMessage) args(s *AnalysisMessageWeakSchema) ([]any, error) {
+	code := s.GetMessageBase().GetType().GetCode()
+	fields := x.GetArgs().GetFields()
+	if len(fields) != len(s.GetArgs()) {
+		return nil, fmt.Errorf("%s: got %d args, want %d", code, len(fields), len(s.GetArgs()))
+	}
+	out := make([]any, 0, len(s.GetArgs()))
+	for _, a := range s.GetArgs() {
+		v, f := fields[a.GetName()]
+		if !f {
+			return nil, fmt.Errorf("%s: missing arg %s", code, a.GetName())
+		}
+		arg, err := fromValue(a.GetGoType(), v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: arg %s: %v", code, a.GetName(), err)
+		}
+		out = append(out, arg)
+	}
+	return out, nil
+}
+
+// fromValue converts a Value back to its declared go type. Values of other go types are returned as is.
+func fromValue(goType string, v *structpb.Value) (any, error) {
+	switch goType {
+	case "string":
+		if _, ok := v.GetKind().(*structpb.Value_StringValue); !ok {
+			return nil, fmt.Errorf("want a string")
+		}
+		return v.GetStringValue(), nil
+	case "bool":
+		if _, ok := v.GetKind().(*structpb.Value_BoolValue); !ok {
+			return nil, fmt.Errorf("want a bool")
+		}
+		return v.GetBoolValue(), nil
+	case "int", "float64":
+		if _, ok := v.GetKind().(*structpb.Value_NumberValue); !ok {
+			return nil, fmt.Errorf("want a number")
+		}
+		if goType == "int" {
+			return int(v.GetNumberValue()), nil
+		}
+		return v.GetNumberValue(), nil
+	case "[]string", "[]int":
+		l, ok := v.GetKind().(*structpb.Value_ListValue)
+		if !ok {
+			return nil, fmt.Errorf("want a list")
+		}
+		elem := strings.TrimPrefix(goType, "[]")
+		strs, ints := []string{}, []int{}
+		for _, e := range l.ListValue.GetValues() {
+			e, err := fromValue(elem, e)
+			if err != nil {
+				return nil, err
+			}
+			if elem == "int" {
+				ints = append(ints, e.(int))
+			} else {
+				strs = append(strs, e.(string))
+			}
+		}
+		if elem == "int" {
+			return ints, nil
+		}
+		return strs, nil
+	}
+	return v.AsInterface(), nil
+}
@@ -0,0 +1,114 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNewMessageRender(t *testing.T) {
+	m, err := NewMessage(ReferencedResourceNotFoundCode, []string{"default/VirtualService/reviews"}, "host", "ratings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := m.Render()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `Error [IST0101] (default/VirtualService/reviews) Referenced host not found: "ratings"`; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	// Messages survive a JSON round trip, which turns ints into floats.
+	m, err = NewMessage(VirtualServiceDestinationPortSelectorRequiredCode, []string{"default/VirtualService/reviews"},
+		"reviews", []int{9080, 9090})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := &GenericAnalysisMessage{}
+	if err := json.Unmarshal(b, decoded); err != nil {
+		t.Fatal(err)
+	}
+	text, err := decoded.Text()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text, `service "reviews" that exposes multiple ports [9080 9090]`) {
+		t.Fatalf("unexpected text %q", text)
+	}
+}
+
+func TestNewMessageErrors(t *testing.T) {
+	paths := []string{"default/Pod/reviews"}
+	cases := map[string]func() error{
+		"unknown code": func() error { _, err := NewMessage("IST9999", paths); return err },
+		"no paths":     func() error { _, err := NewMessage(PodMissingProxyCode, nil, "reviews"); return err },
+		"missing arg":  func() error { _, err := NewMessage(PodMissingProxyCode, paths); return err },
+		"wrong type":   func() error { _, err := NewMessage(GatewayPortNotOnWorkloadCode, paths, "app=gw", "80"); return err },
+		"tampered base": func() error {
+			m, _ := NewMessage(PodMissingProxyCode, paths, "reviews")
+			m.MessageBase.Level = AnalysisMessageBase_INFO
+			return m.Validate()
+		},
+	}
+	for name, fn := range cases {
+		if fn() == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestRegisterMessageType(t *testing.T) {
+	for _, s := range MessageTypes() {
+		if err := validateSchema(s); err != nil {
+			t.Errorf("built-in message type is invalid: %v", err)
+		}
+	}
+	if err := RegisterMessageType(MessageTypes()[0]); err == nil {
+		t.Error("expected duplicate registration to fail")
+	}
+	bad := messageType("IST9998", "Bad", AnalysisMessageBase_INFO, "", "%s and %s", "one", "string")
+	if err := RegisterMessageType(bad); err == nil {
+		t.Error("expected a template/args mismatch to fail")
+	}
+	custom := messageType("IST9997", "Custom", AnalysisMessageBase_WARNING, "", "100%% custom %s", "what", "string")
+	if err := RegisterMessageType(custom); err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewMessage("IST9997", []string{"Namespace/default"}, "message")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := m.Render(); got != "Warning [IST9997] (Namespace/default) 100% custom message" {
+		t.Errorf("got %q", got)
+	}
+	undocumented := messageType("API9999", "Undocumented", AnalysisMessageBase_INFO, "", "undocumented")
+	if got := undocumented.GetMessageBase().GetDocumentationUrl(); got != "" {
+		t.Errorf("expected no documentation url for %s, got %q", undocumented.GetMessageBase().GetType().GetCode(), got)
+	}
+	undocumented.MessageBase.DocumentationUrl = "https://istio.io/docs/reference/config/analysis/api9999/"
+	if err := RegisterMessageType(undocumented); err == nil {
+		t.Error("expected an istio.io documentation url on a non-IST code to fail")
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	analysis "istio.io/api/analysis/v1alpha1"
+)
+
+func testMessages(t *testing.T) []*analysis.GenericAnalysisMessage {
+	t.Helper()
+	var out []*analysis.GenericAnalysisMessage
+	for _, tc := range []struct {
+		code  string
+		paths []string
+		args  []any
+	}{
+		{analysis.ReferencedResourceNotFoundCode, []string{"default/VirtualService/reviews"}, []any{"host", "ratings"}},
+		{analysis.GatewayPortNotOnWorkloadCode, []string{"istio-system/Gateway/ingress"}, []any{"istio=ingressgateway", 8443}},
+		{analysis.ReferencedResourceNotFoundCode, []string{"default/VirtualService/details", "default/Gateway/gw"}, []any{"gateway", "gw"}},
+		{analysis.NamespaceNotInjectedCode, []string{"Namespace/default"}, []any{"default", "default"}},
+	} {
+		m, err := analysis.NewMessage(tc.code, tc.paths, tc.args...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+func TestSARIF(t *testing.T) {
+	msgs := testMessages(t)
+	buf := &bytes.Buffer{}
+	origins := Origins{"default/VirtualService/reviews": {File: filepath.Join("config", "reviews.yaml"), Line: 3}}
+	if err := WriteSARIF(buf, "istioctl", msgs, origins); err != nil {
+		t.Fatal(err)
+	}
+
+	var raw map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &raw); err != nil {
+		t.Fatal(err)
+	}
+	run := raw["runs"].([]any)[0].(map[string]any)
+	rules := run["tool"].(map[string]any)["driver"].(map[string]any)["rules"].([]any)
+	if len(rules) != 3 {
+		t.Fatalf("got %d rules, want 3", len(rules))
+	}
+	rule := rules[0].(map[string]any)
+	if rule["id"] != "IST0101" || rule["helpUri"] != "https://istio.io/docs/reference/config/analysis/ist0101/" {
+		t.Fatalf("unexpected rule %v", rule)
+	}
+	location := run["results"].([]any)[0].(map[string]any)["locations"].([]any)[0].(map[string]any)
+	physical, _ := location["physicalLocation"].(map[string]any)
+	if physical["artifactLocation"].(map[string]any)["uri"] != "config/reviews.yaml" ||
+		physical["region"].(map[string]any)["startLine"] != 3.0 {
+		t.Fatalf("unexpected location %v", location)
+	}
+	if location := run["results"].([]any)[1].(map[string]any)["locations"].([]any)[0].(map[string]any); location["physicalLocation"] != nil {
+		t.Fatalf("unexpected physical location for a resource not read from a file: %v", location)
+	}
+	result := run["results"].([]any)[1].(map[string]any)
+	if result["level"] != "warning" || !strings.Contains(result["message"].(map[string]any)["text"].(string), "port 8443") {
+		t.Fatalf("unexpected result %v", result)
+	}
+	if note := run["results"].([]any)[3].(map[string]any); note["level"] != "note" {
+		t.Fatalf("unexpected result %v", note)
+	}
+
+	got, err := ReadSARIF(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(msgs) {
+		t.Fatalf("got %d messages, want %d", len(got), len(msgs))
+	}
+	for i := range msgs {
+		if !proto.Equal(got[i], msgs[i]) {
+			t.Errorf("message %d changed:\ngot  %v\nwant %v", i, got[i], msgs[i])
+		}
+	}
+}
+
+func TestFromSARIFErrors(t *testing.T) {
+	if _, err := FromSARIF(&SARIFLog{Version: "2.0.0"}); err == nil {
+		t.Error("expected an error for an unsupported version")
+	}
+	log := &SARIFLog{Version: SARIFVersion, Runs: []SARIFRun{{Results: []SARIFResult{{RuleID: "IST0101", Level: "fatal"}}}}}
+	if _, err := FromSARIF(log); err == nil {
+		t.Error("expected an error for an unknown level")
+	}
+}
+
+func TestJUnit(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := WriteJUnit(buf, "istioctl", testMessages(t), analysis.AnalysisMessageBase_WARNING); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		`<testsuites tests="4" failures="3">`,
+		`<testcase name="IST0101 default/VirtualService/details, default/Gateway/gw" classname="ReferencedResourceNotFound">`,
+		`<failure type="WARNING" message="Warning [IST0104] (istio-system/Gateway/ingress) The gateway refers to a port`,
+		`<system-out>Info [IST0102] (Namespace/default) The namespace is not enabled`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+
+	analysis "istio.io/api/analysis/v1alpha1"
+)
+
+// The subset of the JUnit XML format understood by CI systems.
+type (
+	JUnitTestSuites struct {
+		XMLName  xml.Name         `xml:"testsuites"`
+		Tests    int              `xml:"tests,attr"`
+		Failures int              `xml:"failures,attr"`
+		Suites   []JUnitTestSuite `xml:"testsuite"`
+	}
+	JUnitTestSuite struct {
+		Name      string          `xml:"name,attr"`
+		Tests     int             `xml:"tests,attr"`
+		Failures  int             `xml:"failures,attr"`
+		TestCases []JUnitTestCase `xml:"testcase"`
+	}
+	JUnitTestCase struct {
+		Name      string        `xml:"name,attr"`
+		ClassName string        `xml:"classname,attr"`
+		Failure   *JUnitFailure `xml:"failure,omitempty"`
+		SystemOut string        `xml:"system-out,omitempty"`
+	}
+	JUnitFailure struct {
+		Type    string `xml:"type,attr"`
+		Message string `xml:"message,attr"`
+		Text    string `xml:",chardata"`
+	}
+)
+
+// ToJUnit converts msgs to a JUnit test suite named after the tool. Each message is a test case,
+// which fails if the message is at least as severe as failAt. Less severe messages are reported
+// as passing test cases, with the message text as output.
+func ToJUnit(tool string, msgs []*analysis.GenericAnalysisMessage, failAt analysis.AnalysisMessageBase_Level) *JUnitTestSuites {
+	suite := JUnitTestSuite{Name: tool, Tests: len(msgs)}
+	for _, m := range msgs {
+		base := m.GetMessageBase()
+		text, err := m.Render()
+		if err != nil {
+			text = base.GetType().GetName()
+		}
+		tc := JUnitTestCase{
+			Name:      base.GetType().GetCode() + " " + strings.Join(m.GetResourcePaths(), ", "),
+			ClassName: base.GetType().GetName(),
+		}
+		if base.GetLevel() != analysis.AnalysisMessageBase_UNKNOWN && base.GetLevel() <= failAt {
+			tc.Failure = &JUnitFailure{Type: base.GetLevel().String(), Message: text, Text: base.GetDocumentationUrl()}
+			suite.Failures++
+		} else {
+			tc.SystemOut = text
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+	return &JUnitTestSuites{Tests: suite.Tests, Failures: suite.Failures, Suites: []JUnitTestSuite{suite}}
+}
+
+// WriteJUnit writes msgs to w as an indented JUnit XML document.
+func WriteJUnit(w io.Writer, tool string, msgs []*analysis.GenericAnalysisMessage, failAt analysis.AnalysisMessageBase_Level) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(ToJUnit(tool, msgs, failAt)); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,278 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package export converts analysis messages to the SARIF 2.1.0 and JUnit XML formats understood
+// by CI systems and code scanning dashboards.
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"google.golang.org/protobuf/types/known/structpb"
+
+	analysis "istio.io/api/analysis/v1alpha1"
+)
+
+const (
+	// SARIFSchema and SARIFVersion identify the format of the logs written by this package.
+	SARIFSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	SARIFVersion = "2.1.0"
+	// resourceKind is the kind of the logical locations of resource paths.
+	resourceKind = "resource"
+)
+
+// The subset of the SARIF 2.1.0 format needed to report analysis messages.
+type (
+	SARIFLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []SARIFRun `json:"runs"`
+	}
+	SARIFRun struct {
+		Tool    SARIFTool     `json:"tool"`
+		Results []SARIFResult `json:"results"`
+	}
+	SARIFTool struct {
+		Driver SARIFDriver `json:"driver"`
+	}
+	SARIFDriver struct {
+		Name           string      `json:"name"`
+		InformationURI string      `json:"informationUri,omitempty"`
+		Rules          []SARIFRule `json:"rules,omitempty"`
+	}
+	SARIFRule struct {
+		ID                   string                  `json:"id"`
+		Name                 string                  `json:"name,omitempty"`
+		ShortDescription     *SARIFMessage           `json:"shortDescription,omitempty"`
+		HelpURI              string                  `json:"helpUri,omitempty"`
+		DefaultConfiguration *SARIFRuleConfiguration `json:"defaultConfiguration,omitempty"`
+	}
+	SARIFRuleConfiguration struct {
+		Level string `json:"level"`
+	}
+	SARIFMessage struct {
+		Text string `json:"text"`
+	}
+	SARIFResult struct {
+		RuleID     string           `json:"ruleId"`
+		RuleIndex  *int             `json:"ruleIndex,omitempty"`
+		Level      string           `json:"level,omitempty"`
+		Message    SARIFMessage     `json:"message"`
+		Locations  []SARIFLocation  `json:"locations,omitempty"`
+		Properties *SARIFProperties `json:"properties,omitempty"`
+	}
+	SARIFLocation struct {
+		PhysicalLocation *SARIFPhysicalLocation `json:"physicalLocation,omitempty"`
+		LogicalLocations []SARIFLogicalLocation `json:"logicalLocations,omitempty"`
+	}
+	SARIFPhysicalLocation struct {
+		ArtifactLocation SARIFArtifactLocation `json:"artifactLocation"`
+		Region           *SARIFRegion          `json:"region,omitempty"`
+	}
+	SARIFArtifactLocation struct {
+		URI string `json:"uri"`
+	}
+	SARIFRegion struct {
+		StartLine int `json:"startLine"`
+	}
+	SARIFLogicalLocation struct {
+		FullyQualifiedName string `json:"fullyQualifiedName"`
+		Kind               string `json:"kind,omitempty"`
+	}
+	// SARIFProperties carries the message args, so that results can be converted back to messages.
+	SARIFProperties struct {
+		Args map[string]any `json:"args,omitempty"`
+	}
+)
+
+var (
+	toSARIFLevel = map[analysis.AnalysisMessageBase_Level]string{
+		analysis.AnalysisMessageBase_ERROR:   "error",
+		analysis.AnalysisMessageBase_WARNING: "warning",
+		analysis.AnalysisMessageBase_INFO:    "note",
+		analysis.AnalysisMessageBase_UNKNOWN: "none",
+	}
+	fromSARIFLevel = map[string]analysis.AnalysisMessageBase_Level{
+		"error":   analysis.AnalysisMessageBase_ERROR,
+		"warning": analysis.AnalysisMessageBase_WARNING,
+		"note":    analysis.AnalysisMessageBase_INFO,
+		"none":    analysis.AnalysisMessageBase_UNKNOWN,
+		// Level defaults to warning when not set.
+		"": analysis.AnalysisMessageBase_WARNING,
+	}
+)
+
+// Origin is the file a resource was read from, and the line at which it starts if known.
+type Origin struct {
+	File string
+	Line int
+}
+
+// Origins maps resource paths to the origins of the resources read from files.
+type Origins map[string]Origin
+
+// PhysicalLocation returns the SARIF location of o, with the file as a slash-separated URI.
+func (o Origin) PhysicalLocation() *SARIFPhysicalLocation {
+	l := &SARIFPhysicalLocation{ArtifactLocation: SARIFArtifactLocation{URI: filepath.ToSlash(o.File)}}
+	if o.Line > 0 {
+		l.Region = &SARIFRegion{StartLine: o.Line}
+	}
+	return l
+}
+
+// NewSARIFLog returns a SARIF log of the given runs.
+func NewSARIFLog(runs ...SARIFRun) *SARIFLog {
+	return &SARIFLog{Schema: SARIFSchema, Version: SARIFVersion, Runs: runs}
+}
+
+// ToSARIF converts msgs to a SARIF log with a single run of the given tool, such as "istioctl".
+// Each message type becomes a rule, whose help URI is the documentation URL of the type, and
+// each resource path a logical location of the result. Resource paths found in origins, which
+// may be nil, also get the physical location of the file they were read from.
+func ToSARIF(tool string, msgs []*analysis.GenericAnalysisMessage, origins Origins) *SARIFLog {
+	run := SARIFRun{
+		Tool:    SARIFTool{Driver: SARIFDriver{Name: tool, InformationURI: "https://istio.io/latest/docs/reference/config/analysis/"}},
+		Results: []SARIFResult{},
+	}
+	rules := map[string]int{}
+	for _, m := range msgs {
+		base := m.GetMessageBase()
+		code := base.GetType().GetCode()
+		index, f := rules[code]
+		if !f {
+			index = len(run.Tool.Driver.Rules)
+			rules[code] = index
+			rule := SARIFRule{
+				ID:                   code,
+				Name:                 base.GetType().GetName(),
+				HelpURI:              base.GetDocumentationUrl(),
+				DefaultConfiguration: &SARIFRuleConfiguration{Level: toSARIFLevel[base.GetLevel()]},
+			}
+			if s, f := analysis.LookupMessageType(code); f {
+				rule.ShortDescription = &SARIFMessage{Text: s.GetDescription()}
+			}
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+		}
+		text, err := m.Text()
+		if err != nil {
+			// Messages of unregistered types can still be reported, without their details.
+			text = base.GetType().GetName()
+		}
+		result := SARIFResult{
+			RuleID:    code,
+			RuleIndex: &index,
+			Level:     toSARIFLevel[base.GetLevel()],
+			Message:   SARIFMessage{Text: text},
+		}
+		for _, p := range m.GetResourcePaths() {
+			l := SARIFLocation{LogicalLocations: []SARIFLogicalLocation{{FullyQualifiedName: p, Kind: resourceKind}}}
+			if o, f := origins[p]; f {
+				l.PhysicalLocation = o.PhysicalLocation()
+			}
+			result.Locations = append(result.Locations, l)
+		}
+		if m.GetArgs() != nil {
+			result.Properties = &SARIFProperties{Args: m.GetArgs().AsMap()}
+		}
+		run.Results = append(run.Results, result)
+	}
+	return NewSARIFLog(run)
+}
+
+// WriteSARIF writes msgs to w as an indented SARIF log.
+func WriteSARIF(w io.Writer, tool string, msgs []*analysis.GenericAnalysisMessage, origins Origins) error {
+	return ToSARIF(tool, msgs, origins).Write(w)
+}
+
+// Write writes l to w as indented JSON.
+func (l *SARIFLog) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(l)
+}
+
+// FromSARIF converts the results of all the runs of a SARIF log back to messages. The message
+// type and documentation URL are taken from the rule of each result.
+func FromSARIF(log *SARIFLog) ([]*analysis.GenericAnalysisMessage, error) {
+	if log.Version != SARIFVersion {
+		return nil, fmt.Errorf("unsupported SARIF version %q", log.Version)
+	}
+	var out []*analysis.GenericAnalysisMessage
+	for i, run := range log.Runs {
+		for j, r := range run.Results {
+			rule, err := ruleOf(run, r)
+			if err != nil {
+				return nil, fmt.Errorf("runs[%d].results[%d]: %v", i, j, err)
+			}
+			levelName := r.Level
+			if levelName == "" && rule.DefaultConfiguration != nil {
+				levelName = rule.DefaultConfiguration.Level
+			}
+			level, f := fromSARIFLevel[levelName]
+			if !f {
+				return nil, fmt.Errorf("runs[%d].results[%d]: unknown level %q", i, j, r.Level)
+			}
+			m := &analysis.GenericAnalysisMessage{
+				MessageBase: &analysis.AnalysisMessageBase{
+					Type:             &analysis.AnalysisMessageBase_Type{Name: rule.Name, Code: r.RuleID},
+					Level:            level,
+					DocumentationUrl: rule.HelpURI,
+				},
+			}
+			if r.Properties != nil && r.Properties.Args != nil {
+				if m.Args, err = structpb.NewStruct(r.Properties.Args); err != nil {
+					return nil, fmt.Errorf("runs[%d].results[%d]: invalid args: %v", i, j, err)
+				}
+			}
+			for _, l := range r.Locations {
+				for _, ll := range l.LogicalLocations {
+					if ll.Kind == "" || ll.Kind == resourceKind {
+						m.ResourcePaths = append(m.ResourcePaths, ll.FullyQualifiedName)
+					}
+				}
+			}
+			out = append(out, m)
+		}
+	}
+	return out, nil
+}
+
+// ReadSARIF decodes a SARIF log from r and converts it to messages.
+func ReadSARIF(r io.Reader) ([]*analysis.GenericAnalysisMessage, error) {
+	log := &SARIFLog{}
+	if err := json.NewDecoder(r).Decode(log); err != nil {
+		return nil, err
+	}
+	return FromSARIF(log)
+}
+
+// ruleOf returns the rule of a result, found by index or, failing that, by id.
+func ruleOf(run SARIFRun, r SARIFResult) (SARIFRule, error) {
+	rules := run.Tool.Driver.Rules
+	if r.RuleIndex != nil && *r.RuleIndex >= 0 && *r.RuleIndex < len(rules) && rules[*r.RuleIndex].ID == r.RuleID {
+		return rules[*r.RuleIndex], nil
+	}
+	for _, rule := range rules {
+		if rule.ID == r.RuleID {
+			return rule, nil
+		}
+	}
+	if r.RuleID == "" {
+		return SARIFRule{}, fmt.Errorf("ruleId is required")
+	}
+	return SARIFRule{ID: r.RuleID}, nil
+}
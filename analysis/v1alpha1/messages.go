@@ -0,0 +1,153 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import "strings"
+
+// Codes of the built-in message types.
+const (
+	InternalErrorCode                                 = "IST0001"
+	DeprecatedCode                                    = "IST0002"
+	ReferencedResourceNotFoundCode                    = "IST0101"
+	NamespaceNotInjectedCode                          = "IST0102"
+	PodMissingProxyCode                               = "IST0103"
+	GatewayPortNotOnWorkloadCode                      = "IST0104"
+	SchemaValidationErrorCode                         = "IST0106"
+	MisplacedAnnotationCode                           = "IST0107"
+	UnknownAnnotationCode                             = "IST0108"
+	ConflictingMeshGatewayVirtualServiceHostsCode     = "IST0109"
+	ConflictingSidecarWorkloadSelectorsCode           = "IST0110"
+	MultipleSidecarsWithoutWorkloadSelectorsCode      = "IST0111"
+	VirtualServiceDestinationPortSelectorRequiredCode = "IST0112"
+	PortNameIsNotUnderNamingConventionCode            = "IST0118"
+	VirtualServiceUnreachableRuleCode                 = "IST0130"
+	VirtualServiceIneffectiveMatchCode                = "IST0131"
+	DeprecatedAnnotationCode                          = "IST0135"
+	ConflictingGatewaysCode                           = "IST0145"
+
+	// The route analyzer of networking/v1alpha3/analyze reports findings that Istio's analyzers do
+	// not, under codes of their own that have no page on istio.io.
+	ConflictingGatewayVirtualServiceHostsCode         = "API0001"
+	DelegateVirtualServiceReferencedMultipleTimesCode = "API0002"
+	UnreferencedDelegateVirtualServiceCode            = "API0003"
+)
+
+// builtinMessageTypes returns the message types reported by Istio's analyzers, as documented at
+// https://istio.io/latest/docs/reference/config/analysis/, followed by those only reported by the
+// route analyzer of networking/v1alpha3/analyze.
+func builtinMessageTypes() []*AnalysisMessageWeakSchema {
+	return []*AnalysisMessageWeakSchema{
+		messageType(InternalErrorCode, "InternalError", AnalysisMessageBase_ERROR,
+			"There was an internal error in the toolchain. This is almost always a bug in the implementation.",
+			"Internal error: %v", "detail", "string"),
+		messageType(DeprecatedCode, "Deprecated", AnalysisMessageBase_WARNING,
+			"A feature that the configuration is depending on is now deprecated.",
+			"Deprecated: %s", "detail", "string"),
+		messageType(ReferencedResourceNotFoundCode, "ReferencedResourceNotFound", AnalysisMessageBase_ERROR,
+			"A resource being referenced does not exist.",
+			"Referenced %s not found: %q", "reftype", "string", "refval", "string"),
+		messageType(NamespaceNotInjectedCode, "NamespaceNotInjected", AnalysisMessageBase_INFO,
+			"A namespace is not enabled for Istio injection.",
+			"The namespace is not enabled for Istio injection. Run 'kubectl label namespace %s istio-injection=enabled' "+
+				"to enable it, or 'kubectl label namespace %s istio-injection=disabled' to explicitly mark it as not needing injection.",
+			"namespace", "string", "namespace2", "string"),
+		messageType(PodMissingProxyCode, "PodMissingProxy", AnalysisMessageBase_WARNING,
+			"A pod is missing the Istio proxy.",
+			"The pod %s is missing the Istio proxy. This can often be resolved by restarting or redeploying the workload.",
+			"podName", "string"),
+		messageType(GatewayPortNotOnWorkloadCode, "GatewayPortNotOnWorkload", AnalysisMessageBase_WARNING,
+			"Unhandled gateway port",
+			"The gateway refers to a port that is not exposed on the workload (pod selector %s; port %d)",
+			"selector", "string", "port", "int"),
+		messageType(SchemaValidationErrorCode, "SchemaValidationError", AnalysisMessageBase_ERROR,
+			"The resource has a schema validation error.",
+			"Schema validation error: %v", "err", "string"),
+		messageType(MisplacedAnnotationCode, "MisplacedAnnotation", AnalysisMessageBase_WARNING,
+			"An Istio annotation is applied to the wrong kind of resource.",
+			"Misplaced annotation: %s can only be applied to %s", "annotation", "string", "kind", "string"),
+		messageType(UnknownAnnotationCode, "UnknownAnnotation", AnalysisMessageBase_WARNING,
+			"An Istio annotation is not recognized for any kind of resource",
+			"Unknown annotation: %s", "annotation", "string"),
+		messageType(ConflictingMeshGatewayVirtualServiceHostsCode, "ConflictingMeshGatewayVirtualServiceHosts", AnalysisMessageBase_ERROR,
+			"Conflicting hosts on VirtualServices associated with mesh gateway",
+			"The VirtualServices %s associated with mesh gateway define the same host %s which can lead to undefined behavior. "+
+				"This can be fixed by merging the conflicting VirtualServices into a single resource.",
+			"virtualServices", "string", "host", "string"),
+		messageType(ConflictingSidecarWorkloadSelectorsCode, "ConflictingSidecarWorkloadSelectors", AnalysisMessageBase_ERROR,
+			"A Sidecar resource selects the same workloads as another Sidecar resource",
+			"The Sidecars %v in namespace %q select the same workload pod %q, which can lead to undefined behavior.",
+			"conflictingSidecars", "[]string", "namespace", "string", "workloadPod", "string"),
+		messageType(MultipleSidecarsWithoutWorkloadSelectorsCode, "MultipleSidecarsWithoutWorkloadSelectors", AnalysisMessageBase_ERROR,
+			"More than one sidecar resource in a namespace has no workload selector",
+			"The Sidecars %v in namespace %q have no workload selector, which can lead to undefined behavior.",
+			"conflictingSidecars", "[]string", "namespace", "string"),
+		messageType(VirtualServiceDestinationPortSelectorRequiredCode, "VirtualServiceDestinationPortSelectorRequired", AnalysisMessageBase_ERROR,
+			"A VirtualService routes to a service with more than one port exposed, but does not specify which to use.",
+			"This VirtualService routes to a service %q that exposes multiple ports %v. Specifying a port in the destination is required to disambiguate.",
+			"destHost", "string", "destPorts", "[]int"),
+		messageType(PortNameIsNotUnderNamingConventionCode, "PortNameIsNotUnderNamingConvention", AnalysisMessageBase_INFO,
+			"Port name is not under naming convention. Protocol detection is applied to the port.",
+			"Port name %s (port: %d, targetPort: %s) doesn't follow the naming convention of Istio port.",
+			"portName", "string", "port", "int", "targetPort", "string"),
+		messageType(VirtualServiceUnreachableRuleCode, "VirtualServiceUnreachableRule", AnalysisMessageBase_WARNING,
+			"A VirtualService rule will never be used because a previous rule uses the same match.",
+			"VirtualService rule %v not used (%s).",
+			"ruleno", "string", "reason", "string"),
+		messageType(VirtualServiceIneffectiveMatchCode, "VirtualServiceIneffectiveMatch", AnalysisMessageBase_INFO,
+			"A VirtualService rule match duplicates a match in a previous rule.",
+			"VirtualService rule %v match %v is not used (duplicate/overlapping match in rule %v).",
+			"ruleno", "string", "matchno", "string", "dupno", "string"),
+		messageType(DeprecatedAnnotationCode, "DeprecatedAnnotation", AnalysisMessageBase_INFO,
+			"A resource is using a deprecated Istio annotation.",
+			"Annotation %q has been deprecated%s and may not work in future Istio versions.",
+			"annotation", "string", "extra", "string"),
+		messageType(ConflictingGatewaysCode, "ConflictingGateways", AnalysisMessageBase_ERROR,
+			"Gateway should not have the same selector, port and matched hosts of server",
+			"Conflict with gateways %s (workload selector %s, port %s, hosts %v).",
+			"gateway", "string", "selector", "string", "portnumber", "string", "hosts", "string"),
+		messageType(ConflictingGatewayVirtualServiceHostsCode, "ConflictingGatewayVirtualServiceHosts", AnalysisMessageBase_WARNING,
+			"Overlapping hosts on VirtualServices bound to the same gateway",
+			"The VirtualServices %s bound to gateway %s define overlapping hosts %s. Requests for these hosts are only routed "+
+				"by the VirtualService with the most specific host, in creation order for identical hosts.",
+			"virtualServices", "string", "gateway", "string", "hosts", "string"),
+		messageType(DelegateVirtualServiceReferencedMultipleTimesCode, "DelegateVirtualServiceReferencedMultipleTimes", AnalysisMessageBase_WARNING,
+			"A delegate VirtualService is referenced by more than one route",
+			"The delegate VirtualService is referenced by the routes %v. A delegate should be referenced by a single route.",
+			"routes", "[]string"),
+		messageType(UnreferencedDelegateVirtualServiceCode, "UnreferencedDelegateVirtualService", AnalysisMessageBase_WARNING,
+			"A VirtualService without hosts is not referenced as a delegate",
+			"The VirtualService has no hosts and is not referenced as a delegate by any route, so it has no effect.",
+		),
+	}
+}
+
+// messageType returns the schema of a built-in message type. args are pairs of name and go type.
+func messageType(code, name string, level AnalysisMessageBase_Level, description, template string, args ...string) *AnalysisMessageWeakSchema {
+	s := &AnalysisMessageWeakSchema{
+		MessageBase: &AnalysisMessageBase{
+			Type:  &AnalysisMessageBase_Type{Name: name, Code: code},
+			Level: level,
+		},
+		Description: description,
+		Template:    template,
+	}
+	if strings.HasPrefix(code, istioCodePrefix) {
+		s.MessageBase.DocumentationUrl = "https://istio.io/docs/reference/config/analysis/" + strings.ToLower(code) + "/"
+	}
+	for i := 0; i < len(args); i += 2 {
+		s.Args = append(s.Args, &AnalysisMessageWeakSchema_ArgType{Name: args[i], GoType: args[i+1]})
+	}
+	return s
+}
@@ -0,0 +1,85 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package annotation
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		annotation Instance
+		value      string
+		want       any
+		wantErr    bool
+	}{
+		{SidecarInject, "true", true, false},
+		{SidecarInject, "yes", nil, true},
+		{SidecarStatsHistogramBins, "10", int64(10), false},
+		{SidecarStatsHistogramBins, "ten", nil, true},
+		{SidecarStatsFlushInterval, "10s", 10 * time.Second, false},
+		{SidecarStatsFlushInterval, "10", nil, true},
+		{SidecarProxyCPU, "100m", "100m", false},
+		{SidecarProxyMemory, "1Gi", "1Gi", false},
+		{SidecarProxyMemory, "1GB", nil, true},
+		{SidecarTrafficIncludeOutboundIPRanges, "10.0.0.0/8, fd00::/8", []string{"10.0.0.0/8", "fd00::/8"}, false},
+		{SidecarTrafficIncludeOutboundIPRanges, "*", []string{"*"}, false},
+		{SidecarTrafficIncludeOutboundIPRanges, "10.0.0.0", nil, true},
+		{SidecarTrafficExcludeInboundPorts, "", []string{}, false},
+		{SidecarTrafficExcludeInboundPorts, "15020,8080", []string{"15020", "8080"}, false},
+		{SidecarTrafficExcludeInboundPorts, "0", nil, true},
+		{SidecarTrafficExcludeInboundPorts, "65536", nil, true},
+		{InjectTemplates, "sidecar,custom", []string{"sidecar", "custom"}, false},
+		{SidecarStatsHistogramBuckets, `{"cluster.upstream_rq_time":[1,5]}`, map[string]any{"cluster.upstream_rq_time": []any{1.0, 5.0}}, false},
+		{SidecarStatsHistogramBuckets, `{`, nil, true},
+		{SidecarInterceptionMode, "TPROXY", "TPROXY", false},
+		{SidecarInterceptionMode, "tproxy", nil, true},
+		{SidecarComponentLogLevel, "misc:error,upstream:debug", "misc:error,upstream:debug", false},
+		{SidecarComponentLogLevel, "misc", nil, true},
+		{ProxyConfig, "anything", "anything", false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.annotation.Name+"="+tc.value, func(t *testing.T) {
+			got, err := tc.annotation.Parse(tc.value)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("Parse() = %#v, want %#v", got, tc.want)
+			}
+			if err := tc.annotation.Validate(tc.value); (err != nil) != tc.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestValueConstraints(t *testing.T) {
+	for _, a := range AllResourceAnnotations() {
+		if a.Pattern() != "" {
+			if _, err := regexp.Compile(a.Pattern()); err != nil {
+				t.Errorf("%s: invalid pattern: %v", a.Name, err)
+			}
+		}
+		for _, v := range a.AllowedValues() {
+			if err := a.Validate(v); err != nil {
+				t.Errorf("%s: allowed value %q is invalid: %v", a.Name, v, err)
+			}
+		}
+	}
+}
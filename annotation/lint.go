@@ -0,0 +1,86 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package annotation
+
+import (
+	"fmt"
+
+	"istio.io/api/internal/registry"
+)
+
+// FindingType classifies a Finding.
+type FindingType string
+
+const (
+	// UnknownAnnotation is an annotation in an Istio domain that Istio does not define.
+	UnknownAnnotation FindingType = "UnknownAnnotation"
+	// MisplacedAnnotation is an annotation set on a kind of resource it does not apply to.
+	MisplacedAnnotation FindingType = "MisplacedAnnotation"
+	// DeprecatedAnnotation is an annotation that is deprecated.
+	DeprecatedAnnotation FindingType = "DeprecatedAnnotation"
+	// InvalidAnnotationValue is an annotation whose value does not match its value type.
+	InvalidAnnotationValue FindingType = "InvalidAnnotationValue"
+)
+
+var findingTypes = []FindingType{
+	registry.Unknown:      UnknownAnnotation,
+	registry.Misplaced:    MisplacedAnnotation,
+	registry.Deprecated:   DeprecatedAnnotation,
+	registry.InvalidValue: InvalidAnnotationValue,
+}
+
+// Finding is a problem with an annotation of an object.
+type Finding struct {
+	Type FindingType
+	// Key is the annotation name, as set on the object.
+	Key string
+	// Suggestion is the known annotation Key is likely a misspelling of or, for deprecated
+	// annotations, the replacement to use, if any.
+	Suggestion string
+	Message    string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s", f.Type, f.Message)
+}
+
+var linter = registry.Linter{
+	Noun: "annotation",
+	Names: func() []string {
+		var out []string
+		for _, a := range AllResourceAnnotations() {
+			out = append(out, a.Name)
+		}
+		return out
+	},
+	Lookup: func(name string) (registry.Entry, bool) {
+		a, f := Lookup(name)
+		if !f {
+			return registry.Entry{}, false
+		}
+		return registry.Entry{Resources: a.kinds(), Deprecated: a.Deprecated, Metadata: metadataFor(a.Name)}, true
+	},
+}
+
+// Lint checks the annotations of an object of the given kind, such as "Pod", against the known
+// Istio annotations. Annotations outside of the istio.io domains are ignored. Findings are sorted
+// by key.
+func Lint(kind string, annotations map[string]string) []Finding {
+	var findings []Finding
+	for _, f := range linter.Lint(kind, annotations) {
+		findings = append(findings, Finding{Type: findingTypes[f.Kind], Key: f.Key, Suggestion: f.Suggestion, Message: f.Message})
+	}
+	return findings
+}
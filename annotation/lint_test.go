@@ -0,0 +1,55 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package annotation
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	findings := Lint("Pod", map[string]string{
+		"sidecar.istio.io/injet":                   "true",
+		"sidecar.istio.io/proxyCPU":                "lots",
+		"networking.istio.io/exportTo":             ".",
+		"alpha.istio.io/canonical-serviceaccounts": "sa",
+		"policy.istio.io/nonsense":                 "",
+		"example.com/foo":                          "bar",
+	})
+	var got []FindingType
+	for _, f := range findings {
+		got = append(got, f.Type)
+	}
+	want := []FindingType{
+		MisplacedAnnotation, DeprecatedAnnotation, // alpha.istio.io/canonical-serviceaccounts
+		MisplacedAnnotation,    // networking.istio.io/exportTo
+		UnknownAnnotation,      // policy.istio.io/nonsense
+		UnknownAnnotation,      // sidecar.istio.io/injet
+		InvalidAnnotationValue, // sidecar.istio.io/proxyCPU
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", findings, want)
+	}
+	if findings[3].Suggestion != "" {
+		t.Errorf("unexpected suggestion %q", findings[3].Suggestion)
+	}
+	if findings[4].Suggestion != SidecarInject.Name {
+		t.Errorf("got suggestion %q, want %q", findings[4].Suggestion, SidecarInject.Name)
+	}
+
+	if findings := Lint("Service", map[string]string{"networking.istio.io/exportTo": ".,istio-system"}); len(findings) != 0 {
+		t.Errorf("unexpected findings %v", findings)
+	}
+}
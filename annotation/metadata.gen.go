@@ -0,0 +1,80 @@
+// Code generated by kubernetes/cmd/metadata-gen. DO NOT EDIT.
+
+package annotation
+
+import (
+	"regexp"
+
+	"istio.io/api/internal/registry"
+)
+
+// metadatas is keyed by annotation name.
+var metadatas = map[string]*registry.Metadata{
+	AlphaCanonicalServiceAccounts.Name:             {ValueType: registry.StringList},
+	AlphaKubernetesServiceAccounts.Name:            {ValueType: registry.StringList},
+	AmbientBypassInboundCapture.Name:               {Anchor: "AmbientBypassInboundCapture", ValueType: registry.Bool},
+	AmbientDnsCapture.Name:                         {ValueType: registry.Bool},
+	AmbientRedirection.Name:                        {Anchor: "AmbientRedirection"},
+	AmbientWaypointInboundBinding.Name:             {Pattern: regexp.MustCompile(`^[A-Za-z0-9_-]+(/[0-9]+)?$`)},
+	GalleyAnalyzeSuppress.Name:                     {Anchor: "GalleyAnalyzeSuppress", ValueType: registry.StringList},
+	GatewayControllerVersion.Name:                  {},
+	GatewayNameOverride.Name:                       {},
+	GatewayServiceAccount.Name:                     {},
+	InjectTemplates.Name:                           {Anchor: "InjectTemplates", ValueType: registry.StringList},
+	IoIstioAutoRegistrationGroup.Name:              {},
+	IoIstioConnectedAt.Name:                        {},
+	IoIstioDisconnectedAt.Name:                     {},
+	IoIstioDryRun.Name:                             {Anchor: "IoIstioDryRun", ValueType: registry.Bool},
+	IoIstioRerouteVirtualInterfaces.Name:           {Anchor: "IoIstioRerouteVirtualInterfaces", ValueType: registry.StringList},
+	IoIstioRev.Name:                                {Anchor: "IoIstioRev"},
+	IoIstioWorkloadController.Name:                 {},
+	IoKubernetesIngressClass.Name:                  {Anchor: "IoKubernetesIngressClass"},
+	NetworkingExportTo.Name:                        {Anchor: "NetworkingExportTo", ValueType: registry.StringList},
+	NetworkingServiceType.Name:                     {AllowedValues: []string{"ClusterIP", "NodePort", "LoadBalancer"}},
+	NetworkingTrafficDistribution.Name:             {Anchor: "NetworkingTrafficDistribution", AllowedValues: []string{"PreferClose"}},
+	PrometheusMergeMetrics.Name:                    {Anchor: "PrometheusMergeMetrics", ValueType: registry.Bool},
+	ProxyConfig.Name:                               {Anchor: "ProxyConfig"},
+	ProxyOverrides.Name:                            {ValueType: registry.JSON},
+	SidecarAgentLogLevel.Name:                      {Anchor: "SidecarAgentLogLevel"},
+	SidecarBootstrapOverride.Name:                  {Anchor: "SidecarBootstrapOverride"},
+	SidecarComponentLogLevel.Name:                  {Anchor: "SidecarComponentLogLevel", Pattern: regexp.MustCompile(`^[a-z0-9_.]+:[a-z]+(,[a-z0-9_.]+:[a-z]+)*$`)},
+	SidecarDiscoveryAddress.Name:                   {Anchor: "SidecarDiscoveryAddress"},
+	SidecarExtraStatTags.Name:                      {Anchor: "SidecarExtraStatTags", ValueType: registry.StringList},
+	SidecarInject.Name:                             {Anchor: "SidecarInject", ReplacedBy: "sidecar.istio.io/inject", ValueType: registry.Bool},
+	SidecarInterceptionMode.Name:                   {Anchor: "SidecarInterceptionMode", AllowedValues: []string{"REDIRECT", "TPROXY", "NONE"}},
+	SidecarLogLevel.Name:                           {Anchor: "SidecarLogLevel", AllowedValues: []string{"trace", "debug", "info", "warning", "warn", "error", "critical", "off"}},
+	SidecarNativeSidecar.Name:                      {Anchor: "SidecarNativeSidecar", ValueType: registry.Bool},
+	SidecarProxyCPU.Name:                           {Anchor: "SidecarProxyCPU", ValueType: registry.Quantity},
+	SidecarProxyCPULimit.Name:                      {Anchor: "SidecarProxyCPULimit", ValueType: registry.Quantity},
+	SidecarProxyImage.Name:                         {Anchor: "SidecarProxyImage"},
+	SidecarProxyImageType.Name:                     {Anchor: "SidecarProxyImageType", AllowedValues: []string{"default", "debug", "distroless"}},
+	SidecarProxyMemory.Name:                        {Anchor: "SidecarProxyMemory", ValueType: registry.Quantity},
+	SidecarProxyMemoryLimit.Name:                   {Anchor: "SidecarProxyMemoryLimit", ValueType: registry.Quantity},
+	SidecarRewriteAppHTTPProbers.Name:              {Anchor: "SidecarRewriteAppHTTPProbers", ValueType: registry.Bool},
+	SidecarStatsCompression.Name:                   {Anchor: "SidecarStatsCompression", ReplacedBy: "proxy.istio.io/config", AllowedValues: []string{"brotli", "gzip", "zstd"}},
+	SidecarStatsEvictionInterval.Name:              {Anchor: "SidecarStatsEvictionInterval", ValueType: registry.Duration},
+	SidecarStatsFlushInterval.Name:                 {Anchor: "SidecarStatsFlushInterval", ValueType: registry.Duration},
+	SidecarStatsHistogramBins.Name:                 {Anchor: "SidecarStatsHistogramBins", ValueType: registry.Int},
+	SidecarStatsHistogramBuckets.Name:              {Anchor: "SidecarStatsHistogramBuckets", ValueType: registry.JSON},
+	SidecarStatsInclusionPrefixes.Name:             {Anchor: "SidecarStatsInclusionPrefixes", ReplacedBy: "proxy.istio.io/config", ValueType: registry.StringList},
+	SidecarStatsInclusionRegexps.Name:              {Anchor: "SidecarStatsInclusionRegexps", ReplacedBy: "proxy.istio.io/config", ValueType: registry.StringList},
+	SidecarStatsInclusionSuffixes.Name:             {Anchor: "SidecarStatsInclusionSuffixes", ReplacedBy: "proxy.istio.io/config", ValueType: registry.StringList},
+	SidecarStatus.Name:                             {Anchor: "SidecarStatus", ValueType: registry.JSON},
+	SidecarStatusPort.Name:                         {Anchor: "SidecarStatusPort", ValueType: registry.Int},
+	SidecarStatusReadinessApplicationPorts.Name:    {Anchor: "SidecarStatusReadinessApplicationPorts", ValueType: registry.PortList},
+	SidecarStatusReadinessFailureThreshold.Name:    {Anchor: "SidecarStatusReadinessFailureThreshold", ValueType: registry.Int},
+	SidecarStatusReadinessInitialDelaySeconds.Name: {Anchor: "SidecarStatusReadinessInitialDelaySeconds", ValueType: registry.Int},
+	SidecarStatusReadinessPeriodSeconds.Name:       {Anchor: "SidecarStatusReadinessPeriodSeconds", ValueType: registry.Int},
+	SidecarTrafficExcludeInboundPorts.Name:         {Anchor: "SidecarTrafficExcludeInboundPorts", ValueType: registry.PortList},
+	SidecarTrafficExcludeInterfaces.Name:           {Anchor: "SidecarTrafficExcludeInterfaces", ValueType: registry.StringList},
+	SidecarTrafficExcludeOutboundIPRanges.Name:     {Anchor: "SidecarTrafficExcludeOutboundIPRanges", ValueType: registry.CIDRList},
+	SidecarTrafficExcludeOutboundPorts.Name:        {Anchor: "SidecarTrafficExcludeOutboundPorts", ValueType: registry.PortList},
+	SidecarTrafficIncludeInboundPorts.Name:         {Anchor: "SidecarTrafficIncludeInboundPorts", ValueType: registry.PortList},
+	SidecarTrafficIncludeOutboundIPRanges.Name:     {Anchor: "SidecarTrafficIncludeOutboundIPRanges", ValueType: registry.CIDRList},
+	SidecarTrafficIncludeOutboundPorts.Name:        {Anchor: "SidecarTrafficIncludeOutboundPorts", ValueType: registry.PortList},
+	SidecarTrafficKubevirtInterfaces.Name:          {Anchor: "SidecarTrafficKubevirtInterfaces", ReplacedBy: "istio.io/reroute-virtual-interfaces", ValueType: registry.StringList},
+	SidecarUserVolume.Name:                         {Anchor: "SidecarUserVolume", ValueType: registry.JSON},
+	SidecarUserVolumeMount.Name:                    {Anchor: "SidecarUserVolumeMount", ValueType: registry.JSON},
+	TopologyControlPlaneClusters.Name:              {Anchor: "TopologyControlPlaneClusters", ValueType: registry.StringList},
+	TrafficNodeSelector.Name:                       {Anchor: "TrafficNodeSelector"},
+}
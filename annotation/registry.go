@@ -0,0 +1,79 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package annotation
+
+import (
+	"strings"
+	"sync"
+
+	"istio.io/api/internal/registry"
+	"istio.io/api/resource"
+)
+
+var index = sync.OnceValue(func() map[string]*Instance {
+	m := map[string]*Instance{}
+	for _, a := range AllResourceAnnotations() {
+		m[a.Name] = a
+	}
+	return m
+})
+
+// Lookup returns the annotation with the given name.
+func Lookup(name string) (*Instance, bool) {
+	a, f := index()[name]
+	return a, f
+}
+
+// ForResource returns the annotations that apply to resources of type r, including the ones
+// that apply to Any resource, sorted by name.
+func ForResource(r ResourceTypes) []*Instance {
+	return filter(func(a *Instance) bool {
+		for _, t := range a.Resources {
+			if t == r || t == Any {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// ForType returns the annotations that apply to resources of type t, such as the type returned by
+// resource.ParseGroupVersionKind for an object, including the ones that apply to Any resource.
+func ForType(t resource.Type) []*Instance {
+	return filter(func(a *Instance) bool {
+		return registry.AppliesTo(a.kinds(), t)
+	})
+}
+
+// WithPrefix returns the annotations whose name starts with prefix, such as "sidecar.istio.io/",
+// sorted by name.
+func WithPrefix(prefix string) []*Instance {
+	return filter(func(a *Instance) bool {
+		return strings.HasPrefix(a.Name, prefix)
+	})
+}
+
+func filter(fn func(*Instance) bool) []*Instance {
+	return registry.Filter(AllResourceAnnotations(), func(a *Instance) string { return a.Name }, fn)
+}
+
+// kinds returns the names of the resources the annotation applies to.
+func (i *Instance) kinds() []string {
+	out := make([]string, 0, len(i.Resources))
+	for _, r := range i.Resources {
+		out = append(out, r.String())
+	}
+	return out
+}
@@ -0,0 +1,126 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package annotation
+
+import (
+	"strings"
+	"testing"
+
+	"istio.io/api/label"
+	"istio.io/api/resource"
+)
+
+func TestLookup(t *testing.T) {
+	a, f := Lookup("sidecar.istio.io/proxyCPU")
+	if !f || a != &SidecarProxyCPU {
+		t.Fatalf("Lookup() = %v, %v", a, f)
+	}
+	if _, f := Lookup("sidecar.istio.io/proxyCpu"); f {
+		t.Fatal("Lookup() is case sensitive")
+	}
+}
+
+func TestForResource(t *testing.T) {
+	names := map[string]bool{}
+	for _, a := range ForResource(Service) {
+		names[a.Name] = true
+	}
+	for _, want := range []string{NetworkingExportTo.Name, GalleyAnalyzeSuppress.Name} {
+		if !names[want] {
+			t.Errorf("ForResource(Service) does not include %v", want)
+		}
+	}
+	if names[SidecarProxyCPU.Name] {
+		t.Errorf("ForResource(Service) includes %v", SidecarProxyCPU.Name)
+	}
+}
+
+func TestForType(t *testing.T) {
+	pod, err := resource.ParseGroupVersionKind("/v1, Kind=Pod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := map[string]bool{}
+	for _, a := range ForType(pod) {
+		names[a.Name] = true
+	}
+	if !names[SidecarProxyCPU.Name] || !names[GalleyAnalyzeSuppress.Name] {
+		t.Errorf("ForType(%v) = %v", pod, names)
+	}
+	if names[NetworkingExportTo.Name] {
+		t.Errorf("ForType(%v) includes %v", pod, NetworkingExportTo.Name)
+	}
+
+	// Gateway resources are the ones of the Gateway API: an Istio Gateway only gets the Any annotations.
+	if !contains(ForType(resource.Gateway), &GatewayNameOverride) {
+		t.Errorf("ForType(%v) does not include %v", resource.Gateway, GatewayNameOverride.Name)
+	}
+	if contains(ForType(resource.FromGroupKind("networking.istio.io", "Gateway")), &GatewayNameOverride) {
+		t.Errorf("ForType(networking.istio.io Gateway) includes %v", GatewayNameOverride.Name)
+	}
+}
+
+func contains(instances []*Instance, want *Instance) bool {
+	for _, i := range instances {
+		if i == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestWithPrefix(t *testing.T) {
+	got := WithPrefix("readiness.status.sidecar.istio.io/")
+	if len(got) != 4 {
+		t.Fatalf("got %d annotations, want 4", len(got))
+	}
+	for i, a := range got {
+		if i > 0 && got[i-1].Name >= a.Name {
+			t.Errorf("annotations are not sorted: %v", got)
+		}
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	if len(metadatas) != len(AllResourceAnnotations()) {
+		t.Errorf("metadatas has %d entries, want %d", len(metadatas), len(AllResourceAnnotations()))
+	}
+	for _, a := range AllResourceAnnotations() {
+		if _, f := metadatas[a.Name]; !f {
+			t.Errorf("%s: missing from metadatas", a.Name)
+		}
+		if a.ReplacedBy() != "" {
+			if !a.Deprecated {
+				t.Errorf("%s: replaced but not deprecated", a.Name)
+			}
+			_, annotation := Lookup(a.ReplacedBy())
+			_, label := label.Lookup(a.ReplacedBy())
+			if !annotation && !label {
+				t.Errorf("%s: replaced by unknown %q", a.Name, a.ReplacedBy())
+			}
+		}
+		if !a.Hidden && !strings.HasPrefix(a.Docs(), "https://istio.io/") {
+			t.Errorf("%s: missing docs link", a.Name)
+		}
+	}
+}
+
+func TestResourceTypesMatchResource(t *testing.T) {
+	for r := Any; r <= WorkloadEntry; r++ {
+		if got := resource.Type(r).String(); got != r.String() {
+			t.Errorf("resource.Type(%d) = %v, want %v", r, got, r)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package filters builds the EnvoyFilter patch values of the Envoy filters implemented by the
+// Istio proxy, and decodes them back from EnvoyFilter resources.
+package filters
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	alpn "istio.io/api/envoy/config/filter/http/alpn/v2alpha1"
+	jwtauth "istio.io/api/envoy/config/filter/http/jwt_auth/v2alpha1"
+	tcpclusterrewrite "istio.io/api/envoy/config/filter/network/tcp_cluster_rewrite/v2alpha1"
+	"istio.io/api/envoy/extensions/stats"
+	"istio.io/api/networking/v1alpha3"
+)
+
+// Names under which the Istio proxy registers the filters.
+const (
+	JWTAuthName           = "jwt-auth"
+	ALPNName              = "istio.alpn"
+	TCPClusterRewriteName = "envoy.filters.network.tcp_cluster_rewrite"
+	StatsName             = "istio.stats"
+)
+
+// TypedStructURL is the type URL of udpa.type.v1.TypedStruct, which carries the configuration
+// of filters whose types are not known to the Envoy control plane as a JSON struct.
+const TypedStructURL = "type.googleapis.com/udpa.type.v1.TypedStruct"
+
+const typeURLPrefix = "type.googleapis.com/"
+
+// configTypes maps the full name of each supported filter configuration to its default filter name.
+var configTypes = map[protoreflect.FullName]string{
+	(&jwtauth.JwtAuthentication{}).ProtoReflect().Descriptor().FullName():           JWTAuthName,
+	(&alpn.FilterConfig{}).ProtoReflect().Descriptor().FullName():                   ALPNName,
+	(&tcpclusterrewrite.TcpClusterRewrite{}).ProtoReflect().Descriptor().FullName(): TCPClusterRewriteName,
+	(&stats.PluginConfig{}).ProtoReflect().Descriptor().FullName():                  StatsName,
+}
+
+// Filter is the configuration of an Envoy filter.
+type Filter struct {
+	// Name of the filter in the Envoy configuration.
+	Name string
+	// Config is the typed configuration of the filter.
+	Config proto.Message
+}
+
+// JWTAuth returns the Istio JWT authentication HTTP filter.
+func JWTAuth(config *jwtauth.JwtAuthentication) *Filter {
+	return &Filter{Name: JWTAuthName, Config: config}
+}
+
+// ALPN returns the Istio ALPN override HTTP filter.
+func ALPN(config *alpn.FilterConfig) *Filter {
+	return &Filter{Name: ALPNName, Config: config}
+}
+
+// TCPClusterRewrite returns the Istio TCP cluster rewrite network filter.
+func TCPClusterRewrite(config *tcpclusterrewrite.TcpClusterRewrite) *Filter {
+	return &Filter{Name: TCPClusterRewriteName, Config: config}
+}
+
+// Stats returns the Istio stats filter, which can be used both as an HTTP and a network filter.
+func Stats(config *stats.PluginConfig) *Filter {
+	return &Filter{Name: StatsName, Config: config}
+}
+
+// TypeURL returns the type URL of the configuration of f.
+func (f *Filter) TypeURL() string {
+	return typeURLPrefix + string(f.Config.ProtoReflect().Descriptor().FullName())
+}
+
+// TypedConfig returns the configuration of f as an Any, for use in Envoy configuration.
+func (f *Filter) TypedConfig() (*anypb.Any, error) {
+	return anypb.New(f.Config)
+}
+
+// PatchValue returns the value of an EnvoyFilter patch adding f, with the configuration set
+// as typed_config:
+//
+//	name: istio.alpn
+//	typed_config:
+//	  '@type': type.googleapis.com/istio.envoy.config.filter.http.alpn.v2alpha1.FilterConfig
+//	  alpnOverride: ...
+func (f *Filter) PatchValue() (*structpb.Struct, error) {
+	typed, err := f.TypedConfig()
+	if err != nil {
+		return nil, err
+	}
+	config, err := toStruct(typed)
+	if err != nil {
+		return nil, err
+	}
+	return &structpb.Struct{Fields: map[string]*structpb.Value{
+		"name":         structpb.NewStringValue(f.Name),
+		"typed_config": structpb.NewStructValue(config),
+	}}, nil
+}
+
+// TypedStructPatchValue returns the value of an EnvoyFilter patch adding f, with the configuration
+// wrapped in a udpa.type.v1.TypedStruct:
+//
+//	name: istio.stats
+//	typed_config:
+//	  '@type': type.googleapis.com/udpa.type.v1.TypedStruct
+//	  type_url: type.googleapis.com/stats.PluginConfig
+//	  value: ...
+func (f *Filter) TypedStructPatchValue() (*structpb.Struct, error) {
+	value, err := toStruct(f.Config)
+	if err != nil {
+		return nil, err
+	}
+	return &structpb.Struct{Fields: map[string]*structpb.Value{
+		"name": structpb.NewStringValue(f.Name),
+		"typed_config": structpb.NewStructValue(&structpb.Struct{Fields: map[string]*structpb.Value{
+			"@type":    structpb.NewStringValue(TypedStructURL),
+			"type_url": structpb.NewStringValue(f.TypeURL()),
+			"value":    structpb.NewStructValue(value),
+		}}),
+	}}, nil
+}
+
+// Patch returns an EnvoyFilter patch applying operation with f as its value.
+func (f *Filter) Patch(operation v1alpha3.EnvoyFilter_Patch_Operation) (*v1alpha3.EnvoyFilter_Patch, error) {
+	value, err := f.PatchValue()
+	if err != nil {
+		return nil, err
+	}
+	return &v1alpha3.EnvoyFilter_Patch{Operation: operation, Value: value}, nil
+}
+
+// Decode returns the filters supported by this package that are added by the patches of ef, in
+// patch order. Patches of other filters are ignored.
+func Decode(ef *v1alpha3.EnvoyFilter) ([]*Filter, error) {
+	var out []*Filter
+	for i, p := range ef.GetConfigPatches() {
+		f, err := DecodePatchValue(p.GetPatch().GetValue())
+		if err != nil {
+			return nil, fmt.Errorf("configPatches[%d]: %v", i, err)
+		}
+		if f != nil {
+			out = append(out, f)
+		}
+	}
+	return out, nil
+}
+
+// DecodePatchValue returns the filter added by an EnvoyFilter patch value, whose configuration may
+// be set directly or as a TypedStruct. It returns nil if the value does not configure a filter
+// supported by this package.
+func DecodePatchValue(value *structpb.Struct) (*Filter, error) {
+	typed := value.GetFields()["typed_config"].GetStructValue()
+	typeURL := typed.GetFields()["@type"].GetStringValue()
+	if typeURL == "" {
+		return nil, nil
+	}
+	var config *structpb.Struct
+	if typeURL == TypedStructURL {
+		typeURL = typed.GetFields()["type_url"].GetStringValue()
+		config = typed.GetFields()["value"].GetStructValue()
+	} else {
+		// All the keys but @type are fields of the message.
+		config = &structpb.Struct{Fields: map[string]*structpb.Value{}}
+		for k, v := range typed.GetFields() {
+			if k != "@type" {
+				config.Fields[k] = v
+			}
+		}
+	}
+	fullName, f := strings.CutPrefix(typeURL, typeURLPrefix)
+	if !f {
+		return nil, nil
+	}
+	name := protoreflect.FullName(fullName)
+	if _, f := configTypes[name]; !f {
+		return nil, nil
+	}
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(name)
+	if err != nil {
+		return nil, err
+	}
+	m := mt.New().Interface()
+	b, err := protojson.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	if err := protojson.Unmarshal(b, m); err != nil {
+		return nil, fmt.Errorf("invalid %v: %v", name, err)
+	}
+	filterName := value.GetFields()["name"].GetStringValue()
+	if filterName == "" {
+		filterName = configTypes[name]
+	}
+	return &Filter{Name: filterName, Config: m}, nil
+}
+
+// toStruct returns the JSON representation of m as a Struct.
+func toStruct(m proto.Message) (*structpb.Struct, error) {
+	b, err := protojson.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	s := &structpb.Struct{}
+	if err := protojson.Unmarshal(b, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
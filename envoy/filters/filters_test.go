@@ -0,0 +1,141 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filters
+
+import (
+	"encoding/json"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	alpn "istio.io/api/envoy/config/filter/http/alpn/v2alpha1"
+	jwtauth "istio.io/api/envoy/config/filter/http/jwt_auth/v2alpha1"
+	tcpclusterrewrite "istio.io/api/envoy/config/filter/network/tcp_cluster_rewrite/v2alpha1"
+	"istio.io/api/envoy/extensions/stats"
+	"istio.io/api/networking/v1alpha3"
+)
+
+func testFilters() []*Filter {
+	return []*Filter{
+		JWTAuth(&jwtauth.JwtAuthentication{Rules: []*jwtauth.JwtRule{{
+			Issuer:    "https://accounts.example.com",
+			Audiences: []string{"reviews"},
+			JwksSourceSpecifier: &jwtauth.JwtRule_RemoteJwks{RemoteJwks: &jwtauth.RemoteJwks{
+				HttpUri: &jwtauth.HttpUri{
+					Uri:              "https://accounts.example.com/jwks",
+					HttpUpstreamType: &jwtauth.HttpUri_Cluster{Cluster: "jwks"},
+					Timeout:          durationpb.New(5e9),
+				},
+			}},
+		}}}),
+		ALPN(&alpn.FilterConfig{AlpnOverride: []*alpn.FilterConfig_AlpnOverride{{
+			UpstreamProtocol: alpn.FilterConfig_HTTP2,
+			AlpnOverride:     []string{"istio-h2", "h2"},
+		}}}),
+		TCPClusterRewrite(&tcpclusterrewrite.TcpClusterRewrite{ClusterPattern: `\.global$`, ClusterReplacement: ".svc.cluster.local"}),
+		Stats(&stats.PluginConfig{StatPrefix: "istio_", TcpReportingDuration: durationpb.New(15e9)}),
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for name, value := range map[string]func(*Filter) (*structpb.Struct, error){
+		"typed_config": (*Filter).PatchValue,
+		"TypedStruct":  (*Filter).TypedStructPatchValue,
+	} {
+		t.Run(name, func(t *testing.T) {
+			ef := &v1alpha3.EnvoyFilter{}
+			want := testFilters()
+			for _, f := range want {
+				v, err := value(f)
+				if err != nil {
+					t.Fatal(err)
+				}
+				ef.ConfigPatches = append(ef.ConfigPatches, &v1alpha3.EnvoyFilter_EnvoyConfigObjectPatch{
+					ApplyTo: v1alpha3.EnvoyFilter_HTTP_FILTER,
+					Patch:   &v1alpha3.EnvoyFilter_Patch{Operation: v1alpha3.EnvoyFilter_Patch_INSERT_BEFORE, Value: v},
+				})
+			}
+			// The resource goes through its JSON representation, as it would when applied.
+			b, err := json.Marshal(ef)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded := &v1alpha3.EnvoyFilter{}
+			if err := json.Unmarshal(b, decoded); err != nil {
+				t.Fatal(err)
+			}
+			got, err := Decode(decoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(want) {
+				t.Fatalf("got %d filters, want %d", len(got), len(want))
+			}
+			for i := range want {
+				if got[i].Name != want[i].Name || !proto.Equal(got[i].Config, want[i].Config) {
+					t.Errorf("filter %d changed:\ngot  %v %v\nwant %v %v", i, got[i].Name, got[i].Config, want[i].Name, want[i].Config)
+				}
+			}
+		})
+	}
+}
+
+func TestPatchValue(t *testing.T) {
+	v, err := ALPN(&alpn.FilterConfig{AlpnOverride: []*alpn.FilterConfig_AlpnOverride{{AlpnOverride: []string{"h2"}}}}).PatchValue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(v.AsMap())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"name":"istio.alpn","typed_config":{"@type":"type.googleapis.com/istio.envoy.config.filter.http.alpn.v2alpha1.FilterConfig","alpnOverride":[{"alpnOverride":["h2"]}]}}`
+	if string(b) != want {
+		t.Fatalf("got  %s\nwant %s", b, want)
+	}
+}
+
+func TestDecodePatchValue(t *testing.T) {
+	other, err := structpb.NewStruct(map[string]any{
+		"name": "envoy.filters.http.lua",
+		"typed_config": map[string]any{
+			"@type":      "type.googleapis.com/envoy.extensions.filters.http.lua.v3.Lua",
+			"inlineCode": "function envoy_on_request(h) end",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f, err := DecodePatchValue(other); f != nil || err != nil {
+		t.Fatalf("got %v, %v for an unsupported filter", f, err)
+	}
+
+	invalid, err := structpb.NewStruct(map[string]any{
+		"name": "istio.tcp_cluster_rewrite",
+		"typed_config": map[string]any{
+			"@type":          "type.googleapis.com/istio.envoy.config.filter.network.tcp_cluster_rewrite.v2alpha1.TcpClusterRewrite",
+			"clusterPatern":  `\.global$`,
+			"clusterReplace": ".svc.cluster.local",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DecodePatchValue(invalid); err == nil {
+		t.Fatal("expected misspelled fields to be reported")
+	}
+}
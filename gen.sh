@@ -37,5 +37,9 @@ buf generate --template buf.gen-noncrd.yaml \
 buf generate --template buf.gen-golang.yaml \
   --path envoy
 
+# The stability levels are only used by Go tooling, they are not CRDs and have no docs
+buf generate --template buf.gen-golang.yaml \
+  --path stability
+
 # Format Protobuf files
 buf format -w
@@ -0,0 +1,122 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"istio.io/api/internal/suggest"
+)
+
+// FindingKind classifies a Finding.
+type FindingKind int
+
+const (
+	// Unknown is an entry in an Istio domain that Istio does not define.
+	Unknown FindingKind = iota
+	// Misplaced is an entry set on a kind of resource it does not apply to.
+	Misplaced
+	// Deprecated is an entry that is deprecated.
+	Deprecated
+	// InvalidValue is an entry whose value does not match its value type.
+	InvalidValue
+)
+
+// Finding is a problem with an annotation or label of an object.
+type Finding struct {
+	Kind       FindingKind
+	Key        string
+	Suggestion string
+	Message    string
+}
+
+// Entry is what Lint needs to know about an annotation or label.
+type Entry struct {
+	// Resources are the kinds the entry applies to, "Any" for all of them.
+	Resources  []string
+	Deprecated bool
+	Metadata   *Metadata
+}
+
+// Linter checks the annotations or the labels of objects.
+type Linter struct {
+	// Noun is "annotation" or "label", as used in messages.
+	Noun string
+	// Names returns the names of all the known entries.
+	Names func() []string
+	// Lookup returns the entry with the given name.
+	Lookup func(name string) (Entry, bool)
+}
+
+// Lint checks the values set on an object of the given kind, such as "Pod", against the known
+// entries. Keys outside of the istio.io domains are ignored. Findings are sorted by key.
+func (l Linter) Lint(kind string, values map[string]string) []Finding {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var findings []Finding
+	for _, k := range keys {
+		e, f := l.Lookup(k)
+		if !f {
+			if !isIstioDomain(k) {
+				continue
+			}
+			finding := Finding{Kind: Unknown, Key: k, Message: fmt.Sprintf("unknown %s %q", l.Noun, k)}
+			if s := suggest.Closest(k, l.Names()); s != "" {
+				finding.Suggestion = s
+				finding.Message += fmt.Sprintf(", did you mean %q?", s)
+			}
+			findings = append(findings, finding)
+			continue
+		}
+		if !slices.Contains(e.Resources, "Any") && !slices.Contains(e.Resources, kind) {
+			findings = append(findings, Finding{
+				Kind:    Misplaced,
+				Key:     k,
+				Message: fmt.Sprintf("%s %q does not apply to %s, only to %v", l.Noun, k, kind, e.Resources),
+			})
+		}
+		if e.Deprecated {
+			finding := Finding{Kind: Deprecated, Key: k, Message: fmt.Sprintf("%s %q is deprecated", l.Noun, k)}
+			if r := e.Metadata.ReplacedBy; r != "" {
+				finding.Suggestion = r
+				finding.Message += fmt.Sprintf(", use %q instead", r)
+			}
+			findings = append(findings, finding)
+		}
+		if _, err := e.Metadata.Parse(values[k]); err != nil {
+			findings = append(findings, Finding{Kind: InvalidValue, Key: k, Message: InvalidValueError(l.Noun, k, values[k], err).Error()})
+		}
+	}
+	return findings
+}
+
+// InvalidValueError returns the error reported for a value of the annotation or label name that
+// Metadata.Parse rejected with err.
+func InvalidValueError(noun, name, value string, err error) error {
+	return fmt.Errorf("invalid value %q for %s %s: %v", value, noun, name, err)
+}
+
+// isIstioDomain returns true if the prefix of key is istio.io or one of its subdomains.
+func isIstioDomain(key string) bool {
+	domain, _, f := strings.Cut(key, "/")
+	return f && (domain == "istio.io" || strings.HasSuffix(domain, ".istio.io"))
+}
@@ -0,0 +1,42 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"slices"
+	"sort"
+
+	"istio.io/api/resource"
+)
+
+// Filter returns the elements of all for which keep is true, sorted by name.
+func Filter[T any](all []T, name func(T) string, keep func(T) bool) []T {
+	var out []T
+	for _, e := range all {
+		if keep(e) {
+			out = append(out, e)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return name(out[i]) < name(out[j]) })
+	return out
+}
+
+// AppliesTo returns true if resources, kinds as named by the generated ResourceTypes, include
+// Any or the kind of t. A kind names a single resource.Type, so the group of t is matched too.
+func AppliesTo(resources []string, t resource.Type) bool {
+	return slices.ContainsFunc(resources, func(r string) bool {
+		return r == "Any" || resource.FromKind(r) == t
+	})
+}
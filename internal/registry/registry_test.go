@@ -0,0 +1,64 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"regexp"
+	"testing"
+
+	"istio.io/api/resource"
+)
+
+func TestParseValueType(t *testing.T) {
+	for v := String; v <= JSON; v++ {
+		if got, err := ParseValueType(v.String()); err != nil || got != v {
+			t.Errorf("ParseValueType(%q) = %v, %v", v, got, err)
+		}
+	}
+	if _, err := ParseValueType("Float"); err == nil {
+		t.Error("ParseValueType(Float) succeeded")
+	}
+}
+
+func TestMetadataParse(t *testing.T) {
+	m := &Metadata{ValueType: StringList, Pattern: regexp.MustCompile(`^[a-z,]+$`)}
+	if _, err := m.Parse("a,b"); err != nil {
+		t.Errorf("Parse() = %v", err)
+	}
+	if _, err := m.Parse("A"); err == nil {
+		t.Error("Parse() accepted a value not matching the pattern")
+	}
+	if got := (&Metadata{}).PatternString(); got != "" {
+		t.Errorf("PatternString() = %q", got)
+	}
+}
+
+func TestAppliesTo(t *testing.T) {
+	cases := []struct {
+		resources []string
+		t         resource.Type
+		want      bool
+	}{
+		{[]string{"Any"}, resource.Unknown, true},
+		{[]string{"Pod", "Gateway"}, resource.Gateway, true},
+		{[]string{"Pod"}, resource.Service, false},
+		{[]string{"Gateway"}, resource.FromGroupKind("networking.istio.io", "Gateway"), false},
+	}
+	for _, tc := range cases {
+		if got := AppliesTo(tc.resources, tc.t); got != tc.want {
+			t.Errorf("AppliesTo(%v, %v) = %v, want %v", tc.resources, tc.t, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package registry holds the logic shared by the annotation and label packages: the types
+// of their values, how values are parsed, and how the annotations or labels of objects are linted.
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValueType is the type of the values of an annotation or label.
+type ValueType int
+
+const (
+	String ValueType = iota
+	Bool
+	Int
+	Duration
+	Quantity
+	CIDRList
+	PortList
+	StringList
+	JSON
+)
+
+var valueTypes = []string{
+	String:     "String",
+	Bool:       "Bool",
+	Int:        "Int",
+	Duration:   "Duration",
+	Quantity:   "Quantity",
+	CIDRList:   "CIDRList",
+	PortList:   "PortList",
+	StringList: "StringList",
+	JSON:       "JSON",
+}
+
+func (t ValueType) String() string {
+	if t >= 0 && int(t) < len(valueTypes) {
+		return valueTypes[t]
+	}
+	return "Unknown"
+}
+
+// ParseValueType returns the ValueType named s, such as "Bool".
+func ParseValueType(s string) (ValueType, error) {
+	if i := slices.Index(valueTypes, s); i >= 0 {
+		return ValueType(i), nil
+	}
+	return String, fmt.Errorf("unknown value type %q", s)
+}
+
+// Metadata describes an annotation or label beyond its generated Instance.
+type Metadata struct {
+	// Anchor of the entry on the documentation page, empty for hidden entries.
+	Anchor string
+	// ReplacedBy is the name of the annotation or label replacing the entry, if it is deprecated.
+	ReplacedBy    string
+	ValueType     ValueType
+	AllowedValues []string
+	// Pattern the values must match, if any. It is compiled once, when the metadata is created.
+	Pattern *regexp.Regexp
+}
+
+// PatternString returns the source of Pattern, or "" if there is none.
+func (m *Metadata) PatternString() string {
+	if m.Pattern == nil {
+		return ""
+	}
+	return m.Pattern.String()
+}
+
+// quantityRegexp matches a Kubernetes resource quantity, such as "100m" or "1Gi".
+var quantityRegexp = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)(([KMGTPE]i)|[numkMGTPE]|([eE][+-]?\d+))?$`)
+
+// Parse validates value and returns it converted according to the ValueType: string for String
+// and Quantity, bool for Bool, int64 for Int, time.Duration for Duration, []string for the list
+// types and the decoded document for JSON.
+func (m *Metadata) Parse(value string) (any, error) {
+	if len(m.AllowedValues) > 0 && !slices.Contains(m.AllowedValues, value) {
+		return nil, fmt.Errorf("must be one of %v", m.AllowedValues)
+	}
+	if m.Pattern != nil && !m.Pattern.MatchString(value) {
+		return nil, fmt.Errorf("must match %s", m.Pattern)
+	}
+	switch m.ValueType {
+	case Bool:
+		return strconv.ParseBool(value)
+	case Int:
+		return strconv.ParseInt(value, 10, 64)
+	case Duration:
+		return time.ParseDuration(value)
+	case Quantity:
+		if !quantityRegexp.MatchString(value) {
+			return nil, fmt.Errorf("not a resource quantity")
+		}
+		return value, nil
+	case CIDRList:
+		return parseList(value, func(e string) error {
+			if e == "*" {
+				return nil
+			}
+			_, err := netip.ParsePrefix(e)
+			return err
+		})
+	case PortList:
+		return parseList(value, func(e string) error {
+			if e == "*" {
+				return nil
+			}
+			p, err := strconv.ParseUint(e, 10, 16)
+			if err == nil && p == 0 {
+				err = fmt.Errorf("invalid port %q", e)
+			}
+			return err
+		})
+	case StringList:
+		return parseList(value, nil)
+	case JSON:
+		var v any
+		if err := json.Unmarshal([]byte(value), &v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+	return value, nil
+}
+
+// parseList splits a comma separated list, ignoring whitespace around elements, and validates each one.
+func parseList(value string, validate func(string) error) ([]string, error) {
+	out := []string{}
+	if strings.TrimSpace(value) == "" {
+		return out, nil
+	}
+	for _, e := range strings.Split(value, ",") {
+		e = strings.TrimSpace(e)
+		if validate != nil {
+			if err := validate(e); err != nil {
+				return nil, err
+			}
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
@@ -0,0 +1,32 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package suggest
+
+import "testing"
+
+func TestClosest(t *testing.T) {
+	candidates := []string{"sidecar.istio.io/inject", "sidecar.istio.io/interceptionMode", "istio.io/rev"}
+	for name, want := range map[string]string{
+		"sidecar.istio.io/injet":    "sidecar.istio.io/inject",
+		"sidecar.istio.io/Inject":   "sidecar.istio.io/inject",
+		"istio.io/revv":             "istio.io/rev",
+		"sidecar.istio.io/whatever": "",
+		"":                          "",
+	} {
+		if got := Closest(name, candidates); got != want {
+			t.Errorf("Closest(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
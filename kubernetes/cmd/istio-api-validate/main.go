@@ -0,0 +1,267 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command istio-api-validate validates Istio configuration files against the Istio
+// CustomResourceDefinitions, without the need for a cluster.
+//
+// Usage:
+//
+//	istio-api-validate [-o text|json|sarif] [-unknown-fields-as-warnings] PATH...
+//
+// Each PATH is a file, a directory (walked recursively for .yaml, .yml and .json files)
+// or "-" for standard input. Documents that are not Istio resources are skipped, and the
+// items of List documents are validated one by one. Documents that are not valid YAML are
+// reported as invalid if they declare an Istio apiVersion, skipped if they declare another
+// one, and reported with a warning if they declare none.
+// The exit code is 0 if all resources are valid, 1 if any resource is invalid and 2
+// if the inputs could not be read.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+
+	"istio.io/api/kubernetes/crd"
+)
+
+const (
+	exitValid   = 0
+	exitInvalid = 1
+	exitFailure = 2
+)
+
+// stageDecode reports documents that could not be parsed, before any CRD validation stage runs.
+const stageDecode crd.Stage = "decode"
+
+// document is a single YAML or JSON document of an input file.
+type document struct {
+	file string
+	// line is the 1-based line at which the document starts.
+	line int
+	text string
+}
+
+// result is the outcome of validating a single document.
+type result struct {
+	File      string        `json:"file"`
+	Line      int           `json:"line"`
+	Kind      string        `json:"kind,omitempty"`
+	Name      string        `json:"name,omitempty"`
+	Namespace string        `json:"namespace,omitempty"`
+	Findings  []crd.Finding `json:"-"`
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("istio-api-validate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	output := fs.String("o", "text", "output format, one of text, json or sarif")
+	unknownAsWarnings := fs.Bool("unknown-fields-as-warnings", false,
+		"report fields that are not part of the schema as warnings instead of errors")
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: istio-api-validate [flags] PATH...\n\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitFailure
+	}
+	write, f := writers[*output]
+	if !f {
+		fmt.Fprintf(stderr, "unknown output format %q\n", *output)
+		return exitFailure
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitFailure
+	}
+
+	v, err := crd.NewIstioValidator()
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to load CRDs: %v\n", err)
+		return exitFailure
+	}
+	docs, err := readPaths(fs.Args(), stdin)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitFailure
+	}
+	opts := crd.Options{UnknownFieldsAsWarnings: *unknownAsWarnings}
+	results := make([]result, 0, len(docs))
+	for _, d := range docs {
+		results = append(results, validate(v, d, opts)...)
+	}
+	if err := write(stdout, results); err != nil {
+		fmt.Fprintf(stderr, "failed to write output: %v\n", err)
+		return exitFailure
+	}
+	for _, r := range results {
+		for _, f := range r.Findings {
+			if f.Severity == crd.SeverityError {
+				return exitInvalid
+			}
+		}
+	}
+	return exitValid
+}
+
+// validate validates a single document. It returns a result for the document if it is an Istio
+// resource, or for each Istio resource among its items if it is a List.
+func validate(v *crd.Validator, d document, opts crd.Options) []result {
+	obj := &unstructured.Unstructured{}
+	if err := yaml.Unmarshal([]byte(d.text), &obj.Object); err != nil {
+		return invalidDocument(d, err)
+	}
+	if obj.Object == nil {
+		return nil
+	}
+	if !obj.IsList() {
+		return validateObject(v, d, obj, opts)
+	}
+	var results []result
+	items, _ := obj.Object["items"].([]any)
+	for _, item := range items {
+		if u, ok := item.(map[string]any); ok {
+			results = append(results, validateObject(v, d, &unstructured.Unstructured{Object: u}, opts)...)
+		}
+	}
+	return results
+}
+
+// validateObject validates obj, read from d. It returns no result if obj is not an Istio resource.
+func validateObject(v *crd.Validator, d document, obj *unstructured.Unstructured, opts crd.Options) []result {
+	if !isIstioGroup(obj.GroupVersionKind().Group) {
+		return nil
+	}
+	r := result{File: d.file, Line: d.line, Kind: obj.GetKind(), Name: obj.GetName(), Namespace: obj.GetNamespace()}
+	report, err := v.ValidateAll(obj, opts)
+	if err != nil {
+		r.Findings = []crd.Finding{decodeFinding(crd.SeverityError, err)}
+		return []result{r}
+	}
+	r.Findings = report.Findings
+	return []result{r}
+}
+
+// apiVersionRegexp finds the apiVersion of documents that are not valid YAML.
+var apiVersionRegexp = regexp.MustCompile(`(?m)^apiVersion:\s*["']?([^\s"'#]+)`)
+
+// invalidDocument reports d, which could not be parsed. Without a parsed document, whether it
+// is an Istio resource is only known from a top-level apiVersion line.
+func invalidDocument(d document, err error) []result {
+	r := result{File: d.file, Line: d.line}
+	m := apiVersionRegexp.FindStringSubmatch(d.text)
+	switch {
+	case m == nil:
+		r.Findings = []crd.Finding{decodeFinding(crd.SeverityWarning, err)}
+	case isIstioGroup(schema.FromAPIVersionAndKind(m[1], "").Group):
+		r.Findings = []crd.Finding{decodeFinding(crd.SeverityError, err)}
+	default:
+		return nil
+	}
+	return []result{r}
+}
+
+func decodeFinding(severity crd.Severity, err error) crd.Finding {
+	return crd.Finding{Stage: stageDecode, Severity: severity, Message: err.Error()}
+}
+
+func isIstioGroup(group string) bool {
+	return group == "istio.io" || strings.HasSuffix(group, ".istio.io")
+}
+
+// readPaths reads all documents of the given files and directories, in order.
+func readPaths(paths []string, stdin io.Reader) ([]document, error) {
+	var docs []document
+	for _, p := range paths {
+		if p == "-" {
+			data, err := io.ReadAll(stdin)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read standard input: %v", err)
+			}
+			docs = append(docs, splitDocuments("<stdin>", data)...)
+			continue
+		}
+		err := filepath.WalkDir(p, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			// Explicitly named files are always read, files found in directories only by extension.
+			if d.IsDir() || (path != p && !isManifest(path)) {
+				return nil
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			docs = append(docs, splitDocuments(path, data)...)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %v: %v", p, err)
+		}
+	}
+	return docs, nil
+}
+
+func isManifest(path string) bool {
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// splitDocuments splits a multi-document YAML file on lines starting with '---', the same way
+// crd.SplitString does, while keeping track of the line each document starts at.
+func splitDocuments(file string, data []byte) []document {
+	var docs []document
+	var cur []string
+	start := 1
+	flush := func() {
+		text := strings.TrimSpace(strings.Join(cur, "\n"))
+		if text != "" {
+			// Point at the first non-empty line of the document.
+			for _, l := range cur {
+				if strings.TrimSpace(l) != "" {
+					break
+				}
+				start++
+			}
+			docs = append(docs, document{file: file, line: start, text: text})
+		}
+		cur = nil
+	}
+	for i, l := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(l, "---") {
+			flush()
+			start = i + 2
+			continue
+		}
+		cur = append(cur, l)
+	}
+	flush()
+	return docs
+}
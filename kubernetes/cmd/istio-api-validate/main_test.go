@@ -0,0 +1,175 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"istio.io/api/analysis/v1alpha1/export"
+)
+
+const validManifest = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: ignored
+---
+apiVersion: networking.istio.io/v1
+kind: VirtualService
+metadata:
+  name: reviews
+  namespace: default
+spec:
+  hosts:
+  - reviews
+`
+
+const invalidManifest = `apiVersion: networking.istio.io/v1
+kind: ServiceEntry
+metadata:
+  name: external
+  namespace: default
+spec:
+  hosts:
+  - example.com
+  resolution: NONE
+  endpoints:
+  - address: 1.2.3.4
+`
+
+const listManifest = `apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: ignored
+- apiVersion: networking.istio.io/v1
+  kind: ServiceEntry
+  metadata:
+    name: external
+    namespace: default
+  spec:
+    hosts:
+    - example.com
+    resolution: NONE
+    endpoints:
+    - address: 1.2.3.4
+`
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestRun(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"valid.yaml":          validManifest,
+		"nested/invalid.yaml": invalidManifest,
+		"README.md":           "not a manifest",
+	})
+
+	cases := []struct {
+		name     string
+		args     []string
+		stdin    string
+		wantCode int
+		wantOut  string
+	}{
+		{"valid", []string{filepath.Join(dir, "valid.yaml")}, "", exitValid, "1 resources validated, 0 invalid"},
+		{"directory", []string{dir}, "", exitInvalid, "nested/invalid.yaml:1: ServiceEntry/default/external: error [cel]"},
+		{"stdin", []string{"-"}, invalidManifest, exitInvalid, "<stdin>:1:"},
+		{"broken non-Istio document", []string{"-"}, "apiVersion: v1\nkind: ConfigMap\ndata: [\n---\n" + validManifest, exitValid,
+			"1 resources validated, 0 invalid"},
+		{"broken Istio document", []string{"-"}, "apiVersion: networking.istio.io/v1\nkind: Gateway\nspec: [\n", exitInvalid,
+			"<stdin>:1: //: error [decode]"},
+		{"broken document without apiVersion", []string{"-"}, "kind: [\n", exitValid, "<stdin>:1: //: warning [decode]"},
+		{"list", []string{"-"}, listManifest, exitInvalid, "<stdin>:1: ServiceEntry/default/external: error [cel]"},
+		{"unknown format", []string{"-o", "xml", dir}, "", exitFailure, ""},
+		{"missing path", []string{filepath.Join(dir, "missing.yaml")}, "", exitFailure, ""},
+		{"no args", nil, "", exitFailure, ""},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+			code := run(tt.args, strings.NewReader(tt.stdin), stdout, stderr)
+			if code != tt.wantCode {
+				t.Fatalf("got exit code %d, want %d; stdout: %s stderr: %s", code, tt.wantCode, stdout, stderr)
+			}
+			if !strings.Contains(stdout.String(), tt.wantOut) {
+				t.Fatalf("output %q does not contain %q", stdout, tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestRunJSONAndSARIF(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"all.yaml": validManifest + "---\n" + invalidManifest})
+
+	stdout := &bytes.Buffer{}
+	if code := run([]string{"-o", "json", dir}, nil, stdout, &bytes.Buffer{}); code != exitInvalid {
+		t.Fatalf("got exit code %d", code)
+	}
+	var results []jsonResult
+	if err := json.Unmarshal(stdout.Bytes(), &results); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || !results[0].Valid || results[1].Valid || results[1].Line != 15 {
+		t.Fatalf("unexpected results: %s", stdout)
+	}
+	if f := results[1].Findings; len(f) != 1 || f[0].Message != "NONE mode cannot set endpoints" {
+		t.Fatalf("unexpected findings: %+v", f)
+	}
+
+	stdout.Reset()
+	if code := run([]string{"-o", "sarif", dir}, nil, stdout, &bytes.Buffer{}); code != exitInvalid {
+		t.Fatalf("got exit code %d", code)
+	}
+	var log export.SARIFLog
+	if err := json.Unmarshal(stdout.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 || len(log.Runs[0].Results) != 1 {
+		t.Fatalf("unexpected SARIF log: %s", stdout)
+	}
+	res := log.Runs[0].Results[0]
+	if res.RuleID != "cel" || res.Level != "error" || res.Locations[0].PhysicalLocation.Region.StartLine != 15 {
+		t.Fatalf("unexpected SARIF result: %+v", res)
+	}
+}
+
+func TestSplitDocuments(t *testing.T) {
+	docs := splitDocuments("f.yaml", []byte("---\na: 1\n---\n\n\nb: 2\n  ---\n---\n"))
+	if len(docs) != 2 {
+		t.Fatalf("expected 2 documents, got %+v", docs)
+	}
+	if docs[0].line != 2 || docs[1].line != 6 {
+		t.Fatalf("unexpected start lines: %+v", docs)
+	}
+}
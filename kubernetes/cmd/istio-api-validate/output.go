@@ -0,0 +1,126 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"istio.io/api/analysis/v1alpha1/export"
+	"istio.io/api/kubernetes/crd"
+)
+
+var writers = map[string]func(io.Writer, []result) error{
+	"text":  writeText,
+	"json":  writeJSON,
+	"sarif": writeSARIF,
+}
+
+// message renders a finding without its severity and stage.
+func message(f crd.Finding) string {
+	if f.Error != nil {
+		return f.Error.Error()
+	}
+	return f.Message
+}
+
+func writeText(w io.Writer, results []result) error {
+	invalid := 0
+	for _, r := range results {
+		for _, f := range r.Findings {
+			if _, err := fmt.Fprintf(w, "%v:%d: %v/%v/%v: %v [%v]: %v\n",
+				r.File, r.Line, r.Kind, r.Namespace, r.Name, f.Severity, f.Stage, message(f)); err != nil {
+				return err
+			}
+		}
+		for _, f := range r.Findings {
+			if f.Severity == crd.SeverityError {
+				invalid++
+				break
+			}
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d resources validated, %d invalid\n", len(results), invalid)
+	return err
+}
+
+type jsonFinding struct {
+	Field    string       `json:"field,omitempty"`
+	Stage    crd.Stage    `json:"stage"`
+	Severity crd.Severity `json:"severity"`
+	Type     string       `json:"type,omitempty"`
+	Message  string       `json:"message"`
+}
+
+type jsonResult struct {
+	result
+	Valid    bool          `json:"valid"`
+	Findings []jsonFinding `json:"findings,omitempty"`
+}
+
+func writeJSON(w io.Writer, results []result) error {
+	out := make([]jsonResult, 0, len(results))
+	for _, r := range results {
+		jr := jsonResult{result: r, Valid: true}
+		for _, f := range r.Findings {
+			jr.Findings = append(jr.Findings, jsonFinding{
+				Field:    f.Field,
+				Stage:    f.Stage,
+				Severity: f.Severity,
+				Type:     string(f.Type),
+				Message:  f.Message,
+			})
+			if f.Severity == crd.SeverityError {
+				jr.Valid = false
+			}
+		}
+		out = append(out, jr)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+var sarifRules = []export.SARIFRule{
+	{ID: string(stageDecode), ShortDescription: &export.SARIFMessage{Text: "The document could not be decoded as an Istio resource."}},
+	{ID: string(crd.StageSchema), ShortDescription: &export.SARIFMessage{Text: "The resource does not match the OpenAPI schema."}},
+	{ID: string(crd.StageListType), ShortDescription: &export.SARIFMessage{Text: "A list violates its x-kubernetes-list-type constraints."}},
+	{ID: string(crd.StagePruning), ShortDescription: &export.SARIFMessage{Text: "The resource sets a field that is not part of the schema."}},
+	{ID: string(crd.StageCEL), ShortDescription: &export.SARIFMessage{Text: "The resource violates a CEL validation rule."}},
+}
+
+func writeSARIF(w io.Writer, results []result) error {
+	run := export.SARIFRun{
+		Tool: export.SARIFTool{Driver: export.SARIFDriver{
+			Name:           "istio-api-validate",
+			InformationURI: "https://istio.io/latest/docs/reference/config/",
+			Rules:          sarifRules,
+		}},
+		Results: []export.SARIFResult{},
+	}
+	for _, r := range results {
+		origin := export.Origin{File: r.File, Line: r.Line}
+		for _, f := range r.Findings {
+			run.Results = append(run.Results, export.SARIFResult{
+				RuleID:    string(f.Stage),
+				Level:     string(f.Severity),
+				Message:   export.SARIFMessage{Text: fmt.Sprintf("%v/%v/%v: %v", r.Kind, r.Namespace, r.Name, message(f))},
+				Locations: []export.SARIFLocation{{PhysicalLocation: origin.PhysicalLocation()}},
+			})
+		}
+	}
+	return export.NewSARIFLog(run).Write(w)
+}
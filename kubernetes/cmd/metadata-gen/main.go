@@ -0,0 +1,191 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command metadata-gen generates the metadata of the annotation and label packages, such as the
+// type of the values of each entry, from annotations.yaml and labels.yaml.
+//
+// Usage:
+//
+//	metadata-gen --input annotations.yaml --instances annotations.gen.go --output metadata.gen.go --collection_type annotation
+//
+// The instances file is the one annotations_prep generates from the same input, from which the
+// names of the variables of the entries are taken.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"istio.io/api/internal/registry"
+)
+
+// entry is an annotation or label of the input file. Only the fields used here are decoded.
+type entry struct {
+	Name          string   `json:"name"`
+	Hidden        bool     `json:"hidden"`
+	ValueType     string   `json:"valueType"`
+	AllowedValues []string `json:"allowedValues"`
+	Pattern       string   `json:"pattern"`
+	ReplacedBy    string   `json:"replacedBy"`
+}
+
+func main() {
+	input := flag.String("input", "", "annotations.yaml or labels.yaml file")
+	instances := flag.String("instances", "", "Go file generated by annotations_prep from the input")
+	output := flag.String("output", "", "Go file to generate")
+	collection := flag.String("collection_type", "annotation", "annotation or label")
+	flag.Parse()
+
+	src, err := generate(*input, *instances, *collection)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(*output, src, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// generate returns the source of the metadata of the entries of input.
+func generate(input, instances, collection string) ([]byte, error) {
+	if collection != "annotation" && collection != "label" {
+		return nil, fmt.Errorf("unknown collection type %q", collection)
+	}
+	data, err := os.ReadFile(input)
+	if err != nil {
+		return nil, err
+	}
+	var file map[string][]entry
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%s: %v", input, err)
+	}
+	entries := file[collection+"s"]
+	variables, err := variableNames(instances)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return variables[entries[i].Name] < variables[entries[j].Name] })
+	var body bytes.Buffer
+	for _, e := range entries {
+		v, f := variables[e.Name]
+		if !f {
+			return nil, fmt.Errorf("%s: %s is not generated in %s", input, e.Name, instances)
+		}
+		fields, err := metadataFields(e, v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %v", input, e.Name, err)
+		}
+		fmt.Fprintf(&body, "\t%s.Name: {%s},\n", v, strings.Join(fields, ", "))
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by kubernetes/cmd/metadata-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\nimport (\n", collection)
+	if bytes.Contains(body.Bytes(), []byte("regexp.MustCompile")) {
+		fmt.Fprintf(&b, "\t\"regexp\"\n\n")
+	}
+	fmt.Fprintf(&b, "\t\"istio.io/api/internal/registry\"\n)\n\n")
+	fmt.Fprintf(&b, "// metadatas is keyed by %s name.\n", collection)
+	fmt.Fprintf(&b, "var metadatas = map[string]*registry.Metadata{\n%s}\n", body.Bytes())
+	return format.Source(b.Bytes())
+}
+
+// metadataFields returns the fields of the registry.Metadata literal of e, whose variable is v.
+func metadataFields(e entry, v string) ([]string, error) {
+	var fields []string
+	if !e.Hidden {
+		// The documentation of the entries is generated with their variable names as anchors.
+		fields = append(fields, "Anchor: "+strconv.Quote(v))
+	}
+	if e.ReplacedBy != "" {
+		fields = append(fields, "ReplacedBy: "+strconv.Quote(e.ReplacedBy))
+	}
+	if e.ValueType != "" {
+		t, err := registry.ParseValueType(e.ValueType)
+		if err != nil {
+			return nil, err
+		}
+		if t != registry.String {
+			fields = append(fields, "ValueType: registry."+t.String())
+		}
+	}
+	if len(e.AllowedValues) > 0 {
+		values := make([]string, 0, len(e.AllowedValues))
+		for _, a := range e.AllowedValues {
+			values = append(values, strconv.Quote(a))
+		}
+		fields = append(fields, "AllowedValues: []string{"+strings.Join(values, ", ")+"}")
+	}
+	if e.Pattern != "" {
+		if _, err := regexp.Compile(e.Pattern); err != nil {
+			return nil, err
+		}
+		p := strconv.Quote(e.Pattern)
+		if !strings.Contains(e.Pattern, "`") {
+			p = "`" + e.Pattern + "`"
+		}
+		fields = append(fields, "Pattern: regexp.MustCompile("+p+")")
+	}
+	return fields, nil
+}
+
+// variableNames returns the names of the variables of the Instances declared in the Go file at
+// path, keyed by the Name of the Instance.
+func variableNames(path string) (map[string]string, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]string{}
+	ast.Inspect(f, func(n ast.Node) bool {
+		spec, ok := n.(*ast.ValueSpec)
+		if !ok || len(spec.Names) != 1 || len(spec.Values) != 1 {
+			return true
+		}
+		lit, ok := spec.Values[0].(*ast.CompositeLit)
+		if !ok {
+			return true
+		}
+		for _, elt := range lit.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				continue
+			}
+			if k, ok := kv.Key.(*ast.Ident); ok && k.Name == "Name" {
+				if v, ok := kv.Value.(*ast.BasicLit); ok && v.Kind == token.STRING {
+					name, err := strconv.Unquote(v.Value)
+					if err == nil {
+						out[name] = spec.Names[0].Name
+					}
+				}
+			}
+		}
+		return false
+	})
+	return out, nil
+}
@@ -0,0 +1,65 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGeneratedFilesAreUpToDate(t *testing.T) {
+	for _, tc := range []struct{ dir, input, instances, collection string }{
+		{"annotation", "annotations.yaml", "annotations.gen.go", "annotation"},
+		{"label", "labels.yaml", "labels.gen.go", "label"},
+	} {
+		dir := filepath.Join("..", "..", "..", tc.dir)
+		got, err := generate(filepath.Join(dir, tc.input), filepath.Join(dir, tc.instances), tc.collection)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := os.ReadFile(filepath.Join(dir, "metadata.gen.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s/metadata.gen.go is out of date, run make generate-%ss", tc.dir, tc.collection)
+		}
+	}
+}
+
+func TestGenerateRejectsInvalidEntries(t *testing.T) {
+	dir := t.TempDir()
+	instances := filepath.Join(dir, "labels.gen.go")
+	if err := os.WriteFile(instances, []byte("package label\n\nvar (\n\tFoo = Instance{Name: \"foo.istio.io/bar\"}\n)\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for name, yaml := range map[string]string{
+		"valid":         "labels:\n- name: foo.istio.io/bar\n  valueType: Bool\n",
+		"unknown type":  "labels:\n- name: foo.istio.io/bar\n  valueType: Float\n",
+		"bad pattern":   "labels:\n- name: foo.istio.io/bar\n  pattern: '['\n",
+		"not generated": "labels:\n- name: foo.istio.io/baz\n",
+	} {
+		input := filepath.Join(dir, "labels.yaml")
+		if err := os.WriteFile(input, []byte(yaml), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := generate(input, instances, "label")
+		if (err == nil) != (name == "valid") {
+			t.Errorf("%s: generate() error = %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crd
+
+import (
+	"fmt"
+
+	structuraldefaulting "k8s.io/apiextensions-apiserver/pkg/apiserver/schema/defaulting"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// Stage identifies one of the checks the Kubernetes API server runs against a custom resource.
+type Stage string
+
+const (
+	// StageSchema is the OpenAPI v3 schema validation.
+	StageSchema Stage = "schema"
+	// StageListType checks the uniqueness constraints of x-kubernetes-list-type set and map lists.
+	StageListType Stage = "listType"
+	// StagePruning reports fields that are not part of the schema and would be dropped.
+	StagePruning Stage = "pruning"
+	// StageCEL evaluates the x-kubernetes-validations CEL rules.
+	StageCEL Stage = "cel"
+)
+
+// Stages lists all validation stages in the order the API server runs them.
+var Stages = []Stage{StageSchema, StageListType, StagePruning, StageCEL}
+
+// Severity of a Finding.
+type Severity string
+
+const (
+	// SeverityError means the API server would reject the resource.
+	SeverityError Severity = "error"
+	// SeverityWarning means the API server would accept the resource, but likely not as intended.
+	SeverityWarning Severity = "warning"
+)
+
+// Finding is a single problem detected while validating a resource.
+type Finding struct {
+	// Field is the JSON path of the offending field, for example "spec.http[0].retries".
+	Field string
+	// Stage is the validation stage that produced the finding.
+	Stage Stage
+	// Severity of the finding.
+	Severity Severity
+	// Type is the kind of error, as reported by the API server.
+	Type field.ErrorType
+	// Message is the human readable detail. For CEL rules this is the rule's message.
+	Message string
+	// Error is the underlying field error.
+	Error *field.Error
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%v [%v] %v", f.Severity, f.Stage, f.Error.Error())
+}
+
+// Report holds the result of validating a single resource with ValidateAll.
+type Report struct {
+	GroupVersionKind schema.GroupVersionKind
+	Name             string
+	Namespace        string
+	Findings         []Finding
+}
+
+// Errors returns the findings with SeverityError.
+func (r *Report) Errors() []Finding {
+	return r.filter(SeverityError)
+}
+
+// Warnings returns the findings with SeverityWarning.
+func (r *Report) Warnings() []Finding {
+	return r.filter(SeverityWarning)
+}
+
+// HasErrors returns true if any finding has SeverityError.
+func (r *Report) HasErrors() bool {
+	return len(r.Errors()) > 0
+}
+
+func (r *Report) filter(s Severity) []Finding {
+	var out []Finding
+	for _, f := range r.Findings {
+		if f.Severity == s {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// Options tunes the behavior of ValidateAll.
+type Options struct {
+	// UnknownFieldsAsWarnings reports fields that the API server would prune as warnings
+	// instead of errors. The API server silently drops them, so they are usually a typo.
+	UnknownFieldsAsWarnings bool
+}
+
+// ValidateAll validates o and returns every finding of every validation stage, rather than
+// only the first failing one. Schema defaults are applied to a copy of o before validation.
+// An error is returned only if o cannot be converted or its kind has no CustomResourceDefinition
+// and SkipMissing is not set.
+func (v *Validator) ValidateAll(o runtime.Object, opts Options) (*Report, error) {
+	un, err := toUnstructured(o)
+	if err != nil {
+		return nil, err
+	}
+	gvk := un.GroupVersionKind()
+	report := &Report{
+		GroupVersionKind: gvk,
+		Name:             un.GetName(),
+		Namespace:        un.GetNamespace(),
+	}
+	if _, f := v.byGvk[gvk]; !f {
+		if v.SkipMissing {
+			return report, nil
+		}
+		return nil, fmt.Errorf("failed to validate type %v: no validator found", gvk)
+	}
+	un = un.DeepCopy()
+	structuraldefaulting.Default(un.Object, v.structural[gvk])
+	for _, stage := range Stages {
+		severity := SeverityError
+		if stage == StagePruning && opts.UnknownFieldsAsWarnings {
+			severity = SeverityWarning
+		}
+		for _, e := range v.validateStage(stage, gvk, un) {
+			report.Findings = append(report.Findings, Finding{
+				Field:    e.Field,
+				Stage:    stage,
+				Severity: severity,
+				Type:     e.Type,
+				Message:  e.Detail,
+				Error:    e,
+			})
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,306 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package crd validates custom resources against their CustomResourceDefinition, the same way
+// the Kubernetes API server would: structural schema, defaulting, list-type, pruning and CEL rules.
+//
+// Validate stops at the first failing stage, like the API server. ValidateAll runs all of the
+// Stages and reports every finding, optionally downgrading pruned fields to warnings:
+//
+//	v, err := crd.NewIstioValidator()
+//	report, err := v.ValidateAll(obj, crd.Options{UnknownFieldsAsWarnings: true})
+package crd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apiextval "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/validation"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/schema/cel"
+	structuraldefaulting "k8s.io/apiextensions-apiserver/pkg/apiserver/schema/defaulting"
+	structurallisttype "k8s.io/apiextensions-apiserver/pkg/apiserver/schema/listtype"
+	structuralpruning "k8s.io/apiextensions-apiserver/pkg/apiserver/schema/pruning"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/validation"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	kubeyaml "k8s.io/apimachinery/pkg/util/yaml"
+	celconfig "k8s.io/apiserver/pkg/apis/cel"
+
+	"istio.io/api/kubernetes"
+)
+
+// Validator validates custom resources against a set of CustomResourceDefinitions.
+// It is safe for concurrent use once constructed.
+type Validator struct {
+	byGvk      map[schema.GroupVersionKind]validation.SchemaCreateValidator
+	structural map[schema.GroupVersionKind]*structuralschema.Structural
+	cel        map[schema.GroupVersionKind]*cel.Validator
+	// If enabled, resources without a validator will be ignored. Otherwise, they will fail.
+	SkipMissing bool
+}
+
+// Split where the '---' appears at the very beginning of a line. This will avoid
+// accidentally splitting in cases where yaml resources contain nested yaml (which
+// is indented).
+var splitRegex = regexp.MustCompile(`(^|\n)---`)
+
+// SplitString splits the given yaml doc if it's multipart document.
+func SplitString(yamlText string) []string {
+	out := make([]string, 0)
+	parts := splitRegex.Split(yamlText, -1)
+	for _, part := range parts {
+		part := strings.TrimSpace(part)
+		if len(part) > 0 {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// ValidateCustomResource validates o, returning a single error describing the first failing
+// validation stage, prefixed with the kind, name and namespace of the object.
+func (v *Validator) ValidateCustomResource(o runtime.Object) error {
+	un, err := toUnstructured(o)
+	if err != nil {
+		return err
+	}
+	if _, f := v.byGvk[un.GroupVersionKind()]; !f && !v.SkipMissing {
+		return fmt.Errorf("failed to validate type %v: no validator found", un.GroupVersionKind())
+	}
+	if errs := v.Validate(un); len(errs) > 0 {
+		return fmt.Errorf("%v/%v/%v: %v", un.GroupVersionKind().Kind, un.GetName(), un.GetNamespace(), errs.ToAggregate())
+	}
+	return nil
+}
+
+// Validate validates o and returns the errors of the first failing validation stage. Schema
+// defaults are applied to a copy of o before validation. An object whose kind has no
+// CustomResourceDefinition results in an error, unless SkipMissing is set.
+// Use ValidateAll to run every stage.
+func (v *Validator) Validate(o runtime.Object) field.ErrorList {
+	un, err := toUnstructured(o)
+	if err != nil {
+		return field.ErrorList{field.InternalError(nil, err)}
+	}
+	gvk := un.GroupVersionKind()
+	if _, f := v.byGvk[gvk]; !f {
+		if v.SkipMissing {
+			return nil
+		}
+		return field.ErrorList{field.NotSupported[string](field.NewPath("kind"), gvk.String(), nil)}
+	}
+	// Fill in defaults. toUnstructured returns the map of an *unstructured.Unstructured
+	// as is, so they are applied to a copy.
+	un = un.DeepCopy()
+	structuraldefaulting.Default(un.Object, v.structural[gvk])
+	for _, stage := range Stages {
+		if errs := v.validateStage(stage, gvk, un); len(errs) > 0 {
+			return errs
+		}
+	}
+	return nil
+}
+
+// validateStage runs a single validation stage against a defaulted object.
+func (v *Validator) validateStage(stage Stage, gvk schema.GroupVersionKind, un *unstructured.Unstructured) field.ErrorList {
+	structural := v.structural[gvk]
+	switch stage {
+	case StageSchema:
+		return validation.ValidateCustomResource(nil, un.Object, v.byGvk[gvk])
+	case StageListType:
+		return structurallisttype.ValidateListSetsAndMaps(nil, structural, un.Object)
+	case StagePruning:
+		pruneOpts := structuralschema.UnknownFieldPathOptions{TrackUnknownFieldPaths: true}
+		unknownFieldPaths := structuralpruning.PruneWithOptions(un.DeepCopy().Object, structural, false, pruneOpts)
+		errs := make(field.ErrorList, 0, len(unknownFieldPaths))
+		for _, p := range unknownFieldPaths {
+			errs = append(errs, field.Forbidden(field.NewPath(p), "unknown field"))
+		}
+		return errs
+	case StageCEL:
+		if celv := v.cel[gvk]; celv != nil {
+			errs, _ := celv.Validate(context.Background(), nil, structural, un.Object, nil, celconfig.RuntimeCELCostBudget)
+			return errs
+		}
+	}
+	return nil
+}
+
+func toUnstructured(o runtime.Object) (*unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(o)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: content}, nil
+}
+
+// NewIstioValidator returns a validator for all Istio custom resources, built from the
+// CustomResourceDefinitions embedded in this module.
+func NewIstioValidator() (*Validator, error) {
+	return NewValidatorFromReader(bytes.NewReader(kubernetes.CustomResourceDefinitions))
+}
+
+// NewValidatorFromFiles returns a validator for the CustomResourceDefinitions in the given YAML or JSON files.
+func NewValidatorFromFiles(files ...string) (*Validator, error) {
+	crds := []apiextensions.CustomResourceDefinition{}
+	for _, file := range files {
+		data, err := os.Open(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read input yaml file: %v", err)
+		}
+		fileCRDs, err := decodeCRDs(data)
+		data.Close()
+		if err != nil {
+			return nil, err
+		}
+		crds = append(crds, fileCRDs...)
+	}
+	return NewValidatorFromCRDs(crds...)
+}
+
+// NewValidatorFromReader returns a validator for the CustomResourceDefinitions in the given YAML or JSON stream.
+func NewValidatorFromReader(r io.Reader) (*Validator, error) {
+	crds, err := decodeCRDs(r)
+	if err != nil {
+		return nil, err
+	}
+	return NewValidatorFromCRDs(crds...)
+}
+
+func decodeCRDs(r io.Reader) ([]apiextensions.CustomResourceDefinition, error) {
+	crds := []apiextensions.CustomResourceDefinition{}
+	yamlDecoder := kubeyaml.NewYAMLOrJSONDecoder(r, 512*1024)
+	for {
+		un := &unstructured.Unstructured{}
+		err := yamlDecoder.Decode(&un)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		crd := apiextensions.CustomResourceDefinition{}
+		switch un.GroupVersionKind() {
+		case schema.GroupVersionKind{
+			Group:   "apiextensions.k8s.io",
+			Version: "v1",
+			Kind:    "CustomResourceDefinition",
+		}:
+			crdv1 := apiextensionsv1.CustomResourceDefinition{}
+			if err := runtime.DefaultUnstructuredConverter.
+				FromUnstructured(un.UnstructuredContent(), &crdv1); err != nil {
+				return nil, err
+			}
+			if err := apiextensionsv1.Convert_v1_CustomResourceDefinition_To_apiextensions_CustomResourceDefinition(&crdv1, &crd, nil); err != nil {
+				return nil, err
+			}
+		case schema.GroupVersionKind{
+			Group:   "apiextensions.k8s.io",
+			Version: "v1beta1",
+			Kind:    "CustomResourceDefinition",
+		}:
+			crdv1beta1 := apiextensionsv1beta1.CustomResourceDefinition{}
+			if err := runtime.DefaultUnstructuredConverter.
+				FromUnstructured(un.UnstructuredContent(), &crdv1beta1); err != nil {
+				return nil, err
+			}
+			if err := apiextensionsv1beta1.Convert_v1beta1_CustomResourceDefinition_To_apiextensions_CustomResourceDefinition(&crdv1beta1, &crd, nil); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unknown CRD type: %v", un.GroupVersionKind())
+		}
+		crds = append(crds, crd)
+	}
+	return crds, nil
+}
+
+// NewValidatorFromCRDs returns a validator for the given CustomResourceDefinitions.
+func NewValidatorFromCRDs(crds ...apiextensions.CustomResourceDefinition) (*Validator, error) {
+	v := &Validator{
+		byGvk:      map[schema.GroupVersionKind]validation.SchemaCreateValidator{},
+		structural: map[schema.GroupVersionKind]*structuralschema.Structural{},
+		cel:        map[schema.GroupVersionKind]*cel.Validator{},
+	}
+	for _, crd := range crds {
+		versions := crd.Spec.Versions
+		if len(versions) == 0 {
+			versions = []apiextensions.CustomResourceDefinitionVersion{{Name: crd.Spec.Version}} // nolint: staticcheck
+		}
+		for _, v := range versions {
+			crd.Status.StoredVersions = append(crd.Status.StoredVersions, v.Name)
+		}
+		errs := apiextval.ValidateCustomResourceDefinition(context.Background(), &crd)
+		if len(errs) > 0 {
+			return nil, fmt.Errorf("CRD %v is not valid: %v", crd.Name, formatError(errs))
+		}
+		for _, ver := range versions {
+			gvk := schema.GroupVersionKind{
+				Group:   crd.Spec.Group,
+				Version: ver.Name,
+				Kind:    crd.Spec.Names.Kind,
+			}
+			crdSchema := ver.Schema
+			if crdSchema == nil {
+				crdSchema = crd.Spec.Validation
+			}
+			if crdSchema == nil {
+				return nil, fmt.Errorf("crd did not have validation defined")
+			}
+
+			schemaValidator, _, err := validation.NewSchemaValidator(crdSchema.OpenAPIV3Schema)
+			if err != nil {
+				return nil, err
+			}
+			structural, err := structuralschema.NewStructural(crdSchema.OpenAPIV3Schema)
+			if err != nil {
+				return nil, err
+			}
+
+			v.byGvk[gvk] = schemaValidator
+			v.structural[gvk] = structural
+			// CEL programs are compiled and cached here
+			if celv := cel.NewValidator(structural, true, celconfig.PerCallLimit); celv != nil {
+				v.cel[gvk] = celv
+			}
+
+		}
+	}
+
+	return v, nil
+}
+
+func formatError(errs field.ErrorList) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	s := strings.Builder{}
+	s.WriteString("\n")
+	for _, e := range errs.ToAggregate().Errors() {
+		s.WriteString(fmt.Sprintf("- %v\n", strings.TrimPrefix(e.Error(), "spec.validation.openAPIV3Schema")))
+	}
+	return errors.New(s.String())
+}
@@ -0,0 +1,163 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestValidate(t *testing.T) {
+	v, err := NewIstioValidator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "networking.istio.io/v1",
+		"kind":       "VirtualService",
+		"metadata":   map[string]any{"name": "reviews", "namespace": "default"},
+		"spec": map[string]any{
+			"hosts":   []any{"reviews"},
+			"retires": map[string]any{"attempts": int64(3)},
+		},
+	}}
+	errs := v.Validate(obj)
+	if len(errs) != 1 || errs[0].Field != "spec.retires" {
+		t.Fatalf("expected a single unknown field error for spec.retires, got %v", errs)
+	}
+	if _, f := obj.Object["spec"].(map[string]any)["retires"]; !f {
+		t.Fatalf("validation must not modify the input object")
+	}
+
+	unknown := &unstructured.Unstructured{Object: map[string]any{"apiVersion": "example.com/v1", "kind": "Unknown"}}
+	if errs := v.Validate(unknown); len(errs) == 0 {
+		t.Fatalf("expected an error for an unknown kind")
+	}
+	v.SkipMissing = true
+	if errs := v.Validate(unknown); len(errs) != 0 {
+		t.Fatalf("expected unknown kinds to be skipped, got %v", errs)
+	}
+}
+
+func TestValidateAll(t *testing.T) {
+	v, err := NewIstioValidator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "networking.istio.io/v1",
+		"kind":       "ServiceEntry",
+		"metadata":   map[string]any{"name": "external", "namespace": "default"},
+		"spec": map[string]any{
+			"hosts":      []any{"example.com"},
+			"resolution": "NONE",
+			"endpoints":  []any{map[string]any{"address": "1.2.3.4"}},
+			"exportToo":  []any{"."},
+		},
+	}}
+
+	report, err := v.ValidateAll(obj, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Name != "external" || report.Namespace != "default" || report.GroupVersionKind.Kind != "ServiceEntry" {
+		t.Fatalf("unexpected report metadata: %+v", report)
+	}
+	stages := map[Stage]Finding{}
+	for _, f := range report.Findings {
+		stages[f.Stage] = f
+	}
+	if f, ok := stages[StagePruning]; !ok || f.Field != "spec.exportToo" || f.Severity != SeverityError {
+		t.Fatalf("expected a pruning error for spec.exportToo, got %v", report.Findings)
+	}
+	if f, ok := stages[StageCEL]; !ok || f.Message != "NONE mode cannot set endpoints" {
+		t.Fatalf("expected a CEL error carrying the rule message, got %v", report.Findings)
+	}
+	if errs := v.Validate(obj); len(errs) != 1 || errs[0].Field != "spec.exportToo" {
+		t.Fatalf("expected Validate to stop at the first failing stage, got %v", errs)
+	}
+
+	report, err = v.ValidateAll(obj, Options{UnknownFieldsAsWarnings: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := report.Warnings(); len(w) != 1 || w[0].Field != "spec.exportToo" {
+		t.Fatalf("expected unknown fields as warnings, got %v", report.Findings)
+	}
+	if !report.HasErrors() {
+		t.Fatalf("expected the CEL failure to remain an error")
+	}
+
+	if _, err := v.ValidateAll(&unstructured.Unstructured{Object: map[string]any{"apiVersion": "example.com/v1", "kind": "Unknown"}}, Options{}); err == nil {
+		t.Fatalf("expected an error for an unknown kind")
+	}
+}
+
+const defaultingCRD = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+  names:
+    kind: Widget
+    listKind: WidgetList
+    plural: widgets
+    singular: widget
+  scope: Namespaced
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              replicas:
+                type: integer
+                default: 1
+`
+
+func TestValidateDoesNotModifyInput(t *testing.T) {
+	v, err := NewValidatorFromReader(strings.NewReader(defaultingCRD))
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata":   map[string]any{"name": "w", "namespace": "default"},
+		"spec":       map[string]any{},
+	}}
+	want := obj.DeepCopy()
+	if errs := v.Validate(obj); len(errs) != 0 {
+		t.Fatalf("Validate() = %v", errs)
+	}
+	if !reflect.DeepEqual(obj, want) {
+		t.Fatalf("Validate() modified the input: %v", obj.Object)
+	}
+	if _, err := v.ValidateAll(obj, Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(obj, want) {
+		t.Fatalf("ValidateAll() modified the input: %v", obj.Object)
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gatewayapi converts Istio Gateways and VirtualServices to Kubernetes Gateway API
+// resources, and back.
+//
+// The two APIs overlap but are not equivalent, so a conversion is not always exact. Fields that
+// cannot be expressed in the target API are reported as LossyFields rather than silently dropped,
+// as are fields whose meaning changes slightly, such as Istio URI prefixes, which the Gateway API
+// matches by path element. A resource converted without lossy fields converts back to an
+// equivalent one.
+package gatewayapi
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/api/networking/v1alpha3/host"
+)
+
+const (
+	// DefaultGatewayClassName is the class of the Gateways generated from Istio Gateways.
+	DefaultGatewayClassName = "istio"
+	// DefaultDomainSuffix is the cluster domain suffix of Kubernetes service host names.
+	DefaultDomainSuffix = host.DefaultDomainSuffix
+
+	// gatewayNameLabel is the label the Istio gateway deployment controller sets on the pods of
+	// the deployment it creates for a Gateway.
+	gatewayNameLabel = "gateway.networking.k8s.io/gateway-name"
+	// namespaceNameLabel is the label Kubernetes sets on namespaces to their name.
+	namespaceNameLabel = "kubernetes.io/metadata.name"
+	// istioGroup and hostnameKind identify the backends Istio accepts for hosts that are not
+	// Kubernetes services, such as ServiceEntry hosts.
+	istioGroup   = "networking.istio.io"
+	hostnameKind = "Hostname"
+)
+
+// Gateway is an Istio Gateway along with its metadata.
+type Gateway struct {
+	metav1.ObjectMeta
+	Spec *networking.Gateway
+}
+
+// VirtualService is an Istio VirtualService along with its metadata.
+type VirtualService struct {
+	metav1.ObjectMeta
+	Spec *networking.VirtualService
+}
+
+// IstioResources is a set of Istio networking resources.
+type IstioResources struct {
+	Gateways        []*Gateway
+	VirtualServices []*VirtualService
+}
+
+// GatewayAPIResources is a set of Gateway API resources.
+type GatewayAPIResources struct {
+	Gateways   []*gatewayv1.Gateway
+	HTTPRoutes []*gatewayv1.HTTPRoute
+	GRPCRoutes []*gatewayv1.GRPCRoute
+	TLSRoutes  []*gatewayv1alpha2.TLSRoute
+	TCPRoutes  []*gatewayv1alpha2.TCPRoute
+}
+
+// Options configures a conversion.
+type Options struct {
+	// GatewayClassName is the class of the generated Gateways. It defaults to
+	// DefaultGatewayClassName.
+	GatewayClassName string
+	// DomainSuffix is the cluster domain suffix. It defaults to DefaultDomainSuffix.
+	DomainSuffix string
+}
+
+// LossyField is a field of a source resource that could not be converted exactly.
+type LossyField struct {
+	// Resource identifies the source resource, as namespace/Kind/name.
+	Resource string
+	// Field is the path of the field within the resource, such as "spec.http[0].fault".
+	Field string
+	// Reason explains what was lost.
+	Reason string
+}
+
+func (l LossyField) String() string {
+	return fmt.Sprintf("%s: %s: %s", l.Resource, l.Field, l.Reason)
+}
+
+// converter holds the state of a conversion.
+type converter struct {
+	opts     Options
+	resource string
+	lossy    []LossyField
+}
+
+func newConverter(opts Options) *converter {
+	if opts.GatewayClassName == "" {
+		opts.GatewayClassName = DefaultGatewayClassName
+	}
+	if opts.DomainSuffix == "" {
+		opts.DomainSuffix = DefaultDomainSuffix
+	}
+	return &converter{opts: opts}
+}
+
+// setResource sets the resource the following lossy fields are reported for.
+func (c *converter) setResource(kind string, meta metav1.ObjectMeta) {
+	c.resource = meta.Namespace + "/" + kind + "/" + meta.Name
+}
+
+// lose reports the field at p as lossy.
+func (c *converter) lose(p *field.Path, format string, args ...any) {
+	c.lossy = append(c.lossy, LossyField{Resource: c.resource, Field: p.String(), Reason: fmt.Sprintf(format, args...)})
+}
+
+// unsupported reports the fields set in m, but not in handled, as lossy.
+func (c *converter) unsupported(p *field.Path, m proto.Message, handled ...protoreflect.Name) {
+	var names []string
+	m.ProtoReflect().Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		if !slices.Contains(handled, fd.Name()) {
+			names = append(names, fd.JSONName())
+		}
+		return true
+	})
+	slices.Sort(names)
+	for _, n := range names {
+		c.lose(p.Child(n), "not supported by the Gateway API")
+	}
+}
+
+// serviceHost returns the name and namespace of the Kubernetes service of h, which is either a
+// short name relative to namespace or a service FQDN.
+func (c *converter) serviceHost(h, namespace string) (string, string, bool) {
+	if h != "" && !strings.ContainsAny(h, ".*") {
+		return h, namespace, true
+	}
+	return host.KubernetesService(host.Name(h), c.opts.DomainSuffix)
+}
+
+// hostForService returns the host of a Kubernetes service, as a short name when it is in namespace.
+func (c *converter) hostForService(name, ns, namespace string) string {
+	if ns == "" || ns == namespace {
+		return name
+	}
+	return name + "." + ns + ".svc." + c.opts.DomainSuffix
+}
+
+func ptrTo[T any](v T) *T {
+	return &v
+}
+
+// objectMeta returns the metadata of a resource converted from one with metadata m.
+func objectMeta(m metav1.ObjectMeta) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:        m.Name,
+		Namespace:   m.Namespace,
+		Labels:      maps.Clone(m.Labels),
+		Annotations: maps.Clone(m.Annotations),
+	}
+}
+
+var invalidSectionNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// sectionName turns name into a valid Gateway API section name, or returns "" if it cannot.
+func sectionName(name string) string {
+	s := strings.Trim(invalidSectionNameChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if len(s) > 63 {
+		s = strings.TrimRight(s[:63], "-")
+	}
+	return s
+}
@@ -0,0 +1,396 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gatewayapi
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	networking "istio.io/api/networking/v1alpha3"
+)
+
+func exact(v string) *networking.StringMatch {
+	return &networking.StringMatch{MatchType: &networking.StringMatch_Exact{Exact: v}}
+}
+
+func prefix(v string) *networking.StringMatch {
+	return &networking.StringMatch{MatchType: &networking.StringMatch_Prefix{Prefix: v}}
+}
+
+// lossless returns resources whose conversion loses nothing.
+func lossless() *IstioResources {
+	return &IstioResources{
+		Gateways: []*Gateway{{
+			ObjectMeta: metav1.ObjectMeta{Name: "ingress", Namespace: "istio-system"},
+			Spec: &networking.Gateway{
+				Selector: map[string]string{gatewayNameLabel: "ingress"},
+				Servers: []*networking.Server{
+					{
+						Port:  &networking.Port{Number: 80, Protocol: "HTTP", Name: "http"},
+						Hosts: []string{"*/bookinfo.example.com", "./admin.example.com"},
+					},
+					{
+						Port:  &networking.Port{Number: 443, Protocol: "HTTPS", Name: "https"},
+						Hosts: []string{"default/bookinfo.example.com"},
+						Tls:   &networking.ServerTLSSettings{Mode: networking.ServerTLSSettings_SIMPLE, CredentialName: "bookinfo-cert"},
+					},
+					{
+						Port:  &networking.Port{Number: 8443, Protocol: "TLS", Name: "tls"},
+						Hosts: []string{"*/db.example.com"},
+						Tls:   &networking.ServerTLSSettings{Mode: networking.ServerTLSSettings_PASSTHROUGH},
+					},
+				},
+			},
+		}},
+		VirtualServices: []*VirtualService{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "bookinfo", Namespace: "default"},
+				Spec: &networking.VirtualService{
+					Hosts:    []string{"bookinfo.example.com"},
+					Gateways: []string{"istio-system/ingress"},
+					Http: []*networking.HTTPRoute{
+						{
+							Name: "reviews",
+							Match: []*networking.HTTPMatchRequest{{
+								Uri:     prefix("/"),
+								Method:  exact("GET"),
+								Headers: map[string]*networking.StringMatch{"end-user": exact("jason")},
+							}},
+							Rewrite: &networking.HTTPRewrite{Uri: "/reviews/", Authority: "reviews"},
+							Route: []*networking.HTTPRouteDestination{
+								{
+									Destination: &networking.Destination{Host: "reviews", Port: &networking.PortSelector{Number: 9080}},
+									Weight:      75,
+									Headers: &networking.Headers{
+										Request: &networking.Headers_HeaderOperations{Set: map[string]string{"x-version": "v1"}},
+									},
+								},
+								{
+									Destination: &networking.Destination{Host: "reviews.other.svc.cluster.local", Port: &networking.PortSelector{Number: 9080}},
+									Weight:      25,
+								},
+							},
+							Mirrors: []*networking.HTTPMirrorPolicy{{
+								Destination: &networking.Destination{Host: "httpbin.org", Port: &networking.PortSelector{Number: 80}},
+								Percentage:  &networking.Percent{Value: 12.5},
+							}},
+							Timeout: durationpb.New(90 * time.Second),
+							Retries: &networking.HTTPRetry{
+								Attempts:      3,
+								RetryOn:       "502,503",
+								PerTryTimeout: durationpb.New(250 * time.Millisecond),
+							},
+							Headers: &networking.Headers{
+								Response: &networking.Headers_HeaderOperations{Remove: []string{"server"}},
+							},
+						},
+						{
+							Match:    []*networking.HTTPMatchRequest{{Uri: exact("/login")}},
+							Redirect: &networking.HTTPRedirect{Uri: "/signin", Scheme: "https", RedirectPort: &networking.HTTPRedirect_Port{Port: 443}, RedirectCode: 301},
+						},
+					},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "ratings", Namespace: "default"},
+				Spec: &networking.VirtualService{
+					Hosts: []string{"ratings"},
+					Http: []*networking.HTTPRoute{{
+						Route: []*networking.HTTPRouteDestination{{
+							Destination: &networking.Destination{Host: "ratings", Port: &networking.PortSelector{Number: 9080}},
+						}},
+					}},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+				Spec: &networking.VirtualService{
+					Hosts:    []string{"db.example.com"},
+					Gateways: []string{"istio-system/ingress"},
+					Tls: []*networking.TLSRoute{{
+						Match: []*networking.TLSMatchAttributes{{SniHosts: []string{"db.example.com"}}},
+						Route: []*networking.RouteDestination{{
+							Destination: &networking.Destination{Host: "db", Port: &networking.PortSelector{Number: 5432}},
+						}},
+					}},
+				},
+			},
+		},
+	}
+}
+
+func TestToGatewayAPI(t *testing.T) {
+	out, lossy := ToGatewayAPI(lossless(), Options{})
+	if len(lossy) != 0 {
+		t.Fatalf("unexpected lossy fields: %v", lossy)
+	}
+	if len(out.Gateways) != 1 || len(out.HTTPRoutes) != 2 || len(out.TLSRoutes) != 1 || len(out.GRPCRoutes) != 0 || len(out.TCPRoutes) != 0 {
+		t.Fatalf("unexpected resources: %+v", out)
+	}
+
+	gw := out.Gateways[0]
+	if gw.Spec.GatewayClassName != DefaultGatewayClassName {
+		t.Errorf("got class %q", gw.Spec.GatewayClassName)
+	}
+	var names []string
+	for _, l := range gw.Spec.Listeners {
+		names = append(names, string(l.Name))
+	}
+	if want := []string{"http-0", "http-1", "https", "tls"}; !slices.Equal(names, want) {
+		t.Errorf("got listeners %v, want %v", names, want)
+	}
+	if from := *gw.Spec.Listeners[0].AllowedRoutes.Namespaces.From; from != gatewayv1.NamespacesFromAll {
+		t.Errorf("got routes allowed from %v", from)
+	}
+	if from := *gw.Spec.Listeners[1].AllowedRoutes.Namespaces.From; from != gatewayv1.NamespacesFromSame {
+		t.Errorf("got routes allowed from %v", from)
+	}
+	if sel := gw.Spec.Listeners[2].AllowedRoutes.Namespaces.Selector; sel == nil || sel.MatchLabels[namespaceNameLabel] != "default" {
+		t.Errorf("got namespace selector %v", sel)
+	}
+	if tls := gw.Spec.Listeners[2].TLS; tls == nil || *tls.Mode != gatewayv1.TLSModeTerminate || tls.CertificateRefs[0].Name != "bookinfo-cert" {
+		t.Errorf("got TLS %+v", tls)
+	}
+
+	route := out.HTTPRoutes[0]
+	if ref := route.Spec.ParentRefs[0]; ref.Name != "ingress" || *ref.Namespace != "istio-system" {
+		t.Errorf("got parent %+v", ref)
+	}
+	rule := route.Spec.Rules[0]
+	if *rule.Matches[0].Path.Type != gatewayv1.PathMatchPathPrefix || *rule.Matches[0].Method != gatewayv1.HTTPMethodGet {
+		t.Errorf("got match %+v", rule.Matches[0])
+	}
+	var filters []gatewayv1.HTTPRouteFilterType
+	for _, f := range rule.Filters {
+		filters = append(filters, f.Type)
+	}
+	want := []gatewayv1.HTTPRouteFilterType{
+		gatewayv1.HTTPRouteFilterResponseHeaderModifier, gatewayv1.HTTPRouteFilterURLRewrite, gatewayv1.HTTPRouteFilterRequestMirror,
+	}
+	if !slices.Equal(filters, want) {
+		t.Errorf("got filters %v, want %v", filters, want)
+	}
+	if p := rule.Filters[1].URLRewrite.Path; p.Type != gatewayv1.PrefixMatchHTTPPathModifier || *p.ReplacePrefixMatch != "/reviews/" {
+		t.Errorf("got rewrite %+v", p)
+	}
+	if m := rule.Filters[2].RequestMirror; m.Percent != nil || m.Fraction.Numerator != 125000 || *m.BackendRef.Kind != hostnameKind {
+		t.Errorf("got mirror %+v", m)
+	}
+	if ref := rule.BackendRefs[1]; *ref.Weight != 25 || *ref.Namespace != "other" || ref.Name != "reviews" {
+		t.Errorf("got backend %+v", ref)
+	}
+	if *rule.Timeouts.Request != "1m30s" || *rule.Timeouts.BackendRequest != "250ms" {
+		t.Errorf("got timeouts %+v", rule.Timeouts)
+	}
+	if !slices.Equal(rule.Retry.Codes, []gatewayv1.HTTPRouteRetryStatusCode{502, 503}) || *rule.Retry.Attempts != 3 {
+		t.Errorf("got retry %+v", rule.Retry)
+	}
+	if r := route.Spec.Rules[1].Filters[0].RequestRedirect; *r.Path.ReplaceFullPath != "/signin" || *r.StatusCode != 301 || *r.Port != 443 {
+		t.Errorf("got redirect %+v", r)
+	}
+
+	mesh := out.HTTPRoutes[1]
+	if ref := mesh.Spec.ParentRefs[0]; *ref.Kind != "Service" || ref.Name != "ratings" || len(mesh.Spec.Hostnames) != 0 {
+		t.Errorf("got mesh route %+v", mesh.Spec)
+	}
+	if out.TLSRoutes[0].Name != "db" || out.TLSRoutes[0].Spec.Hostnames[0] != "db.example.com" {
+		t.Errorf("got TLS route %+v", out.TLSRoutes[0])
+	}
+}
+
+func TestToGatewayAPIGRPC(t *testing.T) {
+	in := &IstioResources{
+		Gateways: []*Gateway{{
+			ObjectMeta: metav1.ObjectMeta{Name: "grpc", Namespace: "default"},
+			Spec: &networking.Gateway{Servers: []*networking.Server{{
+				Port:  &networking.Port{Number: 8080, Protocol: "GRPC"},
+				Hosts: []string{"*"},
+			}}},
+		}},
+		VirtualServices: []*VirtualService{{
+			ObjectMeta: metav1.ObjectMeta{Name: "echo", Namespace: "default"},
+			Spec: &networking.VirtualService{
+				Hosts:    []string{"echo.example.com"},
+				Gateways: []string{"grpc"},
+				Http: []*networking.HTTPRoute{{
+					Match: []*networking.HTTPMatchRequest{{Uri: exact("/echo.Echo/Say")}, {Uri: prefix("/echo.Admin/")}},
+					Route: []*networking.HTTPRouteDestination{{Destination: &networking.Destination{Host: "echo", Port: &networking.PortSelector{Number: 7070}}}},
+				}},
+			},
+		}},
+	}
+	out, lossy := ToGatewayAPI(in, Options{})
+	if len(lossy) != 0 {
+		t.Fatalf("unexpected lossy fields: %v", lossy)
+	}
+	if len(out.GRPCRoutes) != 1 || len(out.HTTPRoutes) != 0 {
+		t.Fatalf("expected a GRPCRoute, got %+v", out)
+	}
+	if l := out.Gateways[0].Spec.Listeners[0]; l.Name != "grpc-8080" || l.Protocol != gatewayv1.HTTPProtocolType || l.Hostname != nil {
+		t.Errorf("got listener %+v", l)
+	}
+	m := out.GRPCRoutes[0].Spec.Rules[0].Matches
+	if *m[0].Method.Service != "echo.Echo" || *m[0].Method.Method != "Say" || *m[1].Method.Service != "echo.Admin" || m[1].Method.Method != nil {
+		t.Errorf("got matches %+v", m)
+	}
+
+	back, lossy := ToIstio(out, Options{})
+	if len(lossy) != 0 {
+		t.Fatalf("unexpected lossy fields: %v", lossy)
+	}
+	if !proto.Equal(back.VirtualServices[0].Spec, in.VirtualServices[0].Spec) {
+		t.Errorf("got %v, want %v", back.VirtualServices[0].Spec, in.VirtualServices[0].Spec)
+	}
+}
+
+func TestToGatewayAPILossy(t *testing.T) {
+	in := &IstioResources{VirtualServices: []*VirtualService{{
+		ObjectMeta: metav1.ObjectMeta{Name: "reviews", Namespace: "default"},
+		Spec: &networking.VirtualService{
+			Hosts:    []string{"reviews", "example.com"},
+			ExportTo: []string{"."},
+			Http: []*networking.HTTPRoute{{
+				Match: []*networking.HTTPMatchRequest{{Uri: prefix("/api"), IgnoreUriCase: true}},
+				Route: []*networking.HTTPRouteDestination{{Destination: &networking.Destination{Host: "reviews", Subset: "v1"}}},
+				Fault: &networking.HTTPFaultInjection{Abort: &networking.HTTPFaultInjection_Abort{
+					ErrorType: &networking.HTTPFaultInjection_Abort_HttpStatus{HttpStatus: 503},
+				}},
+				Retries: &networking.HTTPRetry{Attempts: 2, RetryOn: "5xx,503"},
+			}},
+		},
+	}}}
+	out, lossy := ToGatewayAPI(in, Options{})
+	var got []string
+	for _, l := range lossy {
+		if l.Resource != "default/VirtualService/reviews" {
+			t.Errorf("unexpected resource %q", l.Resource)
+		}
+		got = append(got, l.Field)
+	}
+	want := []string{
+		"spec.exportTo",
+		"spec.hosts[1]",
+		"spec.http[0].fault",
+		"spec.http[0].match[0].ignoreUriCase",
+		"spec.http[0].match[0].uri.prefix",
+		"spec.http[0].route[0].destination.subset",
+		"spec.http[0].route[0].destination.port",
+		"spec.http[0].retries.retryOn",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("got lossy fields %v, want %v", got, want)
+	}
+	// The convertible parts are kept.
+	if r := out.HTTPRoutes[0].Spec.Rules[0]; len(r.BackendRefs) != 1 || !slices.Equal(r.Retry.Codes, []gatewayv1.HTTPRouteRetryStatusCode{503}) {
+		t.Errorf("got rule %+v", r)
+	}
+}
+
+func TestPathPrefix(t *testing.T) {
+	in := &IstioResources{VirtualServices: []*VirtualService{{
+		ObjectMeta: metav1.ObjectMeta{Name: "reviews", Namespace: "default"},
+		Spec: &networking.VirtualService{
+			Hosts: []string{"reviews"},
+			Http: []*networking.HTTPRoute{{
+				Match: []*networking.HTTPMatchRequest{{Uri: prefix("/foo/")}, {Uri: prefix("/bar")}, {Uri: prefix("/")}},
+				Route: []*networking.HTTPRouteDestination{{Destination: &networking.Destination{Host: "reviews", Port: &networking.PortSelector{Number: 9080}}}},
+			}},
+		},
+	}}}
+	out, lossy := ToGatewayAPI(in, Options{})
+	var got []string
+	for _, l := range lossy {
+		got = append(got, l.Field)
+	}
+	if want := []string{"spec.http[0].match[0].uri.prefix", "spec.http[0].match[1].uri.prefix"}; !slices.Equal(got, want) {
+		t.Errorf("got lossy fields %v, want %v", got, want)
+	}
+
+	// The Gateway API ignores the trailing slash, so like istiod the conversion drops it. Either way,
+	// an Istio prefix matches strings, not path elements.
+	back, lossy := ToIstio(out, Options{})
+	got = nil
+	for _, l := range lossy {
+		got = append(got, l.Field)
+	}
+	if want := []string{"spec.rules[0].matches[0].path", "spec.rules[0].matches[1].path"}; !slices.Equal(got, want) {
+		t.Errorf("got lossy fields %v, want %v", got, want)
+	}
+	var prefixes []string
+	for _, m := range back.VirtualServices[0].Spec.Http[0].Match {
+		prefixes = append(prefixes, m.Uri.GetPrefix())
+	}
+	if want := []string{"/foo", "/bar", "/"}; !slices.Equal(prefixes, want) {
+		t.Errorf("got prefixes %v, want %v", prefixes, want)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	in := lossless()
+	out, lossy := ToGatewayAPI(in, Options{})
+	if len(lossy) != 0 {
+		t.Fatalf("unexpected lossy fields: %v", lossy)
+	}
+	back, lossy := ToIstio(out, Options{})
+	if len(lossy) != 0 {
+		t.Fatalf("unexpected lossy fields: %v", lossy)
+	}
+	if len(back.Gateways) != len(in.Gateways) || len(back.VirtualServices) != len(in.VirtualServices) {
+		t.Fatalf("got %d gateways and %d virtual services", len(back.Gateways), len(back.VirtualServices))
+	}
+	// Server hosts in the namespace of the Gateway come back relative to it.
+	want := proto.Clone(in.Gateways[0].Spec).(*networking.Gateway)
+	want.Servers[0].Hosts = []string{"bookinfo.example.com", "./admin.example.com"}
+	want.Servers[2].Hosts = []string{"db.example.com"}
+	if got := back.Gateways[0]; got.Name != "ingress" || got.Namespace != "istio-system" || !proto.Equal(got.Spec, want) {
+		t.Errorf("got gateway %v, want %v", got.Spec, want)
+	}
+	for i, vs := range back.VirtualServices {
+		if vs.Name != in.VirtualServices[i].Name || !proto.Equal(vs.Spec, in.VirtualServices[i].Spec) {
+			t.Errorf("got virtual service %s %v, want %v", vs.Name, vs.Spec, in.VirtualServices[i].Spec)
+		}
+	}
+}
+
+func TestWeights(t *testing.T) {
+	refs := func(w ...int32) []gatewayv1.BackendRef {
+		var out []gatewayv1.BackendRef
+		for _, w := range w {
+			out = append(out, gatewayv1.BackendRef{Weight: ptrTo(w)})
+		}
+		return out
+	}
+	cases := []struct {
+		refs []gatewayv1.BackendRef
+		want []int32
+	}{
+		{refs(80, 20), []int32{80, 20}},
+		{refs(1, 1, 1), []int32{34, 33, 33}},
+		{refs(1, 2), []int32{33, 67}},
+		{[]gatewayv1.BackendRef{{}, {}}, []int32{50, 50}},
+		{[]gatewayv1.BackendRef{{}}, []int32{0}},
+	}
+	for _, c := range cases {
+		if got := weights(c.refs); !slices.Equal(got, c.want) {
+			t.Errorf("weights(%v) = %v, want %v", c.want, got, c.want)
+		}
+	}
+}
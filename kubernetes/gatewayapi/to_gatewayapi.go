@@ -0,0 +1,725 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gatewayapi
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/api/networking/v1alpha3/host"
+)
+
+// ToGatewayAPI converts Istio Gateways and VirtualServices to the Gateway API. Each Gateway
+// becomes a Gateway with a listener per server host. The HTTP routes of a VirtualService become
+// an HTTPRoute, or a GRPCRoute when all the gateways it is bound to serve gRPC, and each of its
+// TLS and TCP routes becomes a TLSRoute or TCPRoute. VirtualServices bound to the mesh are
+// attached to the Kubernetes services of their hosts.
+func ToGatewayAPI(in *IstioResources, opts Options) (*GatewayAPIResources, []LossyField) {
+	c := newConverter(opts)
+	out := &GatewayAPIResources{}
+	grpcGateways := map[string]bool{}
+	for _, gw := range in.Gateways {
+		out.Gateways = append(out.Gateways, c.convertGateway(gw))
+		grpc := len(gw.Spec.GetServers()) > 0
+		for _, s := range gw.Spec.GetServers() {
+			grpc = grpc && strings.EqualFold(s.GetPort().GetProtocol(), "GRPC")
+		}
+		grpcGateways[gw.Namespace+"/"+gw.Name] = grpc
+	}
+	for _, vs := range in.VirtualServices {
+		c.convertVirtualService(vs, grpcGateways, out)
+	}
+	return out, c.lossy
+}
+
+func (c *converter) convertGateway(gw *Gateway) *gatewayv1.Gateway {
+	c.setResource("Gateway", gw.ObjectMeta)
+	spec := field.NewPath("spec")
+	out := &gatewayv1.Gateway{
+		TypeMeta:   metav1.TypeMeta{APIVersion: gatewayv1.GroupVersion.String(), Kind: "Gateway"},
+		ObjectMeta: objectMeta(gw.ObjectMeta),
+		Spec:       gatewayv1.GatewaySpec{GatewayClassName: gatewayv1.ObjectName(c.opts.GatewayClassName)},
+	}
+	c.unsupported(spec, gw.Spec, "servers", "selector")
+	if sel := gw.Spec.GetSelector(); len(sel) > 0 && !maps.Equal(sel, map[string]string{gatewayNameLabel: gw.Name}) {
+		c.lose(spec.Child("selector"), "the Gateway API deploys a gateway per Gateway, labeled %s=%s", gatewayNameLabel, gw.Name)
+	}
+	names := map[string]bool{}
+	for i, s := range gw.Spec.GetServers() {
+		p := spec.Child("servers").Index(i)
+		c.unsupported(p, s, "port", "hosts", "tls", "name")
+		c.unsupported(p.Child("port"), s.GetPort(), "number", "protocol", "name")
+		base := sectionName(s.GetPort().GetName())
+		if base == "" {
+			base = sectionName(s.GetName())
+		}
+		if base == "" {
+			base = fmt.Sprintf("%s-%d", strings.ToLower(s.GetPort().GetProtocol()), s.GetPort().GetNumber())
+		}
+		protocol, tls := c.convertServerTLS(p, s)
+		for j, h := range s.GetHosts() {
+			nh, err := host.ParseNamespaced(h, false)
+			if err != nil {
+				c.lose(p.Child("hosts").Index(j), "invalid host: %v", err)
+				continue
+			}
+			l := gatewayv1.Listener{
+				Name:     gatewayv1.SectionName(base),
+				Port:     gatewayv1.PortNumber(s.GetPort().GetNumber()),
+				Protocol: protocol,
+				TLS:      tls,
+			}
+			if len(s.Hosts) > 1 {
+				l.Name = gatewayv1.SectionName(fmt.Sprintf("%s-%d", base, j))
+			}
+			for names[string(l.Name)] {
+				l.Name += "-x"
+			}
+			names[string(l.Name)] = true
+			if nh.Name != "*" {
+				l.Hostname = ptrTo(gatewayv1.Hostname(nh.Name))
+			}
+			l.AllowedRoutes = allowedRoutes(nh.Namespace, gw.Namespace)
+			out.Spec.Listeners = append(out.Spec.Listeners, l)
+		}
+	}
+	return out
+}
+
+// allowedRoutes returns the namespaces allowed to attach routes to a listener, given the
+// namespace part of an Istio server host.
+func allowedRoutes(ns, gatewayNamespace string) *gatewayv1.AllowedRoutes {
+	switch {
+	case ns == host.AllNamespaces:
+		return &gatewayv1.AllowedRoutes{Namespaces: &gatewayv1.RouteNamespaces{From: ptrTo(gatewayv1.NamespacesFromAll)}}
+	case ns == host.SameNamespace || ns == gatewayNamespace:
+		return &gatewayv1.AllowedRoutes{Namespaces: &gatewayv1.RouteNamespaces{From: ptrTo(gatewayv1.NamespacesFromSame)}}
+	case ns == host.NoNamespace:
+		return &gatewayv1.AllowedRoutes{Namespaces: &gatewayv1.RouteNamespaces{From: ptrTo(gatewayv1.NamespacesFromNone)}}
+	}
+	return &gatewayv1.AllowedRoutes{Namespaces: &gatewayv1.RouteNamespaces{
+		From:     ptrTo(gatewayv1.NamespacesFromSelector),
+		Selector: &metav1.LabelSelector{MatchLabels: map[string]string{namespaceNameLabel: ns}},
+	}}
+}
+
+// convertServerTLS returns the listener protocol and TLS configuration of s.
+func (c *converter) convertServerTLS(p *field.Path, s *networking.Server) (gatewayv1.ProtocolType, *gatewayv1.GatewayTLSConfig) {
+	protocol := gatewayv1.ProtocolType(strings.ToUpper(s.GetPort().GetProtocol()))
+	switch protocol {
+	case gatewayv1.HTTPProtocolType, gatewayv1.HTTPSProtocolType, gatewayv1.TLSProtocolType, gatewayv1.TCPProtocolType:
+	case "HTTP2", "GRPC", "GRPC-WEB":
+		protocol = gatewayv1.HTTPProtocolType
+	default:
+		c.lose(p.Child("port", "protocol"), "protocol %s is served as TCP", s.GetPort().GetProtocol())
+		protocol = gatewayv1.TCPProtocolType
+	}
+	t := s.GetTls()
+	if t == nil {
+		return protocol, nil
+	}
+	tp := p.Child("tls")
+	c.unsupported(tp, t, "mode", "credential_name", "credential_names", "https_redirect")
+	if t.HttpsRedirect {
+		c.lose(tp.Child("httpsRedirect"), "use a RequestRedirect filter in the routes of the listener")
+	}
+	if protocol != gatewayv1.HTTPSProtocolType && protocol != gatewayv1.TLSProtocolType {
+		// Plain text servers only honor httpsRedirect.
+		return protocol, nil
+	}
+	tls := &gatewayv1.GatewayTLSConfig{}
+	switch t.Mode {
+	case networking.ServerTLSSettings_PASSTHROUGH:
+		tls.Mode = ptrTo(gatewayv1.TLSModePassthrough)
+		return gatewayv1.TLSProtocolType, tls
+	case networking.ServerTLSSettings_SIMPLE:
+	default:
+		c.lose(tp.Child("mode"), "TLS mode %v is not supported by the Gateway API, the listener terminates TLS", t.Mode)
+	}
+	tls.Mode = ptrTo(gatewayv1.TLSModeTerminate)
+	credentials := t.CredentialNames
+	if t.CredentialName != "" {
+		credentials = append([]string{t.CredentialName}, credentials...)
+	}
+	for _, cred := range credentials {
+		tls.CertificateRefs = append(tls.CertificateRefs, gatewayv1.SecretObjectReference{
+			Group: ptrTo(gatewayv1.Group("")),
+			Kind:  ptrTo(gatewayv1.Kind("Secret")),
+			Name:  gatewayv1.ObjectName(cred),
+		})
+	}
+	return protocol, tls
+}
+
+// parentRefs returns the parents of the routes converted from vs: the Gateways it is bound to,
+// and the services of its hosts when it is bound to the mesh. It also reports whether vs is bound
+// to a gateway.
+func (c *converter) parentRefs(vs *VirtualService) ([]gatewayv1.ParentReference, bool) {
+	var refs []gatewayv1.ParentReference
+	gateways := vs.Spec.GetGateways()
+	if len(gateways) == 0 {
+		gateways = []string{"mesh"}
+	}
+	bound := false
+	for i, gw := range gateways {
+		if gw == "mesh" {
+			for j, h := range vs.Spec.GetHosts() {
+				name, ns, ok := c.serviceHost(h, vs.Namespace)
+				if !ok {
+					c.lose(field.NewPath("spec", "hosts").Index(j), "only Kubernetes service hosts can be routed for the mesh")
+					continue
+				}
+				ref := gatewayv1.ParentReference{Group: ptrTo(gatewayv1.Group("")), Kind: ptrTo(gatewayv1.Kind("Service")), Name: gatewayv1.ObjectName(name)}
+				if ns != vs.Namespace {
+					ref.Namespace = ptrTo(gatewayv1.Namespace(ns))
+				}
+				refs = append(refs, ref)
+			}
+			continue
+		}
+		ns, name, f := strings.Cut(gw, "/")
+		if !f {
+			ns, name = vs.Namespace, gw
+		}
+		if strings.Contains(name, ".") {
+			c.lose(field.NewPath("spec", "gateways").Index(i), "gateways must be referenced as namespace/name")
+			continue
+		}
+		ref := gatewayv1.ParentReference{Name: gatewayv1.ObjectName(name)}
+		if ns != vs.Namespace {
+			ref.Namespace = ptrTo(gatewayv1.Namespace(ns))
+		}
+		refs = append(refs, ref)
+		bound = true
+	}
+	return refs, bound
+}
+
+// hostnames returns the hostnames of the routes converted from vs when it is bound to a gateway.
+func hostnames(hosts []string) []gatewayv1.Hostname {
+	var out []gatewayv1.Hostname
+	for _, h := range hosts {
+		if h == "*" {
+			return nil
+		}
+		out = append(out, gatewayv1.Hostname(h))
+	}
+	return out
+}
+
+func (c *converter) convertVirtualService(vs *VirtualService, grpcGateways map[string]bool, out *GatewayAPIResources) {
+	c.setResource("VirtualService", vs.ObjectMeta)
+	spec := field.NewPath("spec")
+	c.unsupported(spec, vs.Spec, "hosts", "gateways", "http", "tls", "tcp")
+	parents, bound := c.parentRefs(vs)
+	var hosts []gatewayv1.Hostname
+	if bound {
+		hosts = hostnames(vs.Spec.GetHosts())
+	}
+	common := gatewayv1.CommonRouteSpec{ParentRefs: parents}
+
+	if len(vs.Spec.GetHttp()) > 0 {
+		grpc := bound
+		for _, gw := range vs.Spec.GetGateways() {
+			if !strings.Contains(gw, "/") {
+				gw = vs.Namespace + "/" + gw
+			}
+			grpc = grpc && grpcGateways[gw]
+		}
+		if grpc {
+			route := &gatewayv1.GRPCRoute{
+				TypeMeta:   metav1.TypeMeta{APIVersion: gatewayv1.GroupVersion.String(), Kind: "GRPCRoute"},
+				ObjectMeta: objectMeta(vs.ObjectMeta),
+				Spec:       gatewayv1.GRPCRouteSpec{CommonRouteSpec: common, Hostnames: hosts},
+			}
+			for i, r := range vs.Spec.Http {
+				route.Spec.Rules = append(route.Spec.Rules, c.convertGRPCRoute(spec.Child("http").Index(i), vs.Namespace, r))
+			}
+			out.GRPCRoutes = append(out.GRPCRoutes, route)
+		} else {
+			route := &gatewayv1.HTTPRoute{
+				TypeMeta:   metav1.TypeMeta{APIVersion: gatewayv1.GroupVersion.String(), Kind: "HTTPRoute"},
+				ObjectMeta: objectMeta(vs.ObjectMeta),
+				Spec:       gatewayv1.HTTPRouteSpec{CommonRouteSpec: common, Hostnames: hosts},
+			}
+			for i, r := range vs.Spec.Http {
+				route.Spec.Rules = append(route.Spec.Rules, c.convertHTTPRoute(spec.Child("http").Index(i), vs.Namespace, r))
+			}
+			out.HTTPRoutes = append(out.HTTPRoutes, route)
+		}
+	}
+
+	for i, r := range vs.Spec.GetTls() {
+		p := spec.Child("tls").Index(i)
+		route := &gatewayv1alpha2.TLSRoute{
+			TypeMeta:   metav1.TypeMeta{APIVersion: gatewayv1alpha2.GroupVersion.String(), Kind: "TLSRoute"},
+			ObjectMeta: objectMeta(vs.ObjectMeta),
+			Spec:       gatewayv1alpha2.TLSRouteSpec{CommonRouteSpec: common},
+		}
+		if len(vs.Spec.Tls) > 1 {
+			route.Name = fmt.Sprintf("%s-tls-%d", vs.Name, i)
+		}
+		var sni []string
+		for k, m := range r.GetMatch() {
+			c.unsupported(p.Child("match").Index(k), m, "sni_hosts")
+			sni = append(sni, m.GetSniHosts()...)
+		}
+		slices.Sort(sni)
+		for _, h := range slices.Compact(sni) {
+			route.Spec.Hostnames = append(route.Spec.Hostnames, gatewayv1.Hostname(h))
+		}
+		route.Spec.Rules = []gatewayv1alpha2.TLSRouteRule{{BackendRefs: c.convertRouteDestinations(p.Child("route"), vs.Namespace, r.GetRoute())}}
+		out.TLSRoutes = append(out.TLSRoutes, route)
+	}
+
+	for i, r := range vs.Spec.GetTcp() {
+		p := spec.Child("tcp").Index(i)
+		route := &gatewayv1alpha2.TCPRoute{
+			TypeMeta:   metav1.TypeMeta{APIVersion: gatewayv1alpha2.GroupVersion.String(), Kind: "TCPRoute"},
+			ObjectMeta: objectMeta(vs.ObjectMeta),
+			Spec:       gatewayv1alpha2.TCPRouteSpec{CommonRouteSpec: gatewayv1.CommonRouteSpec{ParentRefs: slices.Clone(parents)}},
+		}
+		if len(vs.Spec.Tcp) > 1 {
+			route.Name = fmt.Sprintf("%s-tcp-%d", vs.Name, i)
+		}
+		// A single port matched by all the clauses selects the port of the parents.
+		var port uint32
+		for k, m := range r.GetMatch() {
+			c.unsupported(p.Child("match").Index(k), m, "port")
+			if k == 0 {
+				port = m.GetPort()
+			} else if m.GetPort() != port {
+				c.lose(p.Child("match").Index(k).Child("port"), "all the matches of a TCP route must use the same port")
+			}
+		}
+		if port != 0 {
+			for j := range route.Spec.ParentRefs {
+				route.Spec.ParentRefs[j].Port = ptrTo(gatewayv1.PortNumber(port))
+			}
+		}
+		route.Spec.Rules = []gatewayv1alpha2.TCPRouteRule{{BackendRefs: c.convertRouteDestinations(p.Child("route"), vs.Namespace, r.GetRoute())}}
+		out.TCPRoutes = append(out.TCPRoutes, route)
+	}
+}
+
+func (c *converter) convertHTTPRoute(p *field.Path, namespace string, r *networking.HTTPRoute) gatewayv1.HTTPRouteRule {
+	c.unsupported(p, r, "name", "match", "route", "redirect", "rewrite", "timeout", "retries", "headers",
+		"mirror", "mirrors", "mirror_percentage", "mirror_percent")
+	rule := gatewayv1.HTTPRouteRule{Name: c.ruleName(p, r.Name)}
+	allPrefix := true
+	for k, m := range r.GetMatch() {
+		match, prefix := c.convertHTTPMatch(p.Child("match").Index(k), m)
+		rule.Matches = append(rule.Matches, match)
+		allPrefix = allPrefix && prefix
+	}
+	rule.Filters = append(rule.Filters, headerFilters(r.GetHeaders())...)
+	if r.Redirect != nil {
+		rule.Filters = append(rule.Filters, c.convertRedirect(p.Child("redirect"), r.Redirect))
+	}
+	if r.Rewrite != nil {
+		if f, ok := c.convertRewrite(p.Child("rewrite"), r.Rewrite, allPrefix); ok {
+			rule.Filters = append(rule.Filters, f)
+		}
+	}
+	for _, m := range c.convertMirrors(p, namespace, r) {
+		rule.Filters = append(rule.Filters, gatewayv1.HTTPRouteFilter{Type: gatewayv1.HTTPRouteFilterRequestMirror, RequestMirror: m})
+	}
+	for j, d := range r.GetRoute() {
+		dp := p.Child("route").Index(j)
+		c.unsupported(dp, d, "destination", "weight", "headers")
+		ref := gatewayv1.HTTPBackendRef{BackendRef: gatewayv1.BackendRef{
+			BackendObjectReference: c.convertDestination(dp.Child("destination"), namespace, d.Destination),
+			Weight:                 weight(d.Weight, len(r.Route)),
+		}}
+		for _, f := range headerFilters(d.Headers) {
+			ref.Filters = append(ref.Filters, f)
+		}
+		rule.BackendRefs = append(rule.BackendRefs, ref)
+	}
+	if r.Timeout != nil {
+		rule.Timeouts = &gatewayv1.HTTPRouteTimeouts{Request: ptrTo(duration(r.Timeout))}
+	}
+	if r.Retries != nil {
+		rule.Retry, rule.Timeouts = c.convertRetries(p.Child("retries"), r.Retries, rule.Timeouts)
+	}
+	return rule
+}
+
+// ruleName returns the section name of the rule converted from the route named name.
+func (c *converter) ruleName(p *field.Path, name string) *gatewayv1.SectionName {
+	if name == "" {
+		return nil
+	}
+	n := sectionName(name)
+	if n != name {
+		c.lose(p.Child("name"), "route names must be valid section names, %q is renamed to %q", name, n)
+	}
+	if n == "" {
+		return nil
+	}
+	return ptrTo(gatewayv1.SectionName(n))
+}
+
+// convertHTTPMatch converts m, and reports whether it leaves the path unmatched or matches it by
+// prefix.
+func (c *converter) convertHTTPMatch(p *field.Path, m *networking.HTTPMatchRequest) (gatewayv1.HTTPRouteMatch, bool) {
+	c.unsupported(p, m, "name", "uri", "method", "headers", "query_params", "ignore_uri_case")
+	out := gatewayv1.HTTPRouteMatch{}
+	if m.IgnoreUriCase {
+		c.lose(p.Child("ignoreUriCase"), "the Gateway API matches paths case sensitively")
+	}
+	prefix := true
+	switch u := m.GetUri().GetMatchType().(type) {
+	case *networking.StringMatch_Exact:
+		out.Path = &gatewayv1.HTTPPathMatch{Type: ptrTo(gatewayv1.PathMatchExact), Value: ptrTo(u.Exact)}
+		prefix = false
+	case *networking.StringMatch_Prefix:
+		out.Path = &gatewayv1.HTTPPathMatch{Type: ptrTo(gatewayv1.PathMatchPathPrefix), Value: ptrTo(u.Prefix)}
+		switch {
+		case u.Prefix == "/":
+		case strings.HasSuffix(u.Prefix, "/"):
+			c.lose(p.Child("uri", "prefix"), "the Gateway API ignores the trailing slash of path prefixes, so %q also matches %q",
+				u.Prefix, strings.TrimSuffix(u.Prefix, "/"))
+		default:
+			c.lose(p.Child("uri", "prefix"), "the Gateway API matches path prefixes by element, so %q no longer matches %q",
+				u.Prefix, u.Prefix+"x")
+		}
+	case *networking.StringMatch_Regex:
+		out.Path = &gatewayv1.HTTPPathMatch{Type: ptrTo(gatewayv1.PathMatchRegularExpression), Value: ptrTo(u.Regex)}
+		prefix = false
+	}
+	if m.Method != nil {
+		if e, ok := m.Method.MatchType.(*networking.StringMatch_Exact); ok {
+			out.Method = ptrTo(gatewayv1.HTTPMethod(e.Exact))
+		} else {
+			c.lose(p.Child("method"), "the Gateway API only matches exact methods")
+		}
+	}
+	for _, k := range slices.Sorted(maps.Keys(m.Headers)) {
+		exact, value := stringMatch(m.Headers[k])
+		t := gatewayv1.HeaderMatchRegularExpression
+		if exact {
+			t = gatewayv1.HeaderMatchExact
+		}
+		out.Headers = append(out.Headers, gatewayv1.HTTPHeaderMatch{Type: ptrTo(t), Name: gatewayv1.HTTPHeaderName(k), Value: value})
+	}
+	for _, k := range slices.Sorted(maps.Keys(m.QueryParams)) {
+		exact, value := stringMatch(m.QueryParams[k])
+		t := gatewayv1.QueryParamMatchRegularExpression
+		if exact {
+			t = gatewayv1.QueryParamMatchExact
+		}
+		out.QueryParams = append(out.QueryParams, gatewayv1.HTTPQueryParamMatch{Type: ptrTo(t), Name: gatewayv1.HTTPHeaderName(k), Value: value})
+	}
+	return out, prefix
+}
+
+// stringMatch returns the value of a header or query parameter match, as an exact value or as a
+// regular expression. Prefix and presence matches are expressed as regular expressions.
+func stringMatch(m *networking.StringMatch) (bool, string) {
+	switch t := m.GetMatchType().(type) {
+	case *networking.StringMatch_Exact:
+		return true, t.Exact
+	case *networking.StringMatch_Prefix:
+		return false, regexp.QuoteMeta(t.Prefix) + ".*"
+	case *networking.StringMatch_Regex:
+		return false, t.Regex
+	}
+	return false, ".*"
+}
+
+// headerFilters returns the filters applying the header operations h.
+func headerFilters(h *networking.Headers) []gatewayv1.HTTPRouteFilter {
+	var out []gatewayv1.HTTPRouteFilter
+	if f := headerFilter(h.GetRequest()); f != nil {
+		out = append(out, gatewayv1.HTTPRouteFilter{Type: gatewayv1.HTTPRouteFilterRequestHeaderModifier, RequestHeaderModifier: f})
+	}
+	if f := headerFilter(h.GetResponse()); f != nil {
+		out = append(out, gatewayv1.HTTPRouteFilter{Type: gatewayv1.HTTPRouteFilterResponseHeaderModifier, ResponseHeaderModifier: f})
+	}
+	return out
+}
+
+func headerFilter(ops *networking.Headers_HeaderOperations) *gatewayv1.HTTPHeaderFilter {
+	if len(ops.GetSet()) == 0 && len(ops.GetAdd()) == 0 && len(ops.GetRemove()) == 0 {
+		return nil
+	}
+	f := &gatewayv1.HTTPHeaderFilter{Remove: slices.Clone(ops.Remove)}
+	for _, k := range slices.Sorted(maps.Keys(ops.Set)) {
+		f.Set = append(f.Set, gatewayv1.HTTPHeader{Name: gatewayv1.HTTPHeaderName(k), Value: ops.Set[k]})
+	}
+	for _, k := range slices.Sorted(maps.Keys(ops.Add)) {
+		f.Add = append(f.Add, gatewayv1.HTTPHeader{Name: gatewayv1.HTTPHeaderName(k), Value: ops.Add[k]})
+	}
+	return f
+}
+
+func (c *converter) convertRedirect(p *field.Path, r *networking.HTTPRedirect) gatewayv1.HTTPRouteFilter {
+	f := &gatewayv1.HTTPRequestRedirectFilter{}
+	if r.Uri != "" {
+		f.Path = &gatewayv1.HTTPPathModifier{Type: gatewayv1.FullPathHTTPPathModifier, ReplaceFullPath: ptrTo(r.Uri)}
+	}
+	if r.Authority != "" {
+		f.Hostname = ptrTo(gatewayv1.PreciseHostname(r.Authority))
+	}
+	if r.Scheme != "" {
+		f.Scheme = ptrTo(r.Scheme)
+	}
+	switch port := r.RedirectPort.(type) {
+	case *networking.HTTPRedirect_Port:
+		f.Port = ptrTo(gatewayv1.PortNumber(port.Port))
+	case *networking.HTTPRedirect_DerivePort:
+		if port.DerivePort == networking.HTTPRedirect_FROM_REQUEST_PORT {
+			c.lose(p.Child("derivePort"), "the Gateway API cannot redirect to the request port")
+		}
+	}
+	if r.RedirectCode != 0 {
+		f.StatusCode = ptrTo(int(r.RedirectCode))
+	}
+	return gatewayv1.HTTPRouteFilter{Type: gatewayv1.HTTPRouteFilterRequestRedirect, RequestRedirect: f}
+}
+
+// convertRewrite converts r. A URI rewrite replaces the matched prefix when all the matches of
+// the route match a prefix, and the whole path otherwise, as in Istio.
+func (c *converter) convertRewrite(p *field.Path, r *networking.HTTPRewrite, prefix bool) (gatewayv1.HTTPRouteFilter, bool) {
+	c.unsupported(p, r, "uri", "authority")
+	f := &gatewayv1.HTTPURLRewriteFilter{}
+	if r.Authority != "" {
+		f.Hostname = ptrTo(gatewayv1.PreciseHostname(r.Authority))
+	}
+	if r.Uri != "" {
+		if prefix {
+			f.Path = &gatewayv1.HTTPPathModifier{Type: gatewayv1.PrefixMatchHTTPPathModifier, ReplacePrefixMatch: ptrTo(r.Uri)}
+		} else {
+			f.Path = &gatewayv1.HTTPPathModifier{Type: gatewayv1.FullPathHTTPPathModifier, ReplaceFullPath: ptrTo(r.Uri)}
+		}
+	}
+	if f.Hostname == nil && f.Path == nil {
+		return gatewayv1.HTTPRouteFilter{}, false
+	}
+	return gatewayv1.HTTPRouteFilter{Type: gatewayv1.HTTPRouteFilterURLRewrite, URLRewrite: f}, true
+}
+
+// convertMirrors returns the request mirrors of r, set either through mirrors or through the
+// deprecated mirror fields.
+func (c *converter) convertMirrors(p *field.Path, namespace string, r *networking.HTTPRoute) []*gatewayv1.HTTPRequestMirrorFilter {
+	var out []*gatewayv1.HTTPRequestMirrorFilter
+	if r.Mirror != nil {
+		m := &gatewayv1.HTTPRequestMirrorFilter{BackendRef: c.convertDestination(p.Child("mirror"), namespace, r.Mirror)}
+		switch {
+		case r.MirrorPercentage != nil:
+			m.Percent, m.Fraction = percent(r.MirrorPercentage.Value)
+		case r.MirrorPercent != nil: //nolint: staticcheck
+			m.Percent = ptrTo(int32(r.MirrorPercent.Value)) //nolint: staticcheck
+		}
+		out = append(out, m)
+	}
+	for i, mp := range r.GetMirrors() {
+		mpp := p.Child("mirrors").Index(i)
+		c.unsupported(mpp, mp, "destination", "percentage")
+		m := &gatewayv1.HTTPRequestMirrorFilter{BackendRef: c.convertDestination(mpp.Child("destination"), namespace, mp.Destination)}
+		if mp.Percentage != nil {
+			m.Percent, m.Fraction = percent(mp.Percentage.Value)
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+// percent returns v as a whole percentage when it is one, and as a fraction otherwise.
+func percent(v float64) (*int32, *gatewayv1.Fraction) {
+	if v == math.Trunc(v) {
+		return ptrTo(int32(v)), nil
+	}
+	return nil, &gatewayv1.Fraction{Numerator: int32(math.Round(v * 10000)), Denominator: ptrTo(int32(1000000))}
+}
+
+func (c *converter) convertRetries(p *field.Path, r *networking.HTTPRetry, timeouts *gatewayv1.HTTPRouteTimeouts) (*gatewayv1.HTTPRouteRetry, *gatewayv1.HTTPRouteTimeouts) {
+	c.unsupported(p, r, "attempts", "per_try_timeout", "retry_on", "backoff")
+	retry := &gatewayv1.HTTPRouteRetry{Attempts: ptrTo(int(r.Attempts))}
+	if r.Backoff != nil {
+		retry.Backoff = ptrTo(duration(r.Backoff))
+	}
+	if r.PerTryTimeout != nil {
+		if timeouts == nil {
+			timeouts = &gatewayv1.HTTPRouteTimeouts{}
+		}
+		timeouts.BackendRequest = ptrTo(duration(r.PerTryTimeout))
+	}
+	for _, cond := range strings.Split(r.RetryOn, ",") {
+		cond = strings.TrimSpace(cond)
+		if cond == "" {
+			continue
+		}
+		code, err := strconv.Atoi(cond)
+		if err != nil {
+			c.lose(p.Child("retryOn"), "the Gateway API only retries on status codes, not on %q", cond)
+			continue
+		}
+		retry.Codes = append(retry.Codes, gatewayv1.HTTPRouteRetryStatusCode(code))
+	}
+	return retry, timeouts
+}
+
+// duration formats d as a Gateway API duration, such as "1m30s" or "250ms".
+func duration(d *durationpb.Duration) gatewayv1.Duration {
+	v := d.AsDuration()
+	if v <= 0 {
+		return "0s"
+	}
+	var b strings.Builder
+	for _, u := range []struct {
+		unit time.Duration
+		name string
+	}{{time.Hour, "h"}, {time.Minute, "m"}, {time.Second, "s"}, {time.Millisecond, "ms"}} {
+		if n := v / u.unit; n > 0 {
+			fmt.Fprintf(&b, "%d%s", n, u.name)
+			v -= n * u.unit
+		}
+	}
+	if b.Len() == 0 {
+		return "1ms"
+	}
+	return gatewayv1.Duration(b.String())
+}
+
+// weight returns the Gateway API weight of a destination with the Istio weight w among n. A
+// single destination receives all the traffic, whatever its weight.
+func weight(w int32, n int) *int32 {
+	if n == 1 && w == 0 {
+		return nil
+	}
+	return ptrTo(w)
+}
+
+// convertDestination returns the backend of d: a Kubernetes service, or an Istio Hostname
+// backend for other hosts.
+func (c *converter) convertDestination(p *field.Path, namespace string, d *networking.Destination) gatewayv1.BackendObjectReference {
+	c.unsupported(p, d, "host", "port")
+	ref := gatewayv1.BackendObjectReference{}
+	if name, ns, ok := c.serviceHost(d.GetHost(), namespace); ok {
+		ref.Name = gatewayv1.ObjectName(name)
+		if ns != namespace {
+			ref.Namespace = ptrTo(gatewayv1.Namespace(ns))
+		}
+	} else {
+		ref.Group = ptrTo(gatewayv1.Group(istioGroup))
+		ref.Kind = ptrTo(gatewayv1.Kind(hostnameKind))
+		ref.Name = gatewayv1.ObjectName(d.GetHost())
+	}
+	if n := d.GetPort().GetNumber(); n != 0 {
+		ref.Port = ptrTo(gatewayv1.PortNumber(n))
+	} else {
+		c.lose(p.Child("port"), "the Gateway API requires a port for backends; the port of single port services is not inferred")
+	}
+	return ref
+}
+
+func (c *converter) convertRouteDestinations(p *field.Path, namespace string, routes []*networking.RouteDestination) []gatewayv1.BackendRef {
+	var out []gatewayv1.BackendRef
+	for j, d := range routes {
+		dp := p.Index(j)
+		c.unsupported(dp, d, "destination", "weight")
+		out = append(out, gatewayv1.BackendRef{
+			BackendObjectReference: c.convertDestination(dp.Child("destination"), namespace, d.Destination),
+			Weight:                 weight(d.Weight, len(routes)),
+		})
+	}
+	return out
+}
+
+func (c *converter) convertGRPCRoute(p *field.Path, namespace string, r *networking.HTTPRoute) gatewayv1.GRPCRouteRule {
+	c.unsupported(p, r, "name", "match", "route", "headers", "mirror", "mirrors", "mirror_percentage", "mirror_percent")
+	rule := gatewayv1.GRPCRouteRule{Name: c.ruleName(p, r.Name)}
+	for k, m := range r.GetMatch() {
+		rule.Matches = append(rule.Matches, c.convertGRPCMatch(p.Child("match").Index(k), m))
+	}
+	for _, f := range headerFilters(r.GetHeaders()) {
+		rule.Filters = append(rule.Filters, grpcFilter(f))
+	}
+	for _, m := range c.convertMirrors(p, namespace, r) {
+		rule.Filters = append(rule.Filters, gatewayv1.GRPCRouteFilter{Type: gatewayv1.GRPCRouteFilterRequestMirror, RequestMirror: m})
+	}
+	for j, d := range r.GetRoute() {
+		dp := p.Child("route").Index(j)
+		c.unsupported(dp, d, "destination", "weight", "headers")
+		ref := gatewayv1.GRPCBackendRef{BackendRef: gatewayv1.BackendRef{
+			BackendObjectReference: c.convertDestination(dp.Child("destination"), namespace, d.Destination),
+			Weight:                 weight(d.Weight, len(r.Route)),
+		}}
+		for _, f := range headerFilters(d.Headers) {
+			ref.Filters = append(ref.Filters, grpcFilter(f))
+		}
+		rule.BackendRefs = append(rule.BackendRefs, ref)
+	}
+	return rule
+}
+
+// grpcFilter converts a header modifier filter to its GRPCRoute equivalent.
+func grpcFilter(f gatewayv1.HTTPRouteFilter) gatewayv1.GRPCRouteFilter {
+	return gatewayv1.GRPCRouteFilter{
+		Type:                   gatewayv1.GRPCRouteFilterType(f.Type),
+		RequestHeaderModifier:  f.RequestHeaderModifier,
+		ResponseHeaderModifier: f.ResponseHeaderModifier,
+	}
+}
+
+// convertGRPCMatch converts m, whose URI must match a gRPC service or method, as /service/ or
+// /service/method.
+func (c *converter) convertGRPCMatch(p *field.Path, m *networking.HTTPMatchRequest) gatewayv1.GRPCRouteMatch {
+	c.unsupported(p, m, "name", "uri", "headers")
+	out := gatewayv1.GRPCRouteMatch{}
+	switch u := m.GetUri().GetMatchType().(type) {
+	case nil:
+	case *networking.StringMatch_Exact:
+		svc, method, ok := strings.Cut(strings.TrimPrefix(u.Exact, "/"), "/")
+		if ok && svc != "" && method != "" && !strings.Contains(method, "/") {
+			out.Method = &gatewayv1.GRPCMethodMatch{Type: ptrTo(gatewayv1.GRPCMethodMatchExact), Service: ptrTo(svc), Method: ptrTo(method)}
+		} else {
+			c.lose(p.Child("uri"), "%q is not a gRPC method path", u.Exact)
+		}
+	case *networking.StringMatch_Prefix:
+		svc, rest, ok := strings.Cut(strings.TrimPrefix(u.Prefix, "/"), "/")
+		if ok && svc != "" && rest == "" {
+			out.Method = &gatewayv1.GRPCMethodMatch{Type: ptrTo(gatewayv1.GRPCMethodMatchExact), Service: ptrTo(svc)}
+		} else {
+			c.lose(p.Child("uri"), "%q is not a gRPC service path", u.Prefix)
+		}
+	default:
+		c.lose(p.Child("uri"), "gRPC methods can only be matched by exact service and method names")
+	}
+	for _, k := range slices.Sorted(maps.Keys(m.Headers)) {
+		exact, value := stringMatch(m.Headers[k])
+		t := gatewayv1.GRPCHeaderMatchRegularExpression
+		if exact {
+			t = gatewayv1.GRPCHeaderMatchExact
+		}
+		out.Headers = append(out.Headers, gatewayv1.GRPCHeaderMatch{Type: ptrTo(t), Name: gatewayv1.GRPCHeaderName(k), Value: value})
+	}
+	return out
+}
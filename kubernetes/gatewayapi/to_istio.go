@@ -0,0 +1,734 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gatewayapi
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	networking "istio.io/api/networking/v1alpha3"
+)
+
+// ToIstio converts Gateway API resources to Istio Gateways and VirtualServices. It is the reverse
+// of ToGatewayAPI: each Gateway becomes a Gateway selecting the deployment Istio creates for it,
+// and the routes become VirtualServices, merging the routes that share a namespace and name.
+func ToIstio(in *GatewayAPIResources, opts Options) (*IstioResources, []LossyField) {
+	c := newConverter(opts)
+	out := &IstioResources{}
+	for _, gw := range in.Gateways {
+		out.Gateways = append(out.Gateways, c.convertGatewayAPIGateway(gw))
+	}
+	vss := &virtualServices{}
+	for _, r := range in.HTTPRoutes {
+		c.setResource("HTTPRoute", r.ObjectMeta)
+		vs := c.virtualService(vss, r.ObjectMeta, r.Spec.ParentRefs, r.Spec.Hostnames)
+		for i, rule := range r.Spec.Rules {
+			vs.Spec.Http = append(vs.Spec.Http, c.convertHTTPRouteRule(field.NewPath("spec", "rules").Index(i), r.Namespace, rule))
+		}
+	}
+	for _, r := range in.GRPCRoutes {
+		c.setResource("GRPCRoute", r.ObjectMeta)
+		vs := c.virtualService(vss, r.ObjectMeta, r.Spec.ParentRefs, r.Spec.Hostnames)
+		for i, rule := range r.Spec.Rules {
+			vs.Spec.Http = append(vs.Spec.Http, c.convertGRPCRouteRule(field.NewPath("spec", "rules").Index(i), r.Namespace, rule))
+		}
+	}
+	for _, r := range in.TLSRoutes {
+		c.setResource("TLSRoute", r.ObjectMeta)
+		vs := c.virtualService(vss, r.ObjectMeta, r.Spec.ParentRefs, r.Spec.Hostnames)
+		var sni []string
+		for _, h := range r.Spec.Hostnames {
+			sni = append(sni, string(h))
+		}
+		if len(sni) == 0 {
+			sni = []string{"*"}
+		}
+		for i, rule := range r.Spec.Rules {
+			p := field.NewPath("spec", "rules").Index(i)
+			c.checkRuleName(p, rule.Name)
+			vs.Spec.Tls = append(vs.Spec.Tls, &networking.TLSRoute{
+				Match: []*networking.TLSMatchAttributes{{SniHosts: sni}},
+				Route: c.convertBackendRefs(p.Child("backendRefs"), r.Namespace, rule.BackendRefs),
+			})
+		}
+	}
+	for _, r := range in.TCPRoutes {
+		c.setResource("TCPRoute", r.ObjectMeta)
+		vs := c.virtualService(vss, r.ObjectMeta, r.Spec.ParentRefs, nil)
+		var match []*networking.L4MatchAttributes
+		for i, ref := range r.Spec.ParentRefs {
+			if ref.Port == nil {
+				continue
+			}
+			if match == nil {
+				match = []*networking.L4MatchAttributes{{Port: uint32(*ref.Port)}}
+			} else if uint32(*ref.Port) != match[0].Port {
+				c.lose(field.NewPath("spec", "parentRefs").Index(i).Child("port"), "all the parents of a TCP route must use the same port")
+			}
+		}
+		for i, rule := range r.Spec.Rules {
+			p := field.NewPath("spec", "rules").Index(i)
+			c.checkRuleName(p, rule.Name)
+			vs.Spec.Tcp = append(vs.Spec.Tcp, &networking.TCPRoute{
+				Match: match,
+				Route: c.convertBackendRefs(p.Child("backendRefs"), r.Namespace, rule.BackendRefs),
+			})
+		}
+	}
+	out.VirtualServices = vss.list
+	return out, c.lossy
+}
+
+func (c *converter) convertGatewayAPIGateway(gw *gatewayv1.Gateway) *Gateway {
+	c.setResource("Gateway", gw.ObjectMeta)
+	spec := field.NewPath("spec")
+	out := &Gateway{
+		ObjectMeta: objectMeta(gw.ObjectMeta),
+		Spec:       &networking.Gateway{Selector: map[string]string{gatewayNameLabel: gw.Name}},
+	}
+	if len(gw.Spec.Addresses) > 0 {
+		c.lose(spec.Child("addresses"), "not supported by Istio Gateways")
+	}
+	if gw.Spec.Infrastructure != nil {
+		c.lose(spec.Child("infrastructure"), "not supported by Istio Gateways")
+	}
+	if gw.Spec.BackendTLS != nil {
+		c.lose(spec.Child("backendTLS"), "not supported by Istio Gateways")
+	}
+	if gw.Spec.AllowedListeners != nil {
+		c.lose(spec.Child("allowedListeners"), "not supported by Istio Gateways")
+	}
+	listeners := gw.Spec.Listeners
+	for i := 0; i < len(listeners); {
+		// ToGatewayAPI turns each host of a server into a listener named after the server with the
+		// index of the host as suffix: gather them back into a server.
+		l := listeners[i]
+		n := 1
+		if base, f := strings.CutSuffix(string(l.Name), "-0"); f {
+			for i+n < len(listeners) && sameServer(l, listeners[i+n], fmt.Sprintf("%s-%d", base, n)) {
+				n++
+			}
+			if n > 1 {
+				l.Name = gatewayv1.SectionName(base)
+			}
+		}
+		s := &networking.Server{
+			Port: &networking.Port{Number: uint32(l.Port), Protocol: string(l.Protocol), Name: string(l.Name)},
+			Tls:  c.convertListenerTLS(spec.Child("listeners").Index(i).Child("tls"), l.TLS),
+		}
+		switch l.Protocol {
+		case gatewayv1.HTTPProtocolType, gatewayv1.HTTPSProtocolType, gatewayv1.TLSProtocolType, gatewayv1.TCPProtocolType:
+		default:
+			c.lose(spec.Child("listeners").Index(i).Child("protocol"), "protocol %s is not supported by Istio Gateways", l.Protocol)
+		}
+		for j := i; j < i+n; j++ {
+			s.Hosts = append(s.Hosts, c.serverHost(spec.Child("listeners").Index(j), listeners[j], gw.Namespace))
+		}
+		out.Spec.Servers = append(out.Spec.Servers, s)
+		i += n
+	}
+	return out
+}
+
+// sameServer reports whether l is named name and only differs from first by its host.
+func sameServer(first, l gatewayv1.Listener, name string) bool {
+	return string(l.Name) == name && l.Port == first.Port && l.Protocol == first.Protocol &&
+		equality.Semantic.DeepEqual(l.TLS, first.TLS)
+}
+
+// serverHost returns the server host matching the hostname of l, with the namespaces the routes
+// of l are allowed from.
+func (c *converter) serverHost(p *field.Path, l gatewayv1.Listener, namespace string) string {
+	host := "*"
+	if l.Hostname != nil {
+		host = string(*l.Hostname)
+	}
+	ar := l.AllowedRoutes
+	if ar != nil && len(ar.Kinds) > 0 {
+		c.lose(p.Child("allowedRoutes", "kinds"), "Istio Gateways cannot restrict the kinds of routes")
+	}
+	if ar == nil || ar.Namespaces == nil || ar.Namespaces.From == nil {
+		return "./" + host
+	}
+	switch *ar.Namespaces.From {
+	case gatewayv1.NamespacesFromAll:
+		return host
+	case gatewayv1.NamespacesFromSame:
+		return "./" + host
+	case gatewayv1.NamespacesFromSelector:
+		if s := ar.Namespaces.Selector; s != nil && len(s.MatchExpressions) == 0 && len(s.MatchLabels) == 1 && s.MatchLabels[namespaceNameLabel] != "" {
+			return s.MatchLabels[namespaceNameLabel] + "/" + host
+		}
+	}
+	c.lose(p.Child("allowedRoutes", "namespaces"), "Istio Gateways can only allow routes from all namespaces or from a single one, routes are only allowed from %s", namespace)
+	return "./" + host
+}
+
+func (c *converter) convertListenerTLS(p *field.Path, t *gatewayv1.GatewayTLSConfig) *networking.ServerTLSSettings {
+	if t == nil {
+		return nil
+	}
+	if t.FrontendValidation != nil {
+		c.lose(p.Child("frontendValidation"), "not supported by Istio Gateways")
+	}
+	if len(t.Options) > 0 {
+		c.lose(p.Child("options"), "not supported by Istio Gateways")
+	}
+	if t.Mode != nil && *t.Mode == gatewayv1.TLSModePassthrough {
+		return &networking.ServerTLSSettings{Mode: networking.ServerTLSSettings_PASSTHROUGH}
+	}
+	out := &networking.ServerTLSSettings{Mode: networking.ServerTLSSettings_SIMPLE}
+	var credentials []string
+	for i, ref := range t.CertificateRefs {
+		if (ref.Group != nil && *ref.Group != "") || (ref.Kind != nil && *ref.Kind != "Secret") {
+			c.lose(p.Child("certificateRefs").Index(i), "Istio Gateways only use Secrets as certificates")
+			continue
+		}
+		name := string(ref.Name)
+		if ref.Namespace != nil {
+			name = string(*ref.Namespace) + "/" + name
+		}
+		credentials = append(credentials, name)
+	}
+	if len(credentials) == 1 {
+		out.CredentialName = credentials[0]
+	} else {
+		out.CredentialNames = credentials
+	}
+	return out
+}
+
+// virtualServices is the list of VirtualServices converted from routes, indexed by namespace
+// and name.
+type virtualServices struct {
+	list   []*VirtualService
+	byName map[string]*VirtualService
+}
+
+// virtualService returns the VirtualService a route with metadata meta is converted to, bound to
+// the gateways and hosts of its parents.
+func (c *converter) virtualService(vss *virtualServices, meta metav1.ObjectMeta, parents []gatewayv1.ParentReference,
+	hostnames []gatewayv1.Hostname,
+) *VirtualService {
+	key := meta.Namespace + "/" + meta.Name
+	vs := vss.byName[key]
+	if vs == nil {
+		vs = &VirtualService{ObjectMeta: objectMeta(meta), Spec: &networking.VirtualService{}}
+		if vss.byName == nil {
+			vss.byName = map[string]*VirtualService{}
+		}
+		vss.byName[key] = vs
+		vss.list = append(vss.list, vs)
+	}
+	addUnique := func(l []string, v string) []string {
+		if slices.Contains(l, v) {
+			return l
+		}
+		return append(l, v)
+	}
+	var gateways []string
+	mesh := false
+	for i, ref := range parents {
+		p := field.NewPath("spec", "parentRefs").Index(i)
+		if ref.SectionName != nil {
+			c.lose(p.Child("sectionName"), "Istio VirtualServices bind to all the servers of a Gateway")
+		}
+		group, kind := gatewayv1.GroupName, "Gateway"
+		if ref.Group != nil {
+			group = string(*ref.Group)
+		}
+		if ref.Kind != nil {
+			kind = string(*ref.Kind)
+		}
+		ns := meta.Namespace
+		if ref.Namespace != nil {
+			ns = string(*ref.Namespace)
+		}
+		switch {
+		case group == "" && kind == "Service":
+			vs.Spec.Hosts = addUnique(vs.Spec.Hosts, c.hostForService(string(ref.Name), ns, meta.Namespace))
+			mesh = true
+		case group == gatewayv1.GroupName && kind == "Gateway":
+			name := string(ref.Name)
+			if ns != meta.Namespace {
+				name = ns + "/" + name
+			}
+			gateways = addUnique(gateways, name)
+			if len(hostnames) == 0 {
+				vs.Spec.Hosts = addUnique(vs.Spec.Hosts, "*")
+			}
+			for _, h := range hostnames {
+				vs.Spec.Hosts = addUnique(vs.Spec.Hosts, string(h))
+			}
+		default:
+			c.lose(p, "parents of kind %s/%s are not supported by Istio", group, kind)
+		}
+	}
+	if mesh && len(gateways) > 0 {
+		gateways = append(gateways, "mesh")
+	}
+	for _, gw := range gateways {
+		vs.Spec.Gateways = addUnique(vs.Spec.Gateways, gw)
+	}
+	return vs
+}
+
+// checkRuleName reports the names of rules, which Istio TLS and TCP routes do not have.
+func (c *converter) checkRuleName(p *field.Path, name *gatewayv1.SectionName) {
+	if name != nil {
+		c.lose(p.Child("name"), "Istio TLS and TCP routes have no name")
+	}
+}
+
+func (c *converter) convertHTTPRouteRule(p *field.Path, namespace string, rule gatewayv1.HTTPRouteRule) *networking.HTTPRoute {
+	r := &networking.HTTPRoute{}
+	if rule.Name != nil {
+		r.Name = string(*rule.Name)
+	}
+	allPrefix := true
+	for i, m := range rule.Matches {
+		match, prefix := c.convertHTTPRouteMatch(p.Child("matches").Index(i), m)
+		r.Match = append(r.Match, match)
+		allPrefix = allPrefix && prefix
+	}
+	for i, f := range rule.Filters {
+		fp := p.Child("filters").Index(i)
+		switch f.Type {
+		case gatewayv1.HTTPRouteFilterRequestHeaderModifier, gatewayv1.HTTPRouteFilterResponseHeaderModifier:
+			r.Headers = addHeaderFilter(r.Headers, f.Type, f.RequestHeaderModifier, f.ResponseHeaderModifier)
+		case gatewayv1.HTTPRouteFilterRequestRedirect:
+			r.Redirect = c.convertRequestRedirect(fp.Child("requestRedirect"), f.RequestRedirect)
+		case gatewayv1.HTTPRouteFilterURLRewrite:
+			r.Rewrite = convertURLRewrite(f.URLRewrite, allPrefix)
+		case gatewayv1.HTTPRouteFilterRequestMirror:
+			r.Mirrors = append(r.Mirrors, c.convertRequestMirror(fp.Child("requestMirror"), namespace, f.RequestMirror))
+		default:
+			c.lose(fp, "filters of type %s are not supported by Istio", f.Type)
+		}
+	}
+	for i, ref := range rule.BackendRefs {
+		rp := p.Child("backendRefs").Index(i)
+		d := &networking.HTTPRouteDestination{Destination: c.convertBackendObjectReference(rp, namespace, ref.BackendObjectReference)}
+		for j, f := range ref.Filters {
+			switch f.Type {
+			case gatewayv1.HTTPRouteFilterRequestHeaderModifier, gatewayv1.HTTPRouteFilterResponseHeaderModifier:
+				d.Headers = addHeaderFilter(d.Headers, f.Type, f.RequestHeaderModifier, f.ResponseHeaderModifier)
+			default:
+				c.lose(rp.Child("filters").Index(j), "Istio destinations only support header modifier filters")
+			}
+		}
+		r.Route = append(r.Route, d)
+	}
+	refs := make([]gatewayv1.BackendRef, 0, len(rule.BackendRefs))
+	for _, ref := range rule.BackendRefs {
+		refs = append(refs, ref.BackendRef)
+	}
+	for i, w := range weights(refs) {
+		r.Route[i].Weight = w
+	}
+	if t := rule.Timeouts; t != nil {
+		if t.Request != nil {
+			r.Timeout = c.convertDuration(p.Child("timeouts", "request"), *t.Request)
+		}
+		if t.BackendRequest != nil && rule.Retry == nil {
+			c.lose(p.Child("timeouts", "backendRequest"), "Istio only limits the duration of backend requests when retrying")
+		}
+	}
+	if rule.Retry != nil {
+		r.Retries = c.convertRetry(p.Child("retry"), rule.Retry)
+		if rule.Timeouts != nil && rule.Timeouts.BackendRequest != nil {
+			r.Retries.PerTryTimeout = c.convertDuration(p.Child("timeouts", "backendRequest"), *rule.Timeouts.BackendRequest)
+		}
+	}
+	if rule.SessionPersistence != nil {
+		c.lose(p.Child("sessionPersistence"), "use a DestinationRule consistent hash load balancer")
+	}
+	return r
+}
+
+// convertHTTPRouteMatch converts m, and reports whether it leaves the path unmatched or matches
+// it by prefix.
+func (c *converter) convertHTTPRouteMatch(p *field.Path, m gatewayv1.HTTPRouteMatch) (*networking.HTTPMatchRequest, bool) {
+	out := &networking.HTTPMatchRequest{}
+	prefix := true
+	if m.Path != nil && m.Path.Value != nil {
+		t := gatewayv1.PathMatchPathPrefix
+		if m.Path.Type != nil {
+			t = *m.Path.Type
+		}
+		out.Uri = gatewayStringMatch(string(t), *m.Path.Value)
+		prefix = t == gatewayv1.PathMatchPathPrefix
+		// As istiod does, drop the trailing slash, which the Gateway API ignores: "/foo/" matches "/foo".
+		if v := strings.TrimSuffix(*m.Path.Value, "/"); prefix && v != "" {
+			out.Uri = gatewayStringMatch(string(t), v)
+			c.lose(p.Child("path"), "Istio matches path prefixes as strings, so %q also matches %q", v, v+"x")
+		}
+	}
+	if m.Method != nil {
+		out.Method = &networking.StringMatch{MatchType: &networking.StringMatch_Exact{Exact: string(*m.Method)}}
+	}
+	for _, h := range m.Headers {
+		if out.Headers == nil {
+			out.Headers = map[string]*networking.StringMatch{}
+		}
+		t := gatewayv1.HeaderMatchExact
+		if h.Type != nil {
+			t = *h.Type
+		}
+		out.Headers[strings.ToLower(string(h.Name))] = gatewayStringMatch(string(t), h.Value)
+	}
+	for _, q := range m.QueryParams {
+		if out.QueryParams == nil {
+			out.QueryParams = map[string]*networking.StringMatch{}
+		}
+		t := gatewayv1.QueryParamMatchExact
+		if q.Type != nil {
+			t = *q.Type
+		}
+		out.QueryParams[string(q.Name)] = gatewayStringMatch(string(t), q.Value)
+	}
+	return out, prefix
+}
+
+// gatewayStringMatch returns the Istio match of a Gateway API match of type t. All the Gateway API
+// match types share the names Exact, PathPrefix and RegularExpression.
+func gatewayStringMatch(t, value string) *networking.StringMatch {
+	switch t {
+	case string(gatewayv1.PathMatchPathPrefix):
+		return &networking.StringMatch{MatchType: &networking.StringMatch_Prefix{Prefix: value}}
+	case string(gatewayv1.PathMatchRegularExpression):
+		return &networking.StringMatch{MatchType: &networking.StringMatch_Regex{Regex: value}}
+	}
+	return &networking.StringMatch{MatchType: &networking.StringMatch_Exact{Exact: value}}
+}
+
+// addHeaderFilter adds the operations of a header modifier filter to h.
+func addHeaderFilter[T ~string](h *networking.Headers, t T, request, response *gatewayv1.HTTPHeaderFilter) *networking.Headers {
+	if h == nil {
+		h = &networking.Headers{}
+	}
+	f, ops := request, &h.Request
+	if string(t) == string(gatewayv1.HTTPRouteFilterResponseHeaderModifier) {
+		f, ops = response, &h.Response
+	}
+	if f == nil {
+		return h
+	}
+	if *ops == nil {
+		*ops = &networking.Headers_HeaderOperations{}
+	}
+	for _, s := range f.Set {
+		if (*ops).Set == nil {
+			(*ops).Set = map[string]string{}
+		}
+		(*ops).Set[string(s.Name)] = s.Value
+	}
+	for _, a := range f.Add {
+		if (*ops).Add == nil {
+			(*ops).Add = map[string]string{}
+		}
+		(*ops).Add[string(a.Name)] = a.Value
+	}
+	(*ops).Remove = append((*ops).Remove, f.Remove...)
+	return h
+}
+
+func (c *converter) convertRequestRedirect(p *field.Path, f *gatewayv1.HTTPRequestRedirectFilter) *networking.HTTPRedirect {
+	out := &networking.HTTPRedirect{}
+	if f == nil {
+		return out
+	}
+	if f.Scheme != nil {
+		out.Scheme = *f.Scheme
+	}
+	if f.Hostname != nil {
+		out.Authority = string(*f.Hostname)
+	}
+	if f.Port != nil {
+		out.RedirectPort = &networking.HTTPRedirect_Port{Port: uint32(*f.Port)}
+	}
+	if f.StatusCode != nil {
+		out.RedirectCode = uint32(*f.StatusCode)
+	}
+	if f.Path != nil {
+		switch {
+		case f.Path.Type == gatewayv1.FullPathHTTPPathModifier && f.Path.ReplaceFullPath != nil:
+			out.Uri = *f.Path.ReplaceFullPath
+		case f.Path.Type == gatewayv1.PrefixMatchHTTPPathModifier:
+			c.lose(p.Child("path"), "Istio redirects replace the whole path")
+		}
+	}
+	return out
+}
+
+// convertURLRewrite converts f. Istio URI rewrites replace the matched prefix, or the whole path
+// when it is matched exactly, so a full path replacement of a prefix match turns into a regular
+// expression rewrite.
+func convertURLRewrite(f *gatewayv1.HTTPURLRewriteFilter, prefix bool) *networking.HTTPRewrite {
+	out := &networking.HTTPRewrite{}
+	if f == nil {
+		return out
+	}
+	if f.Hostname != nil {
+		out.Authority = string(*f.Hostname)
+	}
+	if f.Path != nil {
+		switch f.Path.Type {
+		case gatewayv1.PrefixMatchHTTPPathModifier:
+			if f.Path.ReplacePrefixMatch != nil {
+				out.Uri = *f.Path.ReplacePrefixMatch
+			}
+		case gatewayv1.FullPathHTTPPathModifier:
+			if f.Path.ReplaceFullPath == nil {
+				break
+			}
+			if prefix {
+				out.UriRegexRewrite = &networking.RegexRewrite{Match: "^.*$", Rewrite: *f.Path.ReplaceFullPath}
+			} else {
+				out.Uri = *f.Path.ReplaceFullPath
+			}
+		}
+	}
+	return out
+}
+
+func (c *converter) convertRequestMirror(p *field.Path, namespace string, f *gatewayv1.HTTPRequestMirrorFilter) *networking.HTTPMirrorPolicy {
+	if f == nil {
+		return &networking.HTTPMirrorPolicy{}
+	}
+	out := &networking.HTTPMirrorPolicy{Destination: c.convertBackendObjectReference(p.Child("backendRef"), namespace, f.BackendRef)}
+	switch {
+	case f.Percent != nil:
+		out.Percentage = &networking.Percent{Value: float64(*f.Percent)}
+	case f.Fraction != nil:
+		d := int32(100)
+		if f.Fraction.Denominator != nil {
+			d = *f.Fraction.Denominator
+		}
+		if d > 0 {
+			out.Percentage = &networking.Percent{Value: float64(f.Fraction.Numerator) * 100 / float64(d)}
+		}
+	}
+	return out
+}
+
+func (c *converter) convertRetry(p *field.Path, r *gatewayv1.HTTPRouteRetry) *networking.HTTPRetry {
+	out := &networking.HTTPRetry{}
+	if r.Attempts != nil {
+		out.Attempts = int32(*r.Attempts)
+	}
+	if r.Backoff != nil {
+		out.Backoff = c.convertDuration(p.Child("backoff"), *r.Backoff)
+	}
+	codes := make([]string, 0, len(r.Codes))
+	for _, code := range r.Codes {
+		codes = append(codes, strconv.Itoa(int(code)))
+	}
+	out.RetryOn = strings.Join(codes, ",")
+	return out
+}
+
+func (c *converter) convertDuration(p *field.Path, d gatewayv1.Duration) *durationpb.Duration {
+	v, err := time.ParseDuration(string(d))
+	if err != nil {
+		c.lose(p, "invalid duration %q", d)
+		return nil
+	}
+	return durationpb.New(v)
+}
+
+// convertBackendObjectReference returns the destination of ref, which is a Kubernetes service or
+// an Istio Hostname backend.
+func (c *converter) convertBackendObjectReference(p *field.Path, namespace string, ref gatewayv1.BackendObjectReference) *networking.Destination {
+	out := &networking.Destination{}
+	group, kind := "", "Service"
+	if ref.Group != nil {
+		group = string(*ref.Group)
+	}
+	if ref.Kind != nil {
+		kind = string(*ref.Kind)
+	}
+	switch {
+	case group == "" && kind == "Service":
+		ns := namespace
+		if ref.Namespace != nil {
+			ns = string(*ref.Namespace)
+		}
+		out.Host = c.hostForService(string(ref.Name), ns, namespace)
+	case group == istioGroup && kind == hostnameKind:
+		out.Host = string(ref.Name)
+	default:
+		c.lose(p, "backends of kind %s/%s are not supported by Istio", group, kind)
+		out.Host = string(ref.Name)
+	}
+	if ref.Port != nil {
+		out.Port = &networking.PortSelector{Number: uint32(*ref.Port)}
+	}
+	return out
+}
+
+func (c *converter) convertBackendRefs(p *field.Path, namespace string, refs []gatewayv1.BackendRef) []*networking.RouteDestination {
+	var out []*networking.RouteDestination
+	w := weights(refs)
+	for i, ref := range refs {
+		out = append(out, &networking.RouteDestination{
+			Destination: c.convertBackendObjectReference(p.Index(i), namespace, ref.BackendObjectReference),
+			Weight:      w[i],
+		})
+	}
+	return out
+}
+
+// weights returns the Istio weights of refs. Gateway API weights are relative, and default to 1,
+// while Istio weights are percentages: they are scaled to add up to 100, distributing the
+// rounding remainder to the largest weights first.
+func weights(refs []gatewayv1.BackendRef) []int32 {
+	out := make([]int32, len(refs))
+	if len(refs) == 1 {
+		if refs[0].Weight != nil {
+			out[0] = *refs[0].Weight
+		}
+		return out
+	}
+	var total int32
+	for i, ref := range refs {
+		out[i] = 1
+		if ref.Weight != nil {
+			out[i] = *ref.Weight
+		}
+		total += out[i]
+	}
+	if total == 0 || total == 100 {
+		return out
+	}
+	// Scale the weights down, and hand out the missing points in the order of the largest
+	// remainders.
+	order := make([]int, len(out))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(out[b]*100%total, out[a]*100%total)
+	})
+	var sum int32
+	for i, w := range out {
+		out[i] = w * 100 / total
+		sum += out[i]
+	}
+	for _, i := range order[:100-sum] {
+		out[i]++
+	}
+	return out
+}
+
+func (c *converter) convertGRPCRouteRule(p *field.Path, namespace string, rule gatewayv1.GRPCRouteRule) *networking.HTTPRoute {
+	r := &networking.HTTPRoute{}
+	if rule.Name != nil {
+		r.Name = string(*rule.Name)
+	}
+	for _, m := range rule.Matches {
+		r.Match = append(r.Match, convertGRPCRouteMatch(m))
+	}
+	for i, f := range rule.Filters {
+		fp := p.Child("filters").Index(i)
+		switch f.Type {
+		case gatewayv1.GRPCRouteFilterRequestHeaderModifier, gatewayv1.GRPCRouteFilterResponseHeaderModifier:
+			r.Headers = addHeaderFilter(r.Headers, f.Type, f.RequestHeaderModifier, f.ResponseHeaderModifier)
+		case gatewayv1.GRPCRouteFilterRequestMirror:
+			r.Mirrors = append(r.Mirrors, c.convertRequestMirror(fp.Child("requestMirror"), namespace, f.RequestMirror))
+		default:
+			c.lose(fp, "filters of type %s are not supported by Istio", f.Type)
+		}
+	}
+	refs := make([]gatewayv1.BackendRef, 0, len(rule.BackendRefs))
+	for _, ref := range rule.BackendRefs {
+		refs = append(refs, ref.BackendRef)
+	}
+	w := weights(refs)
+	for i, ref := range rule.BackendRefs {
+		rp := p.Child("backendRefs").Index(i)
+		d := &networking.HTTPRouteDestination{
+			Destination: c.convertBackendObjectReference(rp, namespace, ref.BackendObjectReference),
+			Weight:      w[i],
+		}
+		for j, f := range ref.Filters {
+			switch f.Type {
+			case gatewayv1.GRPCRouteFilterRequestHeaderModifier, gatewayv1.GRPCRouteFilterResponseHeaderModifier:
+				d.Headers = addHeaderFilter(d.Headers, f.Type, f.RequestHeaderModifier, f.ResponseHeaderModifier)
+			default:
+				c.lose(rp.Child("filters").Index(j), "Istio destinations only support header modifier filters")
+			}
+		}
+		r.Route = append(r.Route, d)
+	}
+	if rule.SessionPersistence != nil {
+		c.lose(p.Child("sessionPersistence"), "use a DestinationRule consistent hash load balancer")
+	}
+	return r
+}
+
+// convertGRPCRouteMatch converts m, matching gRPC methods by the path of their requests.
+func convertGRPCRouteMatch(m gatewayv1.GRPCRouteMatch) *networking.HTTPMatchRequest {
+	out := &networking.HTTPMatchRequest{}
+	if mm := m.Method; mm != nil && (mm.Service != nil || mm.Method != nil) {
+		svc, method := "", ""
+		if mm.Service != nil {
+			svc = *mm.Service
+		}
+		if mm.Method != nil {
+			method = *mm.Method
+		}
+		regex := mm.Type != nil && *mm.Type == gatewayv1.GRPCMethodMatchRegularExpression
+		switch {
+		case !regex && method == "":
+			out.Uri = &networking.StringMatch{MatchType: &networking.StringMatch_Prefix{Prefix: "/" + svc + "/"}}
+		case !regex && svc != "":
+			out.Uri = &networking.StringMatch{MatchType: &networking.StringMatch_Exact{Exact: "/" + svc + "/" + method}}
+		default:
+			if !regex {
+				method = regexp.QuoteMeta(method)
+			}
+			if svc == "" {
+				svc = "[^/]+"
+			}
+			if method == "" {
+				method = "[^/]+"
+			}
+			out.Uri = &networking.StringMatch{MatchType: &networking.StringMatch_Regex{Regex: "/" + svc + "/" + method}}
+		}
+	}
+	for _, h := range m.Headers {
+		if out.Headers == nil {
+			out.Headers = map[string]*networking.StringMatch{}
+		}
+		t := gatewayv1.GRPCHeaderMatchExact
+		if h.Type != nil {
+			t = *h.Type
+		}
+		out.Headers[strings.ToLower(string(h.Name))] = gatewayStringMatch(string(t), h.Value)
+	}
+	return out
+}
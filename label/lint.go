@@ -0,0 +1,86 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package label
+
+import (
+	"fmt"
+
+	"istio.io/api/internal/registry"
+)
+
+// FindingType classifies a Finding.
+type FindingType string
+
+const (
+	// UnknownLabel is a label in an Istio domain that Istio does not define.
+	UnknownLabel FindingType = "UnknownLabel"
+	// MisplacedLabel is a label set on a kind of resource it does not apply to.
+	MisplacedLabel FindingType = "MisplacedLabel"
+	// DeprecatedLabel is a label that is deprecated.
+	DeprecatedLabel FindingType = "DeprecatedLabel"
+	// InvalidLabelValue is a label whose value does not match its value type.
+	InvalidLabelValue FindingType = "InvalidLabelValue"
+)
+
+var findingTypes = []FindingType{
+	registry.Unknown:      UnknownLabel,
+	registry.Misplaced:    MisplacedLabel,
+	registry.Deprecated:   DeprecatedLabel,
+	registry.InvalidValue: InvalidLabelValue,
+}
+
+// Finding is a problem with a label of an object.
+type Finding struct {
+	Type FindingType
+	// Key is the label name, as set on the object.
+	Key string
+	// Suggestion is the known label Key is likely a misspelling of or, for deprecated
+	// labels, the replacement to use, if any.
+	Suggestion string
+	Message    string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s", f.Type, f.Message)
+}
+
+var linter = registry.Linter{
+	Noun: "label",
+	Names: func() []string {
+		var out []string
+		for _, a := range AllResourceLabels() {
+			out = append(out, a.Name)
+		}
+		return out
+	},
+	Lookup: func(name string) (registry.Entry, bool) {
+		a, f := Lookup(name)
+		if !f {
+			return registry.Entry{}, false
+		}
+		return registry.Entry{Resources: a.kinds(), Deprecated: a.Deprecated, Metadata: metadataFor(a.Name)}, true
+	},
+}
+
+// Lint checks the labels of an object of the given kind, such as "Pod", against the known
+// Istio labels. Labels outside of the istio.io domains are ignored. Findings are sorted
+// by key.
+func Lint(kind string, labels map[string]string) []Finding {
+	var findings []Finding
+	for _, f := range linter.Lint(kind, labels) {
+		findings = append(findings, Finding{Type: findingTypes[f.Kind], Key: f.Key, Suggestion: f.Suggestion, Message: f.Message})
+	}
+	return findings
+}
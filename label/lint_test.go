@@ -0,0 +1,40 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package label
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	findings := Lint("Namespace", map[string]string{
+		"istio.io/dataplane-mode": "sidecar",
+		"istio.io/revv":           "canary",
+		"istio.io/rev":            "canary",
+		"app":                     "reviews",
+	})
+	var got []FindingType
+	for _, f := range findings {
+		got = append(got, f.Type)
+	}
+	want := []FindingType{InvalidLabelValue, UnknownLabel}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", findings, want)
+	}
+	if findings[1].Suggestion != IoIstioRev.Name {
+		t.Errorf("got suggestion %q, want %q", findings[1].Suggestion, IoIstioRev.Name)
+	}
+}
@@ -0,0 +1,35 @@
+// Code generated by kubernetes/cmd/metadata-gen. DO NOT EDIT.
+
+package label
+
+import (
+	"istio.io/api/internal/registry"
+)
+
+// metadatas is keyed by label name.
+var metadatas = map[string]*registry.Metadata{
+	GatewayManaged.Name:                         {Anchor: "GatewayManaged"},
+	IoIstioDataplaneMode.Name:                   {Anchor: "IoIstioDataplaneMode", AllowedValues: []string{"ambient", "none"}},
+	IoIstioIngressUseWaypoint.Name:              {Anchor: "IoIstioIngressUseWaypoint", ValueType: registry.Bool},
+	IoIstioRev.Name:                             {Anchor: "IoIstioRev"},
+	IoIstioTag.Name:                             {Anchor: "IoIstioTag"},
+	IoIstioUseWaypoint.Name:                     {Anchor: "IoIstioUseWaypoint"},
+	IoIstioUseWaypointNamespace.Name:            {Anchor: "IoIstioUseWaypointNamespace"},
+	IoIstioWaypointFor.Name:                     {Anchor: "IoIstioWaypointFor", AllowedValues: []string{"service", "workload", "all", "none"}},
+	IoK8sNetworkingGatewayGatewayClassName.Name: {Anchor: "IoK8sNetworkingGatewayGatewayClassName"},
+	IoK8sNetworkingGatewayGatewayName.Name:      {Anchor: "IoK8sNetworkingGatewayGatewayName"},
+	NetworkingEnableAutoallocateIp.Name:         {Anchor: "NetworkingEnableAutoallocateIp", ValueType: registry.Bool},
+	NetworkingGatewayPort.Name:                  {Anchor: "NetworkingGatewayPort", ValueType: registry.Int},
+	OperatorComponent.Name:                      {},
+	OperatorManaged.Name:                        {AllowedValues: []string{"Reconcile"}},
+	OperatorVersion.Name:                        {},
+	SecurityTlsMode.Name:                        {AllowedValues: []string{"istio", "disabled"}},
+	ServiceCanonicalName.Name:                   {Anchor: "ServiceCanonicalName"},
+	ServiceCanonicalRevision.Name:               {Anchor: "ServiceCanonicalRevision"},
+	ServiceWorkloadName.Name:                    {Anchor: "ServiceWorkloadName"},
+	SidecarInject.Name:                          {Anchor: "SidecarInject", ValueType: registry.Bool},
+	TopologyCluster.Name:                        {Anchor: "TopologyCluster"},
+	TopologyLocality.Name:                       {Anchor: "TopologyLocality"},
+	TopologyNetwork.Name:                        {Anchor: "TopologyNetwork"},
+	TopologySubzone.Name:                        {Anchor: "TopologySubzone"},
+}
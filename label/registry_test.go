@@ -0,0 +1,48 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package label
+
+import "testing"
+
+func TestLookup(t *testing.T) {
+	l, f := Lookup("istio.io/rev")
+	if !f || l != &IoIstioRev {
+		t.Fatalf("Lookup() = %v, %v", l, f)
+	}
+	if l.Docs() != "https://istio.io/docs/reference/config/labels/#IoIstioRev" {
+		t.Errorf("unexpected docs link %q", l.Docs())
+	}
+	for _, l := range WithPrefix("topology.istio.io/") {
+		if l.Name == IoIstioRev.Name {
+			t.Errorf("WithPrefix() includes %v", l.Name)
+		}
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	if len(metadatas) != len(AllResourceLabels()) {
+		t.Errorf("metadatas has %d entries, want %d", len(metadatas), len(AllResourceLabels()))
+	}
+	for _, l := range AllResourceLabels() {
+		if _, f := metadatas[l.Name]; !f {
+			t.Errorf("%s: missing from metadatas", l.Name)
+		}
+		for _, v := range l.AllowedValues() {
+			if err := l.Validate(v); err != nil {
+				t.Errorf("%s: allowed value %q is invalid: %v", l.Name, v, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package label
+
+import "istio.io/api/internal/registry"
+
+// ValueType is the type of the values of a label.
+type ValueType = registry.ValueType
+
+const (
+	String     = registry.String
+	Bool       = registry.Bool
+	Int        = registry.Int
+	Duration   = registry.Duration
+	Quantity   = registry.Quantity
+	CIDRList   = registry.CIDRList
+	PortList   = registry.PortList
+	StringList = registry.StringList
+	JSON       = registry.JSON
+)
+
+// docsURL is the page documenting the labels that are not hidden.
+const docsURL = "https://istio.io/docs/reference/config/labels/"
+
+// ValueType returns the type of the values of the label. Labels without metadata take any String.
+func (i Instance) ValueType() ValueType {
+	return metadataFor(i.Name).ValueType
+}
+
+// AllowedValues returns the only values allowed for the label, if restricted.
+func (i Instance) AllowedValues() []string {
+	return metadataFor(i.Name).AllowedValues
+}
+
+// Pattern returns the regular expression the values of the label must match, if any.
+func (i Instance) Pattern() string {
+	return metadataFor(i.Name).PatternString()
+}
+
+// ReplacedBy returns the name of the annotation or label replacing the label, if it is deprecated.
+func (i Instance) ReplacedBy() string {
+	return metadataFor(i.Name).ReplacedBy
+}
+
+// Docs returns a link to the documentation of the label, or "" if it is hidden.
+func (i Instance) Docs() string {
+	if a := metadataFor(i.Name).Anchor; a != "" {
+		return docsURL + "#" + a
+	}
+	return ""
+}
+
+// Parse validates value and returns it converted according to the ValueType of the label:
+// string for String and Quantity, bool for Bool, int64 for Int, time.Duration for Duration,
+// []string for the list types and the decoded document for JSON.
+func (i Instance) Parse(value string) (any, error) {
+	v, err := metadataFor(i.Name).Parse(value)
+	if err != nil {
+		return nil, registry.InvalidValueError("label", i.Name, value, err)
+	}
+	return v, nil
+}
+
+// Validate returns an error if value is not a valid value for the label.
+func (i Instance) Validate(value string) error {
+	_, err := i.Parse(value)
+	return err
+}
+
+func metadataFor(name string) *registry.Metadata {
+	if m, f := metadatas[name]; f {
+		return m
+	}
+	return &registry.Metadata{}
+}
@@ -0,0 +1,154 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"sort"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	analysis "istio.io/api/analysis/v1alpha1"
+)
+
+// Values of IstioCondition.Status.
+const (
+	ConditionTrue    = "True"
+	ConditionFalse   = "False"
+	ConditionUnknown = "Unknown"
+)
+
+// now is replaced in tests.
+var now = time.Now
+
+// SetCondition adds c to the conditions of s, or updates the condition of the same type, keeping
+// conditions sorted by type. LastTransitionTime is only changed when the status changes, and is
+// set to the current time if c does not carry one. The observed generation of s is raised to the
+// one of c. SetCondition returns true if s changed.
+func SetCondition(s *IstioStatus, c *IstioCondition) bool {
+	changed := false
+	if c.GetObservedGeneration() > s.GetObservedGeneration() {
+		s.ObservedGeneration = c.ObservedGeneration
+		changed = true
+	}
+	existing := FindCondition(s, c.GetType())
+	if existing == nil {
+		c = proto.Clone(c).(*IstioCondition)
+		if c.LastTransitionTime == nil {
+			c.LastTransitionTime = timestamppb.New(now())
+		}
+		s.Conditions = append(s.Conditions, c)
+		sort.SliceStable(s.Conditions, func(i, j int) bool { return s.Conditions[i].GetType() < s.Conditions[j].GetType() })
+		return true
+	}
+
+	if existing.Status != c.Status {
+		existing.Status = c.Status
+		if c.LastTransitionTime != nil {
+			existing.LastTransitionTime = proto.Clone(c.LastTransitionTime).(*timestamppb.Timestamp)
+		} else {
+			existing.LastTransitionTime = timestamppb.New(now())
+		}
+		changed = true
+	}
+	if existing.Reason != c.Reason {
+		existing.Reason = c.Reason
+		changed = true
+	}
+	if existing.Message != c.Message {
+		existing.Message = c.Message
+		changed = true
+	}
+	if existing.ObservedGeneration != c.ObservedGeneration {
+		existing.ObservedGeneration = c.ObservedGeneration
+		changed = true
+	}
+	if c.LastProbeTime != nil && !proto.Equal(existing.LastProbeTime, c.LastProbeTime) {
+		existing.LastProbeTime = proto.Clone(c.LastProbeTime).(*timestamppb.Timestamp)
+		changed = true
+	}
+	return changed
+}
+
+// FindCondition returns the condition of s with the given type, or nil.
+func FindCondition(s *IstioStatus, conditionType string) *IstioCondition {
+	for _, c := range s.GetConditions() {
+		if c.GetType() == conditionType {
+			return c
+		}
+	}
+	return nil
+}
+
+// IsConditionTrue returns true if s has a condition of the given type with status True.
+func IsConditionTrue(s *IstioStatus, conditionType string) bool {
+	return FindCondition(s, conditionType).GetStatus() == ConditionTrue
+}
+
+// RemoveCondition removes the condition of s with the given type. It returns true if s changed.
+func RemoveCondition(s *IstioStatus, conditionType string) bool {
+	for i, c := range s.GetConditions() {
+		if c.GetType() == conditionType {
+			s.Conditions = append(s.Conditions[:i], s.Conditions[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// MergeValidationMessages adds msgs to the validation messages of s, skipping those already
+// present. Validation messages carry no text or resource path, so two messages are the same only if
+// they are equal, and messages sharing a type code but differing in any other field are all kept.
+// Messages are kept sorted by level, most severe first, then by code. MergeValidationMessages
+// returns true if s changed.
+func MergeValidationMessages(s *IstioStatus, msgs ...*analysis.AnalysisMessageBase) bool {
+	changed := false
+	for _, m := range msgs {
+		if indexOfMessage(s.ValidationMessages, m) >= 0 {
+			continue
+		}
+		s.ValidationMessages = append(s.ValidationMessages, proto.Clone(m).(*analysis.AnalysisMessageBase))
+		changed = true
+	}
+	if !changed {
+		return false
+	}
+	sort.SliceStable(s.ValidationMessages, func(i, j int) bool {
+		a, b := s.ValidationMessages[i], s.ValidationMessages[j]
+		if a.GetLevel() != b.GetLevel() {
+			return levelOrder(a.GetLevel()) < levelOrder(b.GetLevel())
+		}
+		return a.GetType().GetCode() < b.GetType().GetCode()
+	})
+	return true
+}
+
+func indexOfMessage(msgs []*analysis.AnalysisMessageBase, m *analysis.AnalysisMessageBase) int {
+	for i, x := range msgs {
+		if proto.Equal(x, m) {
+			return i
+		}
+	}
+	return -1
+}
+
+// levelOrder sorts UNKNOWN after the other levels, which are numbered from most to least severe.
+func levelOrder(l analysis.AnalysisMessageBase_Level) int32 {
+	if l == analysis.AnalysisMessageBase_UNKNOWN {
+		return int32(analysis.AnalysisMessageBase_INFO) + 1
+	}
+	return int32(l)
+}
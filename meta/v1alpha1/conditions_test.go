@@ -0,0 +1,138 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	analysis "istio.io/api/analysis/v1alpha1"
+)
+
+func TestSetCondition(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := t0
+	now = func() time.Time { return clock }
+	defer func() { now = time.Now }()
+
+	s := &IstioStatus{}
+	if !SetCondition(s, &IstioCondition{Type: "Reconciled", Status: ConditionFalse, Reason: "Pending", ObservedGeneration: 1}) {
+		t.Fatal("adding a condition should change the status")
+	}
+	if !SetCondition(s, &IstioCondition{Type: "Accepted", Status: ConditionTrue}) {
+		t.Fatal("adding a condition should change the status")
+	}
+	if s.Conditions[0].Type != "Accepted" || s.Conditions[1].Type != "Reconciled" {
+		t.Fatalf("conditions are not sorted: %v", s.Conditions)
+	}
+	if s.ObservedGeneration != 1 {
+		t.Fatalf("got observed generation %d, want 1", s.ObservedGeneration)
+	}
+
+	clock = t0.Add(time.Minute)
+	if SetCondition(s, &IstioCondition{Type: "Reconciled", Status: ConditionFalse, Reason: "Pending", ObservedGeneration: 1}) {
+		t.Fatal("setting the same condition should not change the status")
+	}
+	if !SetCondition(s, &IstioCondition{Type: "Reconciled", Status: ConditionFalse, Reason: "Retrying", ObservedGeneration: 1}) {
+		t.Fatal("changing the reason should change the status")
+	}
+	if got := FindCondition(s, "Reconciled").LastTransitionTime.AsTime(); !got.Equal(t0) {
+		t.Fatalf("transition time changed without a status change: %v", got)
+	}
+
+	if !SetCondition(s, &IstioCondition{Type: "Reconciled", Status: ConditionTrue, ObservedGeneration: 2}) {
+		t.Fatal("changing the status should change the status")
+	}
+	c := FindCondition(s, "Reconciled")
+	if !c.LastTransitionTime.AsTime().Equal(clock) || c.Reason != "" || s.ObservedGeneration != 2 {
+		t.Fatalf("unexpected condition %v in %v", c, s)
+	}
+	if !IsConditionTrue(s, "Reconciled") || IsConditionTrue(s, "Missing") {
+		t.Fatal("IsConditionTrue() is wrong")
+	}
+
+	explicit := timestamppb.New(t0.Add(time.Hour))
+	SetCondition(s, &IstioCondition{Type: "Reconciled", Status: ConditionFalse, LastTransitionTime: explicit, ObservedGeneration: 2})
+	if !proto.Equal(FindCondition(s, "Reconciled").LastTransitionTime, explicit) {
+		t.Fatal("explicit transition time was not used")
+	}
+
+	if !RemoveCondition(s, "Accepted") || RemoveCondition(s, "Accepted") {
+		t.Fatal("RemoveCondition() is wrong")
+	}
+	if len(s.Conditions) != 1 || FindCondition(s, "Accepted") != nil {
+		t.Fatalf("unexpected conditions %v", s.Conditions)
+	}
+}
+
+func TestMergeValidationMessages(t *testing.T) {
+	msg := func(code string, level analysis.AnalysisMessageBase_Level) *analysis.AnalysisMessageBase {
+		return &analysis.AnalysisMessageBase{Type: &analysis.AnalysisMessageBase_Type{Code: code}, Level: level}
+	}
+	s := &IstioStatus{}
+	if !MergeValidationMessages(s, msg("IST0102", analysis.AnalysisMessageBase_INFO), msg("IST0101", analysis.AnalysisMessageBase_WARNING)) {
+		t.Fatal("adding messages should change the status")
+	}
+	if MergeValidationMessages(s, msg("IST0101", analysis.AnalysisMessageBase_WARNING)) {
+		t.Fatal("merging an existing message should not change the status")
+	}
+	if !MergeValidationMessages(s, msg("IST0102", analysis.AnalysisMessageBase_ERROR), msg("IST0103", analysis.AnalysisMessageBase_WARNING)) {
+		t.Fatal("adding a message with an existing code should change the status")
+	}
+	if MergeValidationMessages(s, msg("IST0102", analysis.AnalysisMessageBase_ERROR)) {
+		t.Fatal("merging a message twice should not change the status")
+	}
+	var got []string
+	for _, m := range s.ValidationMessages {
+		got = append(got, m.Type.Code+"/"+m.Level.String())
+	}
+	want := []string{"IST0102/ERROR", "IST0101/WARNING", "IST0103/WARNING", "IST0102/INFO"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestMergeValidationMessagesSameCode(t *testing.T) {
+	msg := func(name, url string) *analysis.AnalysisMessageBase {
+		return &analysis.AnalysisMessageBase{
+			Type:             &analysis.AnalysisMessageBase_Type{Code: "IST0101", Name: name},
+			Level:            analysis.AnalysisMessageBase_ERROR,
+			DocumentationUrl: url,
+		}
+	}
+	s := &IstioStatus{}
+	a := msg("ReferencedResourceNotFound", "https://istio.io/docs/reference/config/analysis/ist0101/")
+	b := msg("ReferencedResourceNotFound", "https://preliminary.istio.io/docs/reference/config/analysis/ist0101/")
+	if !MergeValidationMessages(s, a, b) {
+		t.Fatal("adding messages should change the status")
+	}
+	if MergeValidationMessages(s, b, a) {
+		t.Fatal("merging existing messages should not change the status")
+	}
+	if len(s.ValidationMessages) != 2 {
+		t.Fatalf("got %d messages, want both messages with code IST0101", len(s.ValidationMessages))
+	}
+	if !proto.Equal(s.ValidationMessages[0], a) || !proto.Equal(s.ValidationMessages[1], b) {
+		t.Errorf("got %v, want the messages in the order they were added", s.ValidationMessages)
+	}
+}
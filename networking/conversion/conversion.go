@@ -0,0 +1,54 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package conversion converts networking.istio.io resources between API versions.
+//
+// The v1beta1 and v1 networking types are aliases of v1alpha3, so conversion never
+// loses data. What differs between versions is which fields are meant to be used:
+// fields that are not yet stable may be set at v1alpha3, but should not be relied
+// upon at v1. Every conversion reports the fields set on the resource that are below the
+// stability level of the target version, as defined by the stability package, so tooling
+// can warn about them.
+package conversion
+
+import (
+	"google.golang.org/protobuf/proto"
+
+	"istio.io/api/stability"
+)
+
+// minLevels is the minimum stability level of the fields meant to be used at each version.
+var minLevels = map[string]stability.Level{
+	"v1alpha3": stability.Level_ALPHA,
+	"v1beta1":  stability.Level_BETA,
+	"v1":       stability.Level_STABLE,
+}
+
+// NonStableFields returns the fields set on m that are not stable at the given networking API version,
+// one of "v1alpha3", "v1beta1" or "v1".
+func NonStableFields(m proto.Message, version string) []stability.Field {
+	return stability.FieldsBelow(m, minLevels[version])
+}
+
+// convert copies in to out and returns the fields of out that are not stable at version.
+func convert[T proto.Message](in, out T, version string) []stability.Field {
+	// The versions share their Go types, so in and out may be the same message, which
+	// resetting out would empty.
+	if any(in) == any(out) {
+		return NonStableFields(out, version)
+	}
+	proto.Reset(out)
+	proto.Merge(out, in)
+	return NonStableFields(out, version)
+}
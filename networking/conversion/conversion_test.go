@@ -0,0 +1,114 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conversion
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	v1 "istio.io/api/networking/v1"
+	"istio.io/api/networking/v1alpha3"
+)
+
+func TestConvertVirtualService(t *testing.T) {
+	in := &v1alpha3.VirtualService{
+		Hosts: []string{"reviews"},
+		Tcp: []*v1alpha3.TCPRoute{{
+			Match: []*v1alpha3.L4MatchAttributes{{Port: 9080}, {SourceSubnet: "10.0.0.0/8"}},
+		}},
+	}
+	out := &v1.VirtualService{}
+	fields := Convert_v1alpha3_VirtualService_To_v1_VirtualService(in, out)
+	if !proto.Equal(in, out) {
+		t.Fatalf("conversion is not lossless: %v != %v", in, out)
+	}
+	if len(fields) != 1 || fields[0].Path != "tcp[0].match[1].sourceSubnet" {
+		t.Fatalf("unexpected non-stable fields: %v", fields)
+	}
+	if fields := Convert_v1_VirtualService_To_v1alpha3_VirtualService(out, &v1alpha3.VirtualService{}); len(fields) != 0 {
+		t.Fatalf("expected every field to be usable at v1alpha3, got %v", fields)
+	}
+}
+
+func TestConvertInPlace(t *testing.T) {
+	vs := &v1alpha3.VirtualService{Hosts: []string{"reviews"}}
+	Convert_v1alpha3_VirtualService_To_v1_VirtualService(vs, vs)
+	if len(vs.Hosts) != 1 || vs.Hosts[0] != "reviews" {
+		t.Fatalf("converting a message into itself emptied it: %v", vs)
+	}
+}
+
+func TestConvertReview(t *testing.T) {
+	obj := `{
+		"apiVersion": "networking.istio.io/v1alpha3",
+		"kind": "Sidecar",
+		"metadata": {"name": "default", "namespace": "istio-system", "resourceVersion": "12345678901234567890"},
+		"spec": {
+			"egress": [{"hosts": ["./*"]}],
+			"outboundTrafficPolicy": {"mode": "ALLOW_ANY", "egressProxy": {"host": "proxy"}},
+			"futureField": true
+		}
+	}`
+	review := &ConversionReview{
+		APIVersion: "apiextensions.k8s.io/v1",
+		Kind:       "ConversionReview",
+		Request: &ConversionRequest{
+			UID:               "uid",
+			DesiredAPIVersion: "networking.istio.io/v1",
+			Objects:           []json.RawMessage{json.RawMessage(obj)},
+		},
+	}
+	resp, fields := ConvertReview(review)
+	if resp.Response.Result.Status != StatusSuccess || resp.Response.UID != "uid" {
+		t.Fatalf("unexpected response: %+v", resp.Response)
+	}
+	var got, want map[string]any
+	if err := json.Unmarshal(resp.Response.ConvertedObjects[0], &got); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(obj), &want); err != nil {
+		t.Fatal(err)
+	}
+	want["apiVersion"] = "networking.istio.io/v1"
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("conversion is not lossless:\ngot  %v\nwant %v", got, want)
+	}
+	if len(fields) != 1 || len(fields[0]) != 1 || fields[0][0].Path != "spec.outboundTrafficPolicy.egressProxy" {
+		t.Fatalf("unexpected non-stable fields: %v", fields)
+	}
+
+	// Invalid specs are converted without being decoded, rather than failing the whole review.
+	invalid := `{"apiVersion":"networking.istio.io/v1alpha3","kind":"VirtualService","spec":{"hosts":5}}`
+	review.Request.Objects = append(review.Request.Objects, json.RawMessage(invalid))
+	resp, fields = ConvertReview(review)
+	if resp.Response.Result.Status != StatusSuccess || len(resp.Response.ConvertedObjects) != 2 {
+		t.Fatalf("unexpected response: %+v", resp.Response)
+	}
+	if got := string(resp.Response.ConvertedObjects[1]); got != strings.Replace(invalid, "v1alpha3", "v1", 1) {
+		t.Fatalf("got %s", got)
+	}
+	if len(fields) != 2 || len(fields[1]) != 0 {
+		t.Fatalf("unexpected non-stable fields: %v", fields)
+	}
+
+	review.Request.DesiredAPIVersion = "security.istio.io/v1"
+	if resp, _ := ConvertReview(review); resp.Response.Result.Status != StatusFailure {
+		t.Fatalf("expected a failure for an unsupported version, got %+v", resp.Response)
+	}
+}
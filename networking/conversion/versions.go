@@ -0,0 +1,163 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//nolint:revive // function names follow the Kubernetes Convert_<version>_<Kind>_To_<version>_<Kind> convention
+package conversion
+
+import (
+	v1 "istio.io/api/networking/v1"
+	"istio.io/api/networking/v1alpha3"
+	"istio.io/api/networking/v1beta1"
+	"istio.io/api/stability"
+)
+
+// Convert_v1alpha3_DestinationRule_To_v1_DestinationRule converts a v1alpha3 DestinationRule to v1, returning the fields that are not stable at v1.
+func Convert_v1alpha3_DestinationRule_To_v1_DestinationRule(in *v1alpha3.DestinationRule, out *v1.DestinationRule) []stability.Field {
+	return convert(in, out, "v1")
+}
+
+// Convert_v1_DestinationRule_To_v1alpha3_DestinationRule converts a v1 DestinationRule to v1alpha3, returning the fields that are not stable at v1alpha3.
+func Convert_v1_DestinationRule_To_v1alpha3_DestinationRule(in *v1.DestinationRule, out *v1alpha3.DestinationRule) []stability.Field {
+	return convert(in, out, "v1alpha3")
+}
+
+// Convert_v1beta1_DestinationRule_To_v1_DestinationRule converts a v1beta1 DestinationRule to v1, returning the fields that are not stable at v1.
+func Convert_v1beta1_DestinationRule_To_v1_DestinationRule(in *v1beta1.DestinationRule, out *v1.DestinationRule) []stability.Field {
+	return convert(in, out, "v1")
+}
+
+// Convert_v1_DestinationRule_To_v1beta1_DestinationRule converts a v1 DestinationRule to v1beta1, returning the fields that are not stable at v1beta1.
+func Convert_v1_DestinationRule_To_v1beta1_DestinationRule(in *v1.DestinationRule, out *v1beta1.DestinationRule) []stability.Field {
+	return convert(in, out, "v1beta1")
+}
+
+// Convert_v1alpha3_Gateway_To_v1_Gateway converts a v1alpha3 Gateway to v1, returning the fields that are not stable at v1.
+func Convert_v1alpha3_Gateway_To_v1_Gateway(in *v1alpha3.Gateway, out *v1.Gateway) []stability.Field {
+	return convert(in, out, "v1")
+}
+
+// Convert_v1_Gateway_To_v1alpha3_Gateway converts a v1 Gateway to v1alpha3, returning the fields that are not stable at v1alpha3.
+func Convert_v1_Gateway_To_v1alpha3_Gateway(in *v1.Gateway, out *v1alpha3.Gateway) []stability.Field {
+	return convert(in, out, "v1alpha3")
+}
+
+// Convert_v1beta1_Gateway_To_v1_Gateway converts a v1beta1 Gateway to v1, returning the fields that are not stable at v1.
+func Convert_v1beta1_Gateway_To_v1_Gateway(in *v1beta1.Gateway, out *v1.Gateway) []stability.Field {
+	return convert(in, out, "v1")
+}
+
+// Convert_v1_Gateway_To_v1beta1_Gateway converts a v1 Gateway to v1beta1, returning the fields that are not stable at v1beta1.
+func Convert_v1_Gateway_To_v1beta1_Gateway(in *v1.Gateway, out *v1beta1.Gateway) []stability.Field {
+	return convert(in, out, "v1beta1")
+}
+
+// Convert_v1alpha3_ServiceEntry_To_v1_ServiceEntry converts a v1alpha3 ServiceEntry to v1, returning the fields that are not stable at v1.
+func Convert_v1alpha3_ServiceEntry_To_v1_ServiceEntry(in *v1alpha3.ServiceEntry, out *v1.ServiceEntry) []stability.Field {
+	return convert(in, out, "v1")
+}
+
+// Convert_v1_ServiceEntry_To_v1alpha3_ServiceEntry converts a v1 ServiceEntry to v1alpha3, returning the fields that are not stable at v1alpha3.
+func Convert_v1_ServiceEntry_To_v1alpha3_ServiceEntry(in *v1.ServiceEntry, out *v1alpha3.ServiceEntry) []stability.Field {
+	return convert(in, out, "v1alpha3")
+}
+
+// Convert_v1beta1_ServiceEntry_To_v1_ServiceEntry converts a v1beta1 ServiceEntry to v1, returning the fields that are not stable at v1.
+func Convert_v1beta1_ServiceEntry_To_v1_ServiceEntry(in *v1beta1.ServiceEntry, out *v1.ServiceEntry) []stability.Field {
+	return convert(in, out, "v1")
+}
+
+// Convert_v1_ServiceEntry_To_v1beta1_ServiceEntry converts a v1 ServiceEntry to v1beta1, returning the fields that are not stable at v1beta1.
+func Convert_v1_ServiceEntry_To_v1beta1_ServiceEntry(in *v1.ServiceEntry, out *v1beta1.ServiceEntry) []stability.Field {
+	return convert(in, out, "v1beta1")
+}
+
+// Convert_v1alpha3_Sidecar_To_v1_Sidecar converts a v1alpha3 Sidecar to v1, returning the fields that are not stable at v1.
+func Convert_v1alpha3_Sidecar_To_v1_Sidecar(in *v1alpha3.Sidecar, out *v1.Sidecar) []stability.Field {
+	return convert(in, out, "v1")
+}
+
+// Convert_v1_Sidecar_To_v1alpha3_Sidecar converts a v1 Sidecar to v1alpha3, returning the fields that are not stable at v1alpha3.
+func Convert_v1_Sidecar_To_v1alpha3_Sidecar(in *v1.Sidecar, out *v1alpha3.Sidecar) []stability.Field {
+	return convert(in, out, "v1alpha3")
+}
+
+// Convert_v1beta1_Sidecar_To_v1_Sidecar converts a v1beta1 Sidecar to v1, returning the fields that are not stable at v1.
+func Convert_v1beta1_Sidecar_To_v1_Sidecar(in *v1beta1.Sidecar, out *v1.Sidecar) []stability.Field {
+	return convert(in, out, "v1")
+}
+
+// Convert_v1_Sidecar_To_v1beta1_Sidecar converts a v1 Sidecar to v1beta1, returning the fields that are not stable at v1beta1.
+func Convert_v1_Sidecar_To_v1beta1_Sidecar(in *v1.Sidecar, out *v1beta1.Sidecar) []stability.Field {
+	return convert(in, out, "v1beta1")
+}
+
+// Convert_v1alpha3_VirtualService_To_v1_VirtualService converts a v1alpha3 VirtualService to v1, returning the fields that are not stable at v1.
+func Convert_v1alpha3_VirtualService_To_v1_VirtualService(in *v1alpha3.VirtualService, out *v1.VirtualService) []stability.Field {
+	return convert(in, out, "v1")
+}
+
+// Convert_v1_VirtualService_To_v1alpha3_VirtualService converts a v1 VirtualService to v1alpha3, returning the fields that are not stable at v1alpha3.
+func Convert_v1_VirtualService_To_v1alpha3_VirtualService(in *v1.VirtualService, out *v1alpha3.VirtualService) []stability.Field {
+	return convert(in, out, "v1alpha3")
+}
+
+// Convert_v1beta1_VirtualService_To_v1_VirtualService converts a v1beta1 VirtualService to v1, returning the fields that are not stable at v1.
+func Convert_v1beta1_VirtualService_To_v1_VirtualService(in *v1beta1.VirtualService, out *v1.VirtualService) []stability.Field {
+	return convert(in, out, "v1")
+}
+
+// Convert_v1_VirtualService_To_v1beta1_VirtualService converts a v1 VirtualService to v1beta1, returning the fields that are not stable at v1beta1.
+func Convert_v1_VirtualService_To_v1beta1_VirtualService(in *v1.VirtualService, out *v1beta1.VirtualService) []stability.Field {
+	return convert(in, out, "v1beta1")
+}
+
+// Convert_v1alpha3_WorkloadEntry_To_v1_WorkloadEntry converts a v1alpha3 WorkloadEntry to v1, returning the fields that are not stable at v1.
+func Convert_v1alpha3_WorkloadEntry_To_v1_WorkloadEntry(in *v1alpha3.WorkloadEntry, out *v1.WorkloadEntry) []stability.Field {
+	return convert(in, out, "v1")
+}
+
+// Convert_v1_WorkloadEntry_To_v1alpha3_WorkloadEntry converts a v1 WorkloadEntry to v1alpha3, returning the fields that are not stable at v1alpha3.
+func Convert_v1_WorkloadEntry_To_v1alpha3_WorkloadEntry(in *v1.WorkloadEntry, out *v1alpha3.WorkloadEntry) []stability.Field {
+	return convert(in, out, "v1alpha3")
+}
+
+// Convert_v1beta1_WorkloadEntry_To_v1_WorkloadEntry converts a v1beta1 WorkloadEntry to v1, returning the fields that are not stable at v1.
+func Convert_v1beta1_WorkloadEntry_To_v1_WorkloadEntry(in *v1beta1.WorkloadEntry, out *v1.WorkloadEntry) []stability.Field {
+	return convert(in, out, "v1")
+}
+
+// Convert_v1_WorkloadEntry_To_v1beta1_WorkloadEntry converts a v1 WorkloadEntry to v1beta1, returning the fields that are not stable at v1beta1.
+func Convert_v1_WorkloadEntry_To_v1beta1_WorkloadEntry(in *v1.WorkloadEntry, out *v1beta1.WorkloadEntry) []stability.Field {
+	return convert(in, out, "v1beta1")
+}
+
+// Convert_v1alpha3_WorkloadGroup_To_v1_WorkloadGroup converts a v1alpha3 WorkloadGroup to v1, returning the fields that are not stable at v1.
+func Convert_v1alpha3_WorkloadGroup_To_v1_WorkloadGroup(in *v1alpha3.WorkloadGroup, out *v1.WorkloadGroup) []stability.Field {
+	return convert(in, out, "v1")
+}
+
+// Convert_v1_WorkloadGroup_To_v1alpha3_WorkloadGroup converts a v1 WorkloadGroup to v1alpha3, returning the fields that are not stable at v1alpha3.
+func Convert_v1_WorkloadGroup_To_v1alpha3_WorkloadGroup(in *v1.WorkloadGroup, out *v1alpha3.WorkloadGroup) []stability.Field {
+	return convert(in, out, "v1alpha3")
+}
+
+// Convert_v1beta1_WorkloadGroup_To_v1_WorkloadGroup converts a v1beta1 WorkloadGroup to v1, returning the fields that are not stable at v1.
+func Convert_v1beta1_WorkloadGroup_To_v1_WorkloadGroup(in *v1beta1.WorkloadGroup, out *v1.WorkloadGroup) []stability.Field {
+	return convert(in, out, "v1")
+}
+
+// Convert_v1_WorkloadGroup_To_v1beta1_WorkloadGroup converts a v1 WorkloadGroup to v1beta1, returning the fields that are not stable at v1beta1.
+func Convert_v1_WorkloadGroup_To_v1beta1_WorkloadGroup(in *v1.WorkloadGroup, out *v1beta1.WorkloadGroup) []stability.Field {
+	return convert(in, out, "v1beta1")
+}
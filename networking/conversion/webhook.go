@@ -0,0 +1,165 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conversion
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"istio.io/api/networking/v1alpha3"
+	"istio.io/api/stability"
+)
+
+// Group is the API group of the resources handled by this package.
+const Group = "networking.istio.io"
+
+// Versions lists the networking API versions, oldest first.
+var Versions = []string{"v1alpha3", "v1beta1", "v1"}
+
+var specs = map[string]func() proto.Message{
+	"DestinationRule": func() proto.Message { return &v1alpha3.DestinationRule{} },
+	"Gateway":         func() proto.Message { return &v1alpha3.Gateway{} },
+	"ServiceEntry":    func() proto.Message { return &v1alpha3.ServiceEntry{} },
+	"Sidecar":         func() proto.Message { return &v1alpha3.Sidecar{} },
+	"VirtualService":  func() proto.Message { return &v1alpha3.VirtualService{} },
+	"WorkloadEntry":   func() proto.Message { return &v1alpha3.WorkloadEntry{} },
+	"WorkloadGroup":   func() proto.Message { return &v1alpha3.WorkloadGroup{} },
+}
+
+// ConversionReview mirrors the wire format of an apiextensions.k8s.io/v1 ConversionReview,
+// so that a CRD conversion webhook can be served without depending on Kubernetes libraries.
+type ConversionReview struct {
+	APIVersion string              `json:"apiVersion"`
+	Kind       string              `json:"kind"`
+	Request    *ConversionRequest  `json:"request,omitempty"`
+	Response   *ConversionResponse `json:"response,omitempty"`
+}
+
+// ConversionRequest is the request of a ConversionReview.
+type ConversionRequest struct {
+	UID               string            `json:"uid"`
+	DesiredAPIVersion string            `json:"desiredAPIVersion"`
+	Objects           []json.RawMessage `json:"objects"`
+}
+
+// ConversionResponse is the response of a ConversionReview.
+type ConversionResponse struct {
+	UID              string            `json:"uid"`
+	ConvertedObjects []json.RawMessage `json:"convertedObjects"`
+	Result           Status            `json:"result"`
+}
+
+// Status is the subset of a metav1.Status used in a ConversionResponse.
+type Status struct {
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// Values of Status.Status.
+const (
+	StatusSuccess = "Success"
+	StatusFailure = "Failure"
+)
+
+// ConvertReview answers a ConversionReview request. The fields that are not stable at the desired
+// version are returned for each object, in request order, so that the webhook can log or surface them.
+func ConvertReview(review *ConversionReview) (*ConversionReview, [][]stability.Field) {
+	out := &ConversionReview{APIVersion: review.APIVersion, Kind: review.Kind}
+	if review.Request == nil {
+		out.Response = &ConversionResponse{Result: Status{Status: StatusFailure, Message: "request must be set"}}
+		return out, nil
+	}
+	resp := &ConversionResponse{UID: review.Request.UID, Result: Status{Status: StatusSuccess}}
+	out.Response = resp
+	fields := make([][]stability.Field, 0, len(review.Request.Objects))
+	for i, obj := range review.Request.Objects {
+		converted, f, err := ConvertObject(obj, review.Request.DesiredAPIVersion)
+		if err != nil {
+			resp.ConvertedObjects = nil
+			resp.Result = Status{Status: StatusFailure, Message: fmt.Sprintf("objects[%d]: %v", i, err)}
+			return out, nil
+		}
+		resp.ConvertedObjects = append(resp.ConvertedObjects, converted)
+		fields = append(fields, f)
+	}
+	return out, fields
+}
+
+// ConvertObject converts a JSON encoded networking.istio.io resource to desiredAPIVersion, for
+// example "networking.istio.io/v1". All fields, including ones this package does not know about,
+// are preserved. The returned fields are relative to the object, for example "spec.tcp[0].match[0].sourceSubnet".
+// A spec that cannot be decoded is passed through unchanged, without reporting its fields, as the
+// API server must still be able to serve objects stored before validation caught the error.
+func ConvertObject(obj []byte, desiredAPIVersion string) ([]byte, []stability.Field, error) {
+	version, err := parseAPIVersion(desiredAPIVersion)
+	if err != nil {
+		return nil, nil, err
+	}
+	var u map[string]any
+	d := json.NewDecoder(bytes.NewReader(obj))
+	d.UseNumber()
+	if err := d.Decode(&u); err != nil {
+		return nil, nil, err
+	}
+	apiVersion, _ := u["apiVersion"].(string)
+	if _, err := parseAPIVersion(apiVersion); err != nil {
+		return nil, nil, err
+	}
+	kind, _ := u["kind"].(string)
+	newSpec, f := specs[kind]
+	if !f {
+		return nil, nil, fmt.Errorf("unsupported kind %q", kind)
+	}
+
+	var fields []stability.Field
+	if spec, f := u["spec"]; f {
+		b, err := json.Marshal(spec)
+		if err != nil {
+			return nil, nil, err
+		}
+		m := newSpec()
+		if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(b, m); err == nil {
+			for _, field := range NonStableFields(m, version) {
+				field.Path = "spec." + field.Path
+				fields = append(fields, field)
+			}
+		}
+	}
+
+	u["apiVersion"] = desiredAPIVersion
+	converted, err := json.Marshal(u)
+	if err != nil {
+		return nil, nil, err
+	}
+	return converted, fields, nil
+}
+
+// parseAPIVersion returns the version of a networking.istio.io apiVersion.
+func parseAPIVersion(apiVersion string) (string, error) {
+	group, version, f := strings.Cut(apiVersion, "/")
+	if f && group == Group {
+		for _, v := range Versions {
+			if v == version {
+				return version, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("unsupported apiVersion %q", apiVersion)
+}
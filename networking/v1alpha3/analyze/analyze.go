@@ -0,0 +1,230 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package analyze finds VirtualService routing mistakes that are valid configuration but almost
+// certainly not what was intended: routes shadowed by earlier routes, hosts claimed by several
+// VirtualServices on the same gateway, and delegate VirtualServices that are unused or shared.
+// Findings are reported as analysis/v1alpha1 messages.
+package analyze
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	analysis "istio.io/api/analysis/v1alpha1"
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/api/networking/v1alpha3/simulate"
+)
+
+// VirtualServices analyzes vss and returns the messages for the problems found, grouped by check
+// and, within each check, in the order of vss.
+func VirtualServices(vss []*simulate.VirtualService) []*analysis.GenericAnalysisMessage {
+	var msgs []*analysis.GenericAnalysisMessage
+	for _, vs := range vss {
+		msgs = append(msgs, analyzeRules(vs)...)
+	}
+	msgs = append(msgs, analyzeHosts(vss)...)
+	return append(msgs, analyzeDelegates(vss)...)
+}
+
+// resourcePath returns the analysis resource path of vs.
+func resourcePath(vs *simulate.VirtualService) string {
+	if vs.Namespace == "" {
+		return "VirtualService/" + vs.Name
+	}
+	return vs.Namespace + "/VirtualService/" + vs.Name
+}
+
+// message returns a message about vs. The message types are built in, so a failure to construct
+// the message is a programming error.
+func message(code string, vs *simulate.VirtualService, args ...any) *analysis.GenericAnalysisMessage {
+	m, err := analysis.NewMessage(code, []string{resourcePath(vs)}, args...)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// rule is a route of a VirtualService, reduced to what matters for shadowing.
+type rule[M any] struct {
+	name       string
+	matches    []M
+	matchNames []string
+	// catchAll is set for routes without match clauses, whose matches hold a single clause
+	// matching all requests.
+	catchAll bool
+	// shadows is unset for routes that do not stop route evaluation for the requests they match,
+	// such as delegating routes, whose requests go on to the next route when no delegate route
+	// matches.
+	shadows bool
+}
+
+// shadowedRules reports the rules that can never match as IST0130, and the match clauses that
+// can never match in otherwise reachable rules as IST0131. covers(a, b) reports whether every
+// request matching b also matches a.
+func shadowedRules[M any](vs *simulate.VirtualService, rules []rule[M], covers func(a, b M) bool) []*analysis.GenericAnalysisMessage {
+	var msgs []*analysis.GenericAnalysisMessage
+	for i, r := range rules {
+		// coveredBy holds, for each match clause, the index of the first earlier rule covering it,
+		// i for a clause covered by an earlier clause of the same rule, and -1 otherwise.
+		coveredBy := make([]int, len(r.matches))
+		all := len(r.matches) > 0
+		for k, m := range r.matches {
+			coveredBy[k] = -1
+			for j := 0; j < i && coveredBy[k] < 0; j++ {
+				if rules[j].shadows && slices.ContainsFunc(rules[j].matches, func(pm M) bool { return covers(pm, m) }) {
+					coveredBy[k] = j
+				}
+			}
+			if coveredBy[k] < 0 {
+				all = false
+				if slices.ContainsFunc(r.matches[:k], func(pm M) bool { return covers(pm, m) }) {
+					coveredBy[k] = i
+				}
+			}
+		}
+		if all {
+			msgs = append(msgs, message(analysis.VirtualServiceUnreachableRuleCode, vs, r.name, unreachableReason(rules, coveredBy)))
+			continue
+		}
+		for k, j := range coveredBy {
+			if j >= 0 {
+				msgs = append(msgs, message(analysis.VirtualServiceIneffectiveMatchCode, vs, r.name, r.matchNames[k], rules[j].name))
+			}
+		}
+	}
+	return msgs
+}
+
+func unreachableReason[M any](rules []rule[M], coveredBy []int) string {
+	slices.Sort(coveredBy)
+	coveredBy = slices.Compact(coveredBy)
+	if len(coveredBy) > 1 {
+		return "all its matches are covered by earlier rules"
+	}
+	if rules[coveredBy[0]].catchAll {
+		return fmt.Sprintf("rule %s has no matches, and only the last rule can have no matches", rules[coveredBy[0]].name)
+	}
+	return fmt.Sprintf("all its matches are covered by rule %s", rules[coveredBy[0]].name)
+}
+
+func ruleName(kind string, i int, name string) string {
+	if name != "" {
+		return name
+	}
+	return kind + "[" + strconv.Itoa(i) + "]"
+}
+
+func matchName(i int, name string) string {
+	if name != "" {
+		return name
+	}
+	return strconv.Itoa(i)
+}
+
+// analyzeRules reports the unreachable HTTP, TLS and TCP routes of vs.
+func analyzeRules(vs *simulate.VirtualService) []*analysis.GenericAnalysisMessage {
+	gateways := vs.Spec.GetGateways()
+	var msgs []*analysis.GenericAnalysisMessage
+
+	httpRules := make([]rule[*httpMatch], 0, len(vs.Spec.GetHttp()))
+	for i, r := range vs.Spec.GetHttp() {
+		hr := rule[*httpMatch]{name: ruleName("http", i, r.GetName()), shadows: r.GetDelegate() == nil}
+		if len(r.GetMatch()) == 0 {
+			hr.catchAll = true
+			hr.matches = []*httpMatch{newHTTPMatch(&networking.HTTPMatchRequest{}, vs.Namespace, gateways)}
+			hr.matchNames = []string{"0"}
+		}
+		for k, m := range r.GetMatch() {
+			hr.matches = append(hr.matches, newHTTPMatch(m, vs.Namespace, gateways))
+			hr.matchNames = append(hr.matchNames, matchName(k, m.GetName()))
+		}
+		httpRules = append(httpRules, hr)
+	}
+	msgs = append(msgs, shadowedRules(vs, httpRules, coversHTTP)...)
+
+	tlsRules := make([]rule[*networking.TLSMatchAttributes], 0, len(vs.Spec.GetTls()))
+	for i, r := range vs.Spec.GetTls() {
+		tr := rule[*networking.TLSMatchAttributes]{name: ruleName("tls", i, ""), shadows: true}
+		for k, m := range r.GetMatch() {
+			tr.matches = append(tr.matches, m)
+			tr.matchNames = append(tr.matchNames, matchName(k, ""))
+		}
+		tlsRules = append(tlsRules, tr)
+	}
+	msgs = append(msgs, shadowedRules(vs, tlsRules, func(a, b *networking.TLSMatchAttributes) bool {
+		return coversTLS(a, b, vs.Namespace, gateways)
+	})...)
+
+	tcpRules := make([]rule[*networking.L4MatchAttributes], 0, len(vs.Spec.GetTcp()))
+	for i, r := range vs.Spec.GetTcp() {
+		tr := rule[*networking.L4MatchAttributes]{name: ruleName("tcp", i, ""), shadows: true}
+		if len(r.GetMatch()) == 0 {
+			tr.catchAll = true
+			tr.matches = []*networking.L4MatchAttributes{{}}
+			tr.matchNames = []string{"0"}
+		}
+		for k, m := range r.GetMatch() {
+			tr.matches = append(tr.matches, m)
+			tr.matchNames = append(tr.matchNames, matchName(k, ""))
+		}
+		tcpRules = append(tcpRules, tr)
+	}
+	return append(msgs, shadowedRules(vs, tcpRules, func(a, b *networking.L4MatchAttributes) bool {
+		return coversL4(a, b, vs.Namespace, gateways)
+	})...)
+}
+
+// normalizeGateways returns the gateways a route is bound to, given the gateways of its match
+// clause and of its VirtualService, as sorted namespace/name references.
+func normalizeGateways(namespace string, matchGateways, vsGateways []string) []string {
+	gws := matchGateways
+	if len(gws) == 0 {
+		gws = vsGateways
+	}
+	if len(gws) == 0 {
+		return []string{simulate.MeshGateway}
+	}
+	out := make([]string, 0, len(gws))
+	for _, gw := range gws {
+		if gw != simulate.MeshGateway && !strings.Contains(gw, "/") {
+			gw = namespace + "/" + gw
+		}
+		out = append(out, gw)
+	}
+	slices.Sort(out)
+	return slices.Compact(out)
+}
+
+// coversGateways reports whether the gateways a are a superset of the gateways b.
+func coversGateways(a, b []string) bool {
+	for _, gw := range b {
+		if !slices.Contains(a, gw) {
+			return false
+		}
+	}
+	return true
+}
+
+// coversLabels reports whether the source label selector a selects every workload b selects.
+func coversLabels(a, b map[string]string) bool {
+	for k, v := range a {
+		if bv, f := b[k]; !f || bv != v {
+			return false
+		}
+	}
+	return true
+}
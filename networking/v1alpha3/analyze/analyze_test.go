@@ -0,0 +1,232 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analyze
+
+import (
+	"slices"
+	"testing"
+
+	analysis "istio.io/api/analysis/v1alpha1"
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/api/networking/v1alpha3/simulate"
+)
+
+func prefix(p string) *networking.StringMatch {
+	return &networking.StringMatch{MatchType: &networking.StringMatch_Prefix{Prefix: p}}
+}
+
+func exact(e string) *networking.StringMatch {
+	return &networking.StringMatch{MatchType: &networking.StringMatch_Exact{Exact: e}}
+}
+
+func regex(r string) *networking.StringMatch {
+	return &networking.StringMatch{MatchType: &networking.StringMatch_Regex{Regex: r}}
+}
+
+func virtualService(name string, spec *networking.VirtualService) *simulate.VirtualService {
+	return &simulate.VirtualService{Name: name, Namespace: "default", Spec: spec}
+}
+
+// rendered returns the rendered messages.
+func rendered(t *testing.T, msgs []*analysis.GenericAnalysisMessage) []string {
+	t.Helper()
+	out := make([]string, 0, len(msgs))
+	for _, m := range msgs {
+		s, err := m.Render()
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func checkMessages(t *testing.T, vss []*simulate.VirtualService, want ...string) {
+	t.Helper()
+	got := rendered(t, VirtualServices(vss))
+	if !slices.Equal(got, want) {
+		t.Fatalf("got messages\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestShadowedHTTPRoutes(t *testing.T) {
+	vs := virtualService("reviews", &networking.VirtualService{
+		Hosts: []string{"reviews"},
+		Http: []*networking.HTTPRoute{
+			{Name: "api", Match: []*networking.HTTPMatchRequest{{Uri: prefix("/api")}}},
+			{Name: "api-v2", Match: []*networking.HTTPMatchRequest{{Uri: prefix("/api/v2")}, {Uri: exact("/health")}}},
+			{Name: "jason", Match: []*networking.HTTPMatchRequest{{
+				Uri:     regex("/api/.*"),
+				Headers: map[string]*networking.StringMatch{"end-user": exact("jason")},
+			}}},
+			{Name: "catch-all"},
+			{Name: "unreachable", Match: []*networking.HTTPMatchRequest{{Method: exact("POST")}}},
+		},
+	})
+	checkMessages(t, []*simulate.VirtualService{vs},
+		"Info [IST0131] (default/VirtualService/reviews) VirtualService rule api-v2 match 0 is not used (duplicate/overlapping match in rule api).",
+		"Warning [IST0130] (default/VirtualService/reviews) VirtualService rule unreachable not used "+
+			"(rule catch-all has no matches, and only the last rule can have no matches).",
+	)
+}
+
+func TestCoversHTTP(t *testing.T) {
+	cases := []struct {
+		name string
+		a, b *networking.HTTPMatchRequest
+		want bool
+	}{
+		{"empty covers all", &networking.HTTPMatchRequest{}, &networking.HTTPMatchRequest{Uri: exact("/a"), Port: 80}, true},
+		{"prefix covers exact", &networking.HTTPMatchRequest{Uri: prefix("/a")}, &networking.HTTPMatchRequest{Uri: exact("/a/b")}, true},
+		{"root prefix covers any uri", &networking.HTTPMatchRequest{Uri: prefix("/")}, &networking.HTTPMatchRequest{Uri: regex(".*foo")}, true},
+		{
+			"root prefix does not cover any method",
+			&networking.HTTPMatchRequest{Method: prefix("/")},
+			&networking.HTTPMatchRequest{Method: exact("GET")},
+			false,
+		},
+		{
+			"root prefix does not cover any header",
+			&networking.HTTPMatchRequest{Headers: map[string]*networking.StringMatch{"x-user": prefix("/")}},
+			&networking.HTTPMatchRequest{Headers: map[string]*networking.StringMatch{"x-user": exact("jason")}},
+			false,
+		},
+		{"exact does not cover prefix", &networking.HTTPMatchRequest{Uri: exact("/a")}, &networking.HTTPMatchRequest{Uri: prefix("/a")}, false},
+		{"regex covers exact", &networking.HTTPMatchRequest{Uri: regex("/a/[0-9]+")}, &networking.HTTPMatchRequest{Uri: exact("/a/12")}, true},
+		{"regex does not cover prefix", &networking.HTTPMatchRequest{Uri: regex("/a/.*")}, &networking.HTTPMatchRequest{Uri: prefix("/a/")}, false},
+		{
+			"ignore case covers case sensitive",
+			&networking.HTTPMatchRequest{Uri: prefix("/API"), IgnoreUriCase: true},
+			&networking.HTTPMatchRequest{Uri: prefix("/api/v1")},
+			true,
+		},
+		{
+			"case sensitive does not cover ignore case",
+			&networking.HTTPMatchRequest{Uri: prefix("/api")},
+			&networking.HTTPMatchRequest{Uri: prefix("/api/v1"), IgnoreUriCase: true},
+			false,
+		},
+		{
+			"header presence covers header value",
+			&networking.HTTPMatchRequest{Headers: map[string]*networking.StringMatch{"X-User": {}}},
+			&networking.HTTPMatchRequest{Headers: map[string]*networking.StringMatch{"x-user": exact("jason")}},
+			true,
+		},
+		{
+			"header does not cover missing header",
+			&networking.HTTPMatchRequest{Headers: map[string]*networking.StringMatch{"x-user": {}}},
+			&networking.HTTPMatchRequest{},
+			false,
+		},
+		{
+			"without headers",
+			&networking.HTTPMatchRequest{WithoutHeaders: map[string]*networking.StringMatch{"x-debug": {}}},
+			&networking.HTTPMatchRequest{WithoutHeaders: map[string]*networking.StringMatch{"x-debug": {}, "x-canary": {}}},
+			true,
+		},
+		{
+			"source labels",
+			&networking.HTTPMatchRequest{SourceLabels: map[string]string{"app": "web"}},
+			&networking.HTTPMatchRequest{SourceLabels: map[string]string{"app": "web", "version": "v1"}},
+			true,
+		},
+		{
+			"gateways",
+			&networking.HTTPMatchRequest{Gateways: []string{"ingress"}},
+			&networking.HTTPMatchRequest{Gateways: []string{"ingress", "mesh"}},
+			false,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := newHTTPMatch(tt.a, "default", nil), newHTTPMatch(tt.b, "default", nil)
+			if got := coversHTTP(a, b); got != tt.want {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShadowedTLSAndTCPRoutes(t *testing.T) {
+	vs := virtualService("passthrough", &networking.VirtualService{
+		Hosts:    []string{"*.example.com"},
+		Gateways: []string{"ingress"},
+		Tls: []*networking.TLSRoute{
+			{Match: []*networking.TLSMatchAttributes{{SniHosts: []string{"*.example.com"}}}},
+			{Match: []*networking.TLSMatchAttributes{{SniHosts: []string{"api.example.com"}, Port: 443}}},
+		},
+		Tcp: []*networking.TCPRoute{
+			{Match: []*networking.L4MatchAttributes{{DestinationSubnets: []string{"10.0.0.0/8"}}}},
+			{Match: []*networking.L4MatchAttributes{{DestinationSubnets: []string{"10.1.0.0/16", "10.2.0.1"}}}},
+			{Match: []*networking.L4MatchAttributes{{DestinationSubnets: []string{"192.168.0.0/16"}}}},
+		},
+	})
+	checkMessages(t, []*simulate.VirtualService{vs},
+		"Warning [IST0130] (default/VirtualService/passthrough) VirtualService rule tls[1] not used (all its matches are covered by rule tls[0]).",
+		"Warning [IST0130] (default/VirtualService/passthrough) VirtualService rule tcp[1] not used (all its matches are covered by rule tcp[0]).",
+	)
+}
+
+func TestConflictingHosts(t *testing.T) {
+	vss := []*simulate.VirtualService{
+		virtualService("reviews", &networking.VirtualService{Hosts: []string{"reviews"}}),
+		virtualService("reviews-fqdn", &networking.VirtualService{Hosts: []string{"reviews.default.svc.cluster.local"}}),
+		virtualService("wildcard", &networking.VirtualService{Hosts: []string{"*.example.com"}, Gateways: []string{"ingress"}}),
+		virtualService("api", &networking.VirtualService{Hosts: []string{"api.example.com"}, Gateways: []string{"default/ingress"}}),
+		virtualService("other-gateway", &networking.VirtualService{Hosts: []string{"api.example.com"}, Gateways: []string{"egress"}}),
+	}
+	checkMessages(t, vss,
+		"Warning [API0001] (default/VirtualService/wildcard) The VirtualServices default/api,default/wildcard bound to gateway "+
+			"default/ingress define overlapping hosts *.example.com. Requests for these hosts are only routed by the "+
+			"VirtualService with the most specific host, in creation order for identical hosts.",
+		"Warning [API0001] (default/VirtualService/api) The VirtualServices default/api,default/wildcard bound to gateway "+
+			"default/ingress define overlapping hosts api.example.com. Requests for these hosts are only routed by the "+
+			"VirtualService with the most specific host, in creation order for identical hosts.",
+		"Error [IST0109] (default/VirtualService/reviews) The VirtualServices default/reviews,default/reviews-fqdn associated with "+
+			"mesh gateway define the same host reviews.default.svc.cluster.local which can lead to undefined behavior. "+
+			"This can be fixed by merging the conflicting VirtualServices into a single resource.",
+		"Error [IST0109] (default/VirtualService/reviews-fqdn) The VirtualServices default/reviews,default/reviews-fqdn associated with "+
+			"mesh gateway define the same host reviews.default.svc.cluster.local which can lead to undefined behavior. "+
+			"This can be fixed by merging the conflicting VirtualServices into a single resource.",
+	)
+}
+
+func TestDelegates(t *testing.T) {
+	delegate := func(name string) *networking.HTTPRoute {
+		return &networking.HTTPRoute{
+			Match:    []*networking.HTTPMatchRequest{{Uri: prefix("/" + name)}},
+			Delegate: &networking.Delegate{Name: name},
+		}
+	}
+	vss := []*simulate.VirtualService{
+		virtualService("root", &networking.VirtualService{
+			Hosts: []string{"bookinfo.example.com"},
+			Http:  []*networking.HTTPRoute{delegate("reviews"), delegate("missing")},
+		}),
+		virtualService("other-root", &networking.VirtualService{
+			Hosts: []string{"reviews.example.com"},
+			Http:  []*networking.HTTPRoute{{Name: "reviews", Delegate: &networking.Delegate{Name: "reviews", Namespace: "default"}}},
+		}),
+		virtualService("reviews", &networking.VirtualService{Http: []*networking.HTTPRoute{{Name: "all"}}}),
+		virtualService("unused", &networking.VirtualService{Http: []*networking.HTTPRoute{{Name: "all"}}}),
+	}
+	checkMessages(t, vss,
+		`Error [IST0101] (default/VirtualService/root) Referenced delegate VirtualService not found: "default/missing"`,
+		"Warning [API0002] (default/VirtualService/reviews) The delegate VirtualService is referenced by the routes "+
+			"[default/root http[0] default/other-root reviews]. A delegate should be referenced by a single route.",
+		"Warning [API0003] (default/VirtualService/unused) The VirtualService has no hosts and is not referenced as a delegate "+
+			"by any route, so it has no effect.",
+	)
+}
@@ -0,0 +1,62 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analyze
+
+import (
+	analysis "istio.io/api/analysis/v1alpha1"
+	"istio.io/api/networking/v1alpha3/simulate"
+)
+
+// analyzeDelegates reports the delegate VirtualServices referenced by more than one route as
+// API0002, those referenced by no route as API0003, and references to missing VirtualServices
+// as IST0101.
+func analyzeDelegates(vss []*simulate.VirtualService) []*analysis.GenericAnalysisMessage {
+	var msgs []*analysis.GenericAnalysisMessage
+	byName := map[string]*simulate.VirtualService{}
+	for _, vs := range vss {
+		byName[vs.String()] = vs
+	}
+	refs := map[*simulate.VirtualService][]string{}
+	for _, vs := range vss {
+		for i, r := range vs.Spec.GetHttp() {
+			d := r.GetDelegate()
+			if d == nil {
+				continue
+			}
+			ns := d.Namespace
+			if ns == "" {
+				ns = vs.Namespace
+			}
+			target, f := byName[ns+"/"+d.Name]
+			if !f {
+				msgs = append(msgs, message(analysis.ReferencedResourceNotFoundCode, vs, "delegate VirtualService", ns+"/"+d.Name))
+				continue
+			}
+			refs[target] = append(refs[target], vs.String()+" "+ruleName("http", i, r.Name))
+		}
+	}
+	for _, vs := range vss {
+		if len(vs.Spec.GetHosts()) > 0 {
+			continue
+		}
+		switch n := len(refs[vs]); {
+		case n == 0:
+			msgs = append(msgs, message(analysis.UnreferencedDelegateVirtualServiceCode, vs))
+		case n > 1:
+			msgs = append(msgs, message(analysis.DelegateVirtualServiceReferencedMultipleTimesCode, vs, refs[vs]))
+		}
+	}
+	return msgs
+}
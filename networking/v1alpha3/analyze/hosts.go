@@ -0,0 +1,121 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analyze
+
+import (
+	"slices"
+	"strings"
+
+	analysis "istio.io/api/analysis/v1alpha1"
+	"istio.io/api/networking/v1alpha3/host"
+	"istio.io/api/networking/v1alpha3/simulate"
+)
+
+// binding is a VirtualService bound to a gateway, with its hosts expanded to FQDNs.
+type binding struct {
+	vs    *simulate.VirtualService
+	hosts []string
+}
+
+// analyzeHosts reports the VirtualServices defining overlapping hosts on the same gateway.
+// Identical hosts on the mesh gateway are reported as IST0109, and other overlaps as API0001.
+func analyzeHosts(vss []*simulate.VirtualService) []*analysis.GenericAnalysisMessage {
+	var gateways []string
+	byGateway := map[string][]binding{}
+	for _, vs := range vss {
+		if len(vs.Spec.GetHosts()) == 0 {
+			continue
+		}
+		b := binding{vs: vs}
+		for _, h := range vs.Spec.Hosts {
+			b.hosts = append(b.hosts, string(host.Expand(host.Name(h), vs.Namespace, simulate.DefaultDomainSuffix)))
+		}
+		for _, gw := range boundGateways(vs) {
+			if _, f := byGateway[gw]; !f {
+				gateways = append(gateways, gw)
+			}
+			byGateway[gw] = append(byGateway[gw], b)
+		}
+	}
+	slices.Sort(gateways)
+
+	var msgs []*analysis.GenericAnalysisMessage
+	for _, gw := range gateways {
+		bindings := byGateway[gw]
+		for i, b := range bindings {
+			var conflicting, hosts []string
+			for _, h := range b.hosts {
+				var identical []string
+				overlapping := false
+				for j, o := range bindings {
+					if i == j {
+						continue
+					}
+					for _, oh := range o.hosts {
+						switch {
+						case h == oh && gw == simulate.MeshGateway:
+							identical = append(identical, o.vs.String())
+						case host.Name(h).Intersects(host.Name(oh)):
+							conflicting = append(conflicting, o.vs.String())
+							overlapping = true
+						}
+					}
+				}
+				if len(identical) > 0 {
+					names := sortedUnique(append(identical, b.vs.String()))
+					msgs = append(msgs, message(analysis.ConflictingMeshGatewayVirtualServiceHostsCode, b.vs,
+						strings.Join(names, ","), h))
+				}
+				if overlapping {
+					hosts = append(hosts, h)
+				}
+			}
+			if len(conflicting) > 0 {
+				names := sortedUnique(append(conflicting, b.vs.String()))
+				msgs = append(msgs, message(analysis.ConflictingGatewayVirtualServiceHostsCode, b.vs,
+					strings.Join(names, ","), gw, strings.Join(sortedUnique(hosts), ",")))
+			}
+		}
+	}
+	return msgs
+}
+
+// boundGateways returns the gateways vs is bound to, either directly or through the match
+// clauses of its routes.
+func boundGateways(vs *simulate.VirtualService) []string {
+	gws := normalizeGateways(vs.Namespace, nil, vs.Spec.Gateways)
+	for _, r := range vs.Spec.Http {
+		for _, m := range r.GetMatch() {
+			gws = append(gws, normalizeGateways(vs.Namespace, m.GetGateways(), vs.Spec.Gateways)...)
+		}
+	}
+	for _, r := range vs.Spec.Tls {
+		for _, m := range r.GetMatch() {
+			gws = append(gws, normalizeGateways(vs.Namespace, m.GetGateways(), vs.Spec.Gateways)...)
+		}
+	}
+	for _, r := range vs.Spec.Tcp {
+		for _, m := range r.GetMatch() {
+			gws = append(gws, normalizeGateways(vs.Namespace, m.GetGateways(), vs.Spec.Gateways)...)
+		}
+	}
+	return sortedUnique(gws)
+}
+
+func sortedUnique(s []string) []string {
+	s = slices.Clone(s)
+	slices.Sort(s)
+	return slices.Compact(s)
+}
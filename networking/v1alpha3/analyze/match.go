@@ -0,0 +1,257 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analyze
+
+import (
+	"net/netip"
+	"regexp"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/api/networking/v1alpha3/host"
+)
+
+// httpMatch is an HTTPMatchRequest along with the gateways it applies to.
+type httpMatch struct {
+	*networking.HTTPMatchRequest
+	gateways []string
+}
+
+func newHTTPMatch(m *networking.HTTPMatchRequest, namespace string, vsGateways []string) *httpMatch {
+	return &httpMatch{HTTPMatchRequest: m, gateways: normalizeGateways(namespace, m.Gateways, vsGateways)}
+}
+
+// coversHTTP reports whether every request matching b also matches a.
+func coversHTTP(a, b *httpMatch) bool {
+	return coversURI(a, b) &&
+		coversString(a.Scheme, b.Scheme, false, false) &&
+		coversString(a.Method, b.Method, false, false) &&
+		coversString(a.Authority, b.Authority, false, false) &&
+		coversStringMap(a.Headers, b.Headers, strings.ToLower) &&
+		coversStringMap(a.QueryParams, b.QueryParams, nil) &&
+		coversWithout(a.WithoutHeaders, b.WithoutHeaders) &&
+		(a.Port == 0 || a.Port == b.Port) &&
+		(a.SourceNamespace == "" || a.SourceNamespace == b.SourceNamespace) &&
+		coversLabels(a.SourceLabels, b.SourceLabels) &&
+		coversGateways(a.gateways, b.gateways)
+}
+
+// matchesAll reports whether m matches every value. A match without a type only requires the
+// value to be present, which the pseudo-headers matched by uri, scheme, method and authority
+// always are.
+func matchesAll(m *networking.StringMatch) bool {
+	switch t := m.GetMatchType().(type) {
+	case nil:
+		return true
+	case *networking.StringMatch_Prefix:
+		return t.Prefix == ""
+	case *networking.StringMatch_Regex:
+		return t.Regex == ".*" || t.Regex == "^.*$"
+	}
+	return false
+}
+
+// coversURI is coversString for the uri matches of a and b. Request paths always start with "/",
+// so that prefix matches every uri, while it does not match every header or method.
+func coversURI(a, b *httpMatch) bool {
+	if p, ok := a.Uri.GetMatchType().(*networking.StringMatch_Prefix); ok && p.Prefix == "/" {
+		return true
+	}
+	return coversString(a.Uri, b.Uri, a.IgnoreUriCase, b.IgnoreUriCase)
+}
+
+// coversString reports whether every value matching b also matches a. It errs on the side of
+// returning false, as regular expressions are only compared with exact values and themselves.
+func coversString(a, b *networking.StringMatch, aIgnoreCase, bIgnoreCase bool) bool {
+	if matchesAll(a) {
+		return true
+	}
+	if b.GetMatchType() == nil {
+		return false
+	}
+	// A case sensitive match cannot cover a case insensitive one.
+	if bIgnoreCase && !aIgnoreCase {
+		return false
+	}
+	equal := func(x, y string) bool {
+		if aIgnoreCase {
+			return strings.EqualFold(x, y)
+		}
+		return x == y
+	}
+	switch am := a.MatchType.(type) {
+	case *networking.StringMatch_Exact:
+		bm, ok := b.MatchType.(*networking.StringMatch_Exact)
+		return ok && equal(am.Exact, bm.Exact)
+	case *networking.StringMatch_Prefix:
+		var s string
+		switch bm := b.MatchType.(type) {
+		case *networking.StringMatch_Exact:
+			s = bm.Exact
+		case *networking.StringMatch_Prefix:
+			s = bm.Prefix
+		default:
+			return false
+		}
+		return len(s) >= len(am.Prefix) && equal(am.Prefix, s[:len(am.Prefix)])
+	case *networking.StringMatch_Regex:
+		switch bm := b.MatchType.(type) {
+		case *networking.StringMatch_Exact:
+			re, err := regexp.Compile("^(?:" + am.Regex + ")$")
+			return err == nil && !bIgnoreCase && re.MatchString(bm.Exact)
+		case *networking.StringMatch_Regex:
+			return am.Regex == bm.Regex
+		}
+	}
+	return false
+}
+
+// coversStringMap reports whether every request whose headers or query parameters match b also
+// matches a. Keys are compared after applying normalize, if set.
+func coversStringMap(a, b map[string]*networking.StringMatch, normalize func(string) string) bool {
+	if len(a) == 0 {
+		return true
+	}
+	nb := make(map[string]*networking.StringMatch, len(b))
+	for k, v := range b {
+		if normalize != nil {
+			k = normalize(k)
+		}
+		nb[k] = v
+	}
+	for k, am := range a {
+		if normalize != nil {
+			k = normalize(k)
+		}
+		bm, f := nb[k]
+		if !f || !coversString(am, bm, false, false) {
+			return false
+		}
+	}
+	return true
+}
+
+// coversWithout reports whether every request excluded by the withoutHeaders a is also excluded
+// by the withoutHeaders b.
+func coversWithout(a, b map[string]*networking.StringMatch) bool {
+	for k, am := range a {
+		found := false
+		for bk, bm := range b {
+			if strings.EqualFold(k, bk) && (proto.Equal(am, bm) || matchesAll(bm)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// coversTLS reports whether every connection matching b also matches a.
+func coversTLS(a, b *networking.TLSMatchAttributes, namespace string, vsGateways []string) bool {
+	return coversHosts(a.SniHosts, b.SniHosts) &&
+		coversSubnets(a.DestinationSubnets, b.DestinationSubnets) &&
+		(a.Port == 0 || a.Port == b.Port) &&
+		(a.SourceNamespace == "" || a.SourceNamespace == b.SourceNamespace) &&
+		coversLabels(a.SourceLabels, b.SourceLabels) &&
+		coversGateways(normalizeGateways(namespace, a.Gateways, vsGateways), normalizeGateways(namespace, b.Gateways, vsGateways))
+}
+
+// coversL4 reports whether every connection matching b also matches a.
+func coversL4(a, b *networking.L4MatchAttributes, namespace string, vsGateways []string) bool {
+	var as, bs []string
+	if a.SourceSubnet != "" {
+		as = []string{a.SourceSubnet}
+	}
+	if b.SourceSubnet != "" {
+		bs = []string{b.SourceSubnet}
+	}
+	return coversSubnets(a.DestinationSubnets, b.DestinationSubnets) &&
+		coversSubnets(as, bs) &&
+		(a.Port == 0 || a.Port == b.Port) &&
+		(a.SourceNamespace == "" || a.SourceNamespace == b.SourceNamespace) &&
+		coversLabels(a.SourceLabels, b.SourceLabels) &&
+		coversGateways(normalizeGateways(namespace, a.Gateways, vsGateways), normalizeGateways(namespace, b.Gateways, vsGateways))
+}
+
+// coversHosts reports whether every host in b is matched by a host in a. An empty list matches
+// all hosts.
+func coversHosts(a, b []string) bool {
+	if len(a) == 0 {
+		return true
+	}
+	if len(b) == 0 {
+		return false
+	}
+	for _, bh := range b {
+		covered := false
+		for _, ah := range a {
+			if host.Name(bh).SubsetOf(host.Name(ah)) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+	return true
+}
+
+// coversSubnets reports whether every address in the subnets b is in one of the subnets a. An
+// empty list matches all addresses.
+func coversSubnets(a, b []string) bool {
+	if len(a) == 0 {
+		return true
+	}
+	if len(b) == 0 {
+		return false
+	}
+	for _, bs := range b {
+		bp, err := parsePrefix(bs)
+		if err != nil {
+			return false
+		}
+		covered := false
+		for _, as := range a {
+			ap, err := parsePrefix(as)
+			if err == nil && ap.Bits() <= bp.Bits() && ap.Contains(bp.Addr()) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+	return true
+}
+
+// parsePrefix parses a CIDR block or a single IP address.
+func parsePrefix(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		p, err := netip.ParsePrefix(s)
+		return p.Masked(), err
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
@@ -0,0 +1,146 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package host implements the host name semantics shared by the networking resources: parsing,
+// wildcard matching, short name expansion and exportTo visibility.
+//
+// Hosts appear in VirtualService.hosts, Gateway and Sidecar hosts, which are qualified by a
+// namespace as in "namespace/host", ServiceEntry.hosts and DestinationRule.host. A host is either
+// a DNS name, which may have a leading wildcard label ("*.example.com"), or "*", which matches
+// all names. Short names such as "reviews" stand for the Kubernetes service of that name in the
+// namespace of the resource.
+package host
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrEmpty is returned when parsing an empty host.
+var ErrEmpty = errors.New("host must not be empty")
+
+const (
+	dns1123LabelMaxLength = 63
+	dns1123LabelFmt       = "[a-zA-Z0-9](?:[-a-zA-Z0-9]*[a-zA-Z0-9])?"
+	maxLength             = 253
+)
+
+var dns1123LabelRegexp = regexp.MustCompile("^" + dns1123LabelFmt + "$")
+
+// Name is a host name, possibly a wildcard. Names are case insensitive, and those returned by
+// this package are lower case.
+type Name string
+
+// Parse parses a host name, which may be a wildcard such as "*.example.com" or "*".
+func Parse(s string) (Name, error) {
+	switch {
+	case s == "":
+		return "", ErrEmpty
+	case s == "*":
+		return "*", nil
+	case strings.HasPrefix(s, "*"):
+		if !strings.HasPrefix(s, "*.") {
+			return "", errors.New("wildcard must be the only character of the leftmost label")
+		}
+		if _, err := ParseFQDN(s[2:]); err != nil {
+			return "", err
+		}
+		return Name(strings.ToLower(s)), nil
+	}
+	return ParseFQDN(s)
+}
+
+// ParseFQDN parses a host name that is not a wildcard. A trailing dot is allowed.
+func ParseFQDN(s string) (Name, error) {
+	if len(s) > maxLength {
+		return "", fmt.Errorf("must be no more than %d characters", maxLength)
+	}
+	for _, label := range strings.Split(strings.TrimSuffix(s, "."), ".") {
+		if len(label) > dns1123LabelMaxLength || !dns1123LabelRegexp.MatchString(label) {
+			return "", fmt.Errorf("label %q must consist of alphanumeric characters or '-', "+
+				"and must start and end with an alphanumeric character", label)
+		}
+	}
+	return Name(strings.ToLower(s)), nil
+}
+
+// IsWildcard reports whether n is a wildcard.
+func (n Name) IsWildcard() bool {
+	return strings.HasPrefix(string(n), "*")
+}
+
+// Matches reports whether the host h, which is not a wildcard, is one of the hosts n stands for.
+func (n Name) Matches(h Name) bool {
+	return h.SubsetOf(n)
+}
+
+// SubsetOf reports whether all the hosts n stands for are also matched by o. Wildcards only
+// match names with more labels: "*.example.com" matches neither "example.com" nor "*".
+func (n Name) SubsetOf(o Name) bool {
+	n, o = Name(strings.ToLower(string(n))), Name(strings.ToLower(string(o)))
+	if n == o || o == "*" {
+		return true
+	}
+	suffix, wildcard := strings.CutPrefix(string(o), "*")
+	return wildcard && strings.HasSuffix(string(n), suffix) && len(n) > len(suffix)
+}
+
+// Intersects reports whether some host is matched by both n and o. Since wildcards only match
+// suffixes, two names intersect if and only if one is a subset of the other.
+func (n Name) Intersects(o Name) bool {
+	return n.SubsetOf(o) || o.SubsetOf(n)
+}
+
+// Intersection returns the hosts matched by both n and o, which is the more specific of the two
+// names, and whether they intersect.
+func (n Name) Intersection(o Name) (Name, bool) {
+	switch {
+	case n.SubsetOf(o):
+		return n, true
+	case o.SubsetOf(n):
+		return o, true
+	}
+	return "", false
+}
+
+// CompareSpecificity orders the names a and b matching the same host by how specifically they
+// match it: it returns a positive number if a is more specific than b, a negative number if it is
+// less specific, and 0 if they are as specific. Exact names are more specific than wildcards, and
+// longer wildcards than shorter ones, which is how Istio selects among the resources matching a
+// host.
+func CompareSpecificity(a, b Name) int {
+	switch aw, bw := a.IsWildcard(), b.IsWildcard(); {
+	case !aw && !bw:
+		return 0
+	case !aw:
+		return 1
+	case !bw:
+		return -1
+	}
+	return len(a) - len(b)
+}
+
+// MostSpecific returns the index of the name in candidates that most specifically matches the
+// host h, which is not a wildcard, or -1 if none matches. Ties go to the first candidate.
+func MostSpecific(h Name, candidates []Name) int {
+	best := -1
+	for i, c := range candidates {
+		if c.Matches(h) && (best < 0 || CompareSpecificity(c, candidates[best]) > 0) {
+			best = i
+		}
+	}
+	return best
+}
@@ -0,0 +1,195 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package host
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		in   string
+		want Name
+		err  bool
+	}{
+		{in: "Reviews.Default.svc.cluster.local", want: "reviews.default.svc.cluster.local"},
+		{in: "*.example.com", want: "*.example.com"},
+		{in: "*", want: "*"},
+		{in: "example.com.", want: "example.com."},
+		{in: "*example.com", err: true},
+		{in: "foo.*.com", err: true},
+		{in: "-foo.com", err: true},
+		{in: "foo..com", err: true},
+	}
+	for _, c := range cases {
+		got, err := Parse(c.in)
+		if (err != nil) != c.err || got != c.want {
+			t.Errorf("Parse(%q) = %q, %v", c.in, got, err)
+		}
+	}
+	if _, err := Parse(""); !errors.Is(err, ErrEmpty) {
+		t.Errorf("expected ErrEmpty, got %v", err)
+	}
+	if _, err := ParseFQDN("*.example.com"); err == nil {
+		t.Errorf("expected an error for a wildcard FQDN")
+	}
+}
+
+func TestParseNamespaced(t *testing.T) {
+	cases := []struct {
+		in      string
+		require bool
+		want    Namespaced
+		errs    int
+	}{
+		{in: "prod/*.example.com", want: Namespaced{"prod", "*.example.com"}},
+		{in: "./*", want: Namespaced{SameNamespace, "*"}},
+		{in: "~/*", want: Namespaced{NoNamespace, "*"}},
+		{in: "*/reviews.default.svc.cluster.local", want: Namespaced{AllNamespaces, "reviews.default.svc.cluster.local"}},
+		{in: "example.com", want: Namespaced{AllNamespaces, "example.com"}},
+		{in: "example.com", require: true, errs: 1},
+		{in: "~/example.com", errs: 1},
+		{in: "Prod_/*ex", errs: 2},
+	}
+	for _, c := range cases {
+		got, err := ParseNamespaced(c.in, c.require)
+		var errs []error
+		if err != nil {
+			errs = []error{err}
+			if j, ok := err.(interface{ Unwrap() []error }); ok {
+				errs = j.Unwrap()
+			}
+		}
+		if len(errs) != c.errs || got != c.want {
+			t.Errorf("ParseNamespaced(%q) = %v, %v", c.in, got, err)
+		}
+	}
+}
+
+func TestMatching(t *testing.T) {
+	cases := []struct {
+		a, b       Name
+		subset     bool
+		intersects bool
+	}{
+		{"foo.example.com", "foo.example.com", true, true},
+		{"foo.example.com", "*.example.com", true, true},
+		{"a.foo.example.com", "*.example.com", true, true},
+		{"*.foo.example.com", "*.example.com", true, true},
+		{"example.com", "*.example.com", false, false},
+		{"*.example.com", "foo.example.com", false, true},
+		{"*.example.com", "*", true, true},
+		{"*", "*.example.com", false, true},
+		{"*.example.com", "*.example.org", false, false},
+		{"Foo.Example.com", "foo.example.com", true, true},
+	}
+	for _, c := range cases {
+		if got := c.a.SubsetOf(c.b); got != c.subset {
+			t.Errorf("%q.SubsetOf(%q) = %v", c.a, c.b, got)
+		}
+		if got := c.a.Intersects(c.b); got != c.intersects {
+			t.Errorf("%q.Intersects(%q) = %v", c.a, c.b, got)
+		}
+	}
+	if n, ok := Name("*.example.com").Intersection("*.foo.example.com"); !ok || n != "*.foo.example.com" {
+		t.Errorf("got intersection %q, %v", n, ok)
+	}
+
+	candidates := []Name{"*", "*.com", "foo.example.com", "*.example.com"}
+	for h, want := range map[Name]int{"foo.example.com": 2, "bar.example.com": 3, "example.com": 1, "localhost": 0} {
+		if got := MostSpecific(h, candidates); got != want {
+			t.Errorf("MostSpecific(%q) = %d, want %d", h, got, want)
+		}
+	}
+	if got := MostSpecific("foo", []Name{"bar"}); got != -1 {
+		t.Errorf("expected no match, got %d", got)
+	}
+}
+
+func TestNamespaced(t *testing.T) {
+	cases := []struct {
+		n         Namespaced
+		namespace string
+		host      Name
+		want      bool
+	}{
+		{Namespaced{AllNamespaces, "*.example.com"}, "prod", "foo.example.com", true},
+		{Namespaced{SameNamespace, "*"}, "istio-system", "foo.example.com", true},
+		{Namespaced{SameNamespace, "*"}, "prod", "foo.example.com", false},
+		{Namespaced{"prod", "foo.example.com"}, "prod", "*.example.com", true},
+		{Namespaced{"prod", "foo.example.com"}, "dev", "foo.example.com", false},
+		{Namespaced{NoNamespace, "*"}, "istio-system", "foo.example.com", false},
+	}
+	for _, c := range cases {
+		if got := c.n.Selects("istio-system", c.namespace, c.host); got != c.want {
+			t.Errorf("%v.Selects(%q, %q) = %v", c.n, c.namespace, c.host, got)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	for in, want := range map[Name]Name{
+		"reviews":            "reviews.default.svc.cluster.local",
+		"Reviews":            "reviews.default.svc.cluster.local",
+		"reviews.prod":       "reviews.prod",
+		"*":                  "*",
+		"*.example.com":      "*.example.com",
+		"ratings.prod.svc.x": "ratings.prod.svc.x",
+	} {
+		if got := Expand(in, "default", DefaultDomainSuffix); got != want {
+			t.Errorf("Expand(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	if svc, ns, ok := KubernetesService("reviews.prod.svc.cluster.local", DefaultDomainSuffix); !ok || svc != "reviews" || ns != "prod" {
+		t.Errorf("got %q, %q, %v", svc, ns, ok)
+	}
+	for _, h := range []Name{"reviews.prod.svc.example.com", "a.b.prod.svc.cluster.local", "*.prod.svc.cluster.local", "reviews"} {
+		if _, _, ok := KubernetesService(h, DefaultDomainSuffix); ok {
+			t.Errorf("%q is not a Kubernetes service", h)
+		}
+	}
+
+	got := Aliases("reviews.prod.svc.cluster.local", "prod", DefaultDomainSuffix)
+	if want := []Name{"reviews.prod.svc.cluster.local", "reviews.prod.svc", "reviews.prod", "reviews"}; !slices.Equal(got, want) {
+		t.Errorf("got aliases %v, want %v", got, want)
+	}
+	if got := Aliases("example.com", "prod", DefaultDomainSuffix); !slices.Equal(got, []Name{"example.com"}) {
+		t.Errorf("got aliases %v", got)
+	}
+}
+
+func TestVisible(t *testing.T) {
+	cases := []struct {
+		exportTo  []string
+		namespace string
+		want      bool
+	}{
+		{nil, "prod", true},
+		{[]string{"*"}, "prod", true},
+		{[]string{"."}, "default", true},
+		{[]string{"."}, "prod", false},
+		{[]string{".", "prod"}, "prod", true},
+		{[]string{"~"}, "default", false},
+		{[]string{"dev"}, "prod", false},
+	}
+	for _, c := range cases {
+		if got := Visible(c.exportTo, "default", c.namespace); got != c.want {
+			t.Errorf("Visible(%v, %q) = %v", c.exportTo, c.namespace, got)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package host
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DefaultDomainSuffix is the default cluster domain suffix of Kubernetes services.
+const DefaultDomainSuffix = "cluster.local"
+
+// Reserved namespaces of namespaced hosts and exportTo.
+const (
+	// AllNamespaces matches all namespaces.
+	AllNamespaces = "*"
+	// SameNamespace stands for the namespace of the resource.
+	SameNamespace = "."
+	// NoNamespace matches no namespace.
+	NoNamespace = "~"
+)
+
+// Namespaced is a host qualified by the namespaces it selects configuration from, as in the
+// "namespace/host" hosts of Gateway servers and Sidecar egress listeners.
+type Namespaced struct {
+	// Namespace is a namespace name, or one of AllNamespaces, SameNamespace and NoNamespace.
+	Namespace string
+	Name      Name
+}
+
+// ParseNamespaced parses a namespaced host, such as "prod/*.example.com", "./*" or "~/*". Hosts
+// without a namespace select all namespaces, unless requireNamespace is set. All the problems
+// found are returned, joined.
+func ParseNamespaced(s string, requireNamespace bool) (Namespaced, error) {
+	ns, name, found := strings.Cut(s, "/")
+	if !found {
+		if requireNamespace {
+			return Namespaced{}, errors.New("must be of the form namespace/dnsName")
+		}
+		ns, name = AllNamespaces, s
+	}
+	var errs []error
+	switch ns {
+	case AllNamespaces, SameNamespace:
+	case NoNamespace:
+		if name != "*" {
+			errs = append(errs, fmt.Errorf("namespace %q may only be used with host \"*\"", NoNamespace))
+		}
+	default:
+		if len(ns) > dns1123LabelMaxLength || !dns1123LabelRegexp.MatchString(ns) {
+			errs = append(errs, fmt.Errorf("invalid namespace %q", ns))
+		}
+	}
+	n, err := Parse(name)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return Namespaced{}, errors.Join(errs...)
+	}
+	return Namespaced{Namespace: ns, Name: n}, nil
+}
+
+func (n Namespaced) String() string {
+	return n.Namespace + "/" + string(n.Name)
+}
+
+// Selects reports whether n, set on a resource in configNamespace, selects configuration for
+// the host h from namespace: the namespace must be selected, and h must intersect the name of n.
+func (n Namespaced) Selects(configNamespace, namespace string, h Name) bool {
+	switch n.Namespace {
+	case NoNamespace:
+		return false
+	case SameNamespace:
+		if namespace != configNamespace {
+			return false
+		}
+	case AllNamespaces:
+	default:
+		if namespace != n.Namespace {
+			return false
+		}
+	}
+	return n.Name.Intersects(h)
+}
+
+// Expand returns the FQDN of the host h of a resource in namespace: short names stand for the
+// Kubernetes service of that name in namespace. Other names are returned as they are.
+func Expand(h Name, namespace, domainSuffix string) Name {
+	h = Name(strings.ToLower(string(h)))
+	if h == "" || h.IsWildcard() || strings.Contains(string(h), ".") {
+		return h
+	}
+	return h + Name("."+namespace+".svc."+domainSuffix)
+}
+
+// KubernetesService returns the name and namespace of the Kubernetes service h is the FQDN of,
+// and whether it is one.
+func KubernetesService(h Name, domainSuffix string) (string, string, bool) {
+	name, found := strings.CutSuffix(strings.ToLower(string(h)), ".svc."+domainSuffix)
+	if !found {
+		return "", "", false
+	}
+	svc, ns, found := strings.Cut(name, ".")
+	if !found || svc == "" || ns == "" || strings.Contains(ns, ".") || strings.Contains(svc, "*") {
+		return "", "", false
+	}
+	return svc, ns, true
+}
+
+// Aliases returns the names a client in namespace can reach the host fqdn by. Kubernetes
+// services can also be reached through the prefixes of their FQDN that DNS search paths expand,
+// and by their short name from their own namespace.
+func Aliases(fqdn Name, namespace, domainSuffix string) []Name {
+	svc, ns, ok := KubernetesService(fqdn, domainSuffix)
+	if !ok {
+		return []Name{fqdn}
+	}
+	name := Name(svc + "." + ns)
+	aliases := []Name{fqdn, name + ".svc", name}
+	if ns == namespace {
+		aliases = append(aliases, Name(svc))
+	}
+	return aliases
+}
+
+// Visible reports whether a resource in configNamespace with the given exportTo is visible in
+// namespace. Resources without exportTo are visible in all namespaces.
+func Visible(exportTo []string, configNamespace, namespace string) bool {
+	if len(exportTo) == 0 {
+		return true
+	}
+	for _, e := range exportTo {
+		switch e {
+		case AllNamespaces:
+			return true
+		case SameNamespace:
+			if namespace == configNamespace {
+				return true
+			}
+		case NoNamespace:
+		default:
+			if e == namespace {
+				return true
+			}
+		}
+	}
+	return false
+}
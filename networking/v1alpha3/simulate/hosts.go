@@ -0,0 +1,94 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package simulate
+
+import (
+	"slices"
+	"strings"
+
+	"istio.io/api/networking/v1alpha3/host"
+)
+
+// selectVirtualServices returns the VirtualServices routing the host of r, in evaluation order.
+// Only the VirtualServices whose host is the most specific match are returned: an exact host
+// wins over a wildcard, and a longer wildcard over a shorter one. Gateways merge the routes of
+// equally specific VirtualServices, but sidecars do not, so only the first one is returned for
+// the mesh gateway.
+func (s *Simulator) selectVirtualServices(r *request) []*VirtualService {
+	var selected []*VirtualService
+	var best host.Name
+	for _, vs := range s.virtualServices {
+		if len(vs.Spec.GetHosts()) == 0 || !s.visible(r, vs) || !bound(r, vs) {
+			continue
+		}
+		var match host.Name
+		for _, h := range vs.Spec.Hosts {
+			if m, ok := s.matchHost(r, vs.Namespace, h); ok && (match == "" || host.CompareSpecificity(m, match) > 0) {
+				match = m
+			}
+		}
+		if match == "" {
+			continue
+		}
+		switch c := host.CompareSpecificity(match, best); {
+		case len(selected) == 0 || c > 0:
+			best = match
+			selected = []*VirtualService{vs}
+		case c == 0:
+			selected = append(selected, vs)
+		}
+	}
+	if r.gateway == MeshGateway && len(selected) > 1 {
+		return selected[:1]
+	}
+	return selected
+}
+
+// bound reports whether vs is bound to the gateway of r, either directly or through the
+// gateways of one of its match clauses.
+func bound(r *request, vs *VirtualService) bool {
+	if boundToGateway(r.gateway, vs.Namespace, vs.Spec.Gateways) {
+		return true
+	}
+	for _, route := range vs.Spec.Http {
+		for _, m := range route.GetMatch() {
+			if len(m.GetGateways()) > 0 && boundToGateway(r.gateway, vs.Namespace, m.Gateways) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// visible reports whether vs is exported to the namespace of the client: the source namespace
+// for sidecars, and the namespace of the gateway otherwise. Without a known namespace, all
+// VirtualServices are visible.
+func (s *Simulator) visible(r *request, vs *VirtualService) bool {
+	ns := r.SourceNamespace
+	if gwNamespace, _, f := strings.Cut(r.gateway, "/"); f {
+		ns = gwNamespace
+	}
+	return ns == "" || host.Visible(vs.Spec.ExportTo, vs.Namespace, ns)
+}
+
+// matchHost returns the FQDN of the VirtualService host h, relative to namespace, if it matches
+// the host of r. Short names also match the names clients can reach their service by.
+func (s *Simulator) matchHost(r *request, namespace, h string) (host.Name, bool) {
+	fqdn := host.Expand(host.Name(h), namespace, s.domainSuffix())
+	if fqdn.IsWildcard() {
+		return fqdn, fqdn.Matches(host.Name(r.host))
+	}
+	return fqdn, slices.Contains(host.Aliases(fqdn, r.SourceNamespace, s.domainSuffix()), host.Name(r.host))
+}
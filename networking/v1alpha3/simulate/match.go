@@ -0,0 +1,125 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package simulate
+
+import (
+	"regexp"
+	"strings"
+
+	networking "istio.io/api/networking/v1alpha3"
+)
+
+// matchRoute returns the clause of route matching r. A route without match clauses matches all
+// requests, in which case the returned clause is nil. Gateways are those the VirtualService of
+// the route is bound to, with names relative to namespace.
+func matchRoute(r *request, route *networking.HTTPRoute, namespace string, gateways []string) (*networking.HTTPMatchRequest, bool) {
+	if len(route.Match) == 0 {
+		return nil, boundToGateway(r.gateway, namespace, gateways)
+	}
+	for _, m := range route.Match {
+		if m == nil {
+			continue
+		}
+		gws := gateways
+		if len(m.Gateways) > 0 {
+			gws = m.Gateways
+		}
+		if boundToGateway(r.gateway, namespace, gws) && matchRequest(r, m) {
+			return m, true
+		}
+	}
+	return nil, false
+}
+
+// matchRequest reports whether r matches all the conditions of m.
+func matchRequest(r *request, m *networking.HTTPMatchRequest) bool {
+	if m.Uri != nil && !matchString(m.Uri, r.path, m.IgnoreUriCase) {
+		return false
+	}
+	if m.Scheme != nil && !matchString(m.Scheme, r.scheme(), false) {
+		return false
+	}
+	if m.Method != nil && !matchString(m.Method, r.method(), false) {
+		return false
+	}
+	if m.Authority != nil && !matchString(m.Authority, r.Authority, false) {
+		return false
+	}
+	if m.Port != 0 && m.Port != r.Port {
+		return false
+	}
+	if m.SourceNamespace != "" && m.SourceNamespace != r.SourceNamespace {
+		return false
+	}
+	for k, v := range m.SourceLabels {
+		if l, f := r.SourceLabels[k]; !f || l != v {
+			return false
+		}
+	}
+	for k, sm := range m.Headers {
+		if v, f := r.headers[strings.ToLower(k)]; !f || !matchString(sm, v, false) {
+			return false
+		}
+	}
+	for k, sm := range m.WithoutHeaders {
+		if v, f := r.headers[strings.ToLower(k)]; f && matchString(sm, v, false) {
+			return false
+		}
+	}
+	for k, sm := range m.QueryParams {
+		if v, f := r.query[k]; !f || !matchString(sm, v, false) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchString reports whether v matches sm. Regular expressions use RE2 syntax and must match
+// the whole value, as in Envoy. A match without a type only requires the value to be present.
+func matchString(sm *networking.StringMatch, v string, ignoreCase bool) bool {
+	switch m := sm.GetMatchType().(type) {
+	case *networking.StringMatch_Exact:
+		if ignoreCase {
+			return strings.EqualFold(m.Exact, v)
+		}
+		return m.Exact == v
+	case *networking.StringMatch_Prefix:
+		if ignoreCase {
+			return len(v) >= len(m.Prefix) && strings.EqualFold(m.Prefix, v[:len(m.Prefix)])
+		}
+		return strings.HasPrefix(v, m.Prefix)
+	case *networking.StringMatch_Regex:
+		re, err := regexp.Compile("^(?:" + m.Regex + ")$")
+		return err == nil && re.MatchString(v)
+	}
+	return true
+}
+
+// boundToGateway reports whether gateway is one of gateways, whose names are relative to
+// namespace. An empty list stands for the mesh gateway.
+func boundToGateway(gateway, namespace string, gateways []string) bool {
+	if len(gateways) == 0 {
+		return gateway == MeshGateway
+	}
+	for _, gw := range gateways {
+		if gw != MeshGateway && !strings.Contains(gw, "/") {
+			gw = namespace + "/" + gw
+		}
+		if gw == gateway {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,396 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package simulate evaluates VirtualService routing offline. Given a set of VirtualServices and a
+// synthetic request, it reports the HTTP route the proxy would select and the actions it would
+// apply, so that routing changes can be unit tested before they reach a mesh.
+//
+// The simulation follows the semantics of the routes istiod generates for sidecars and gateways:
+// VirtualServices are selected by host, gateway and exportTo, their HTTP routes are evaluated in
+// order, and the first route with a matching HTTPMatchRequest wins. Other configuration that
+// affects routing in a live mesh, such as Sidecar resources and EnvoyFilters, is not considered.
+package simulate
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/api/networking/v1alpha3/host"
+)
+
+// DefaultDomainSuffix is the cluster domain suffix used to expand short host names.
+const DefaultDomainSuffix = host.DefaultDomainSuffix
+
+// MeshGateway is the name of the reserved gateway that stands for all the sidecars in the mesh.
+const MeshGateway = "mesh"
+
+// ErrNoRoute is returned when no VirtualService route matches a request. In a live mesh, such a
+// request is either sent to the destination host unchanged or rejected with a 404, depending on
+// whether a VirtualService was selected for its host.
+var ErrNoRoute = errors.New("no matching route")
+
+// VirtualService is a VirtualService along with its identity.
+type VirtualService struct {
+	Name      string
+	Namespace string
+	Spec      *networking.VirtualService
+}
+
+func (vs *VirtualService) String() string {
+	return vs.Namespace + "/" + vs.Name
+}
+
+// Request is a synthetic HTTP request.
+type Request struct {
+	// Authority is the value of the :authority (Host) header, which may include a port.
+	Authority string
+	// Path is the request path, which may include a query string.
+	Path string
+	// Method is the request method. It defaults to GET.
+	Method string
+	// Scheme is the request scheme. It defaults to http.
+	Scheme string
+	// Headers are the request headers. Header names are case insensitive.
+	Headers map[string]string
+	// QueryParams are the query parameters, in addition to those in Path.
+	QueryParams map[string]string
+	// SourceLabels are the labels of the client workload.
+	SourceLabels map[string]string
+	// SourceNamespace is the namespace of the client workload. VirtualServices are only visible
+	// to the client when exported to this namespace.
+	SourceNamespace string
+	// Gateway is the gateway receiving the request, as "namespace/name", or "mesh" (the default)
+	// for a request sent by a sidecar.
+	Gateway string
+	// Port is the port the request is sent to, which is matched against HTTPMatchRequest.port.
+	Port uint32
+}
+
+// Destination is a destination of the selected route.
+type Destination struct {
+	*networking.HTTPRouteDestination
+	// Probability is the fraction of requests sent to the destination, between 0 and 1.
+	Probability float64
+}
+
+// Result describes how a request is routed.
+type Result struct {
+	// VirtualService is the VirtualService selected for the request host.
+	VirtualService *VirtualService
+	// Delegates are the delegate VirtualServices followed from VirtualService, in order. Route
+	// was taken from the last one, if any.
+	Delegates []*VirtualService
+	// Route is the selected route. For delegates, it is the route of the delegate with the
+	// settings it inherits from the routes delegating to it filled in.
+	Route *networking.HTTPRoute
+	// RouteIndex is the index of Route within the http routes of the VirtualService it was
+	// taken from: the last of Delegates, or VirtualService.
+	RouteIndex int
+	// Match is the clause of Route that matched the request, or nil if Route matches all requests.
+	Match *networking.HTTPMatchRequest
+
+	// Destinations are the destinations the request may be sent to. It is empty for redirects
+	// and direct responses.
+	Destinations []Destination
+	// Rewrite, Redirect and DirectResponse are the corresponding actions of Route.
+	Rewrite        *networking.HTTPRewrite
+	Redirect       *networking.HTTPRedirect
+	DirectResponse *networking.HTTPDirectResponse
+	// Headers are the header operations of Route. Destinations may set further operations.
+	Headers *networking.Headers
+	// Retries is the retry policy of Route. Nil means the proxy default policy applies.
+	Retries *networking.HTTPRetry
+	// Timeout is the timeout of Route. Nil means requests do not time out.
+	Timeout *durationpb.Duration
+
+	// Authority and Path are the authority and path of the request after Rewrite is applied.
+	Authority string
+	Path      string
+}
+
+// Simulator routes requests through a set of VirtualServices.
+type Simulator struct {
+	// DomainSuffix is the cluster domain suffix. It defaults to DefaultDomainSuffix.
+	DomainSuffix string
+
+	virtualServices []*VirtualService
+}
+
+// New returns a Simulator routing through vss. When VirtualServices for the same host are bound
+// to the same gateway, their routes are evaluated in the order of vss. On the mesh gateway, only
+// the first of them is used.
+func New(vss ...*VirtualService) *Simulator {
+	return &Simulator{virtualServices: vss}
+}
+
+func (s *Simulator) domainSuffix() string {
+	if s.DomainSuffix == "" {
+		return DefaultDomainSuffix
+	}
+	return s.DomainSuffix
+}
+
+// request is a Request normalized for matching.
+type request struct {
+	*Request
+	host    string
+	path    string
+	query   map[string]string
+	headers map[string]string
+	gateway string
+}
+
+func normalize(r *Request) *request {
+	req := &request{
+		Request: r,
+		host:    strings.ToLower(r.Authority),
+		path:    r.Path,
+		query:   map[string]string{},
+		headers: map[string]string{},
+		gateway: r.Gateway,
+	}
+	if h, _, f := strings.Cut(req.host, ":"); f && !strings.HasPrefix(req.host, "[") {
+		req.host = h
+	}
+	if p, q, f := strings.Cut(r.Path, "?"); f {
+		req.path = p
+		values, _ := url.ParseQuery(q)
+		for k, v := range values {
+			req.query[k] = v[0]
+		}
+	}
+	if req.path == "" {
+		req.path = "/"
+	}
+	for k, v := range r.QueryParams {
+		req.query[k] = v
+	}
+	for k, v := range r.Headers {
+		req.headers[strings.ToLower(k)] = v
+	}
+	if req.gateway == "" {
+		req.gateway = MeshGateway
+	}
+	return req
+}
+
+func (r *request) method() string {
+	if r.Method == "" {
+		return "GET"
+	}
+	return r.Method
+}
+
+func (r *request) scheme() string {
+	if r.Scheme == "" {
+		return "http"
+	}
+	return r.Scheme
+}
+
+// Route returns how req is routed. It returns an error wrapping ErrNoRoute if no route matches.
+func (s *Simulator) Route(req *Request) (*Result, error) {
+	r := normalize(req)
+	vss := s.selectVirtualServices(r)
+	if len(vss) == 0 {
+		return nil, fmt.Errorf("%w: no VirtualService for host %q on gateway %q", ErrNoRoute, r.host, r.gateway)
+	}
+	for _, vs := range vss {
+		res, err := s.routeVirtualService(r, []*VirtualService{vs}, nil)
+		if err != nil || res != nil {
+			return res, err
+		}
+	}
+	return nil, fmt.Errorf("%w: no route of %v matches the request", ErrNoRoute, vss[0])
+}
+
+// routeVirtualService evaluates the routes of the last VirtualService of chain, which starts
+// with the root VirtualService followed by the delegates leading to it. Settings inherited from
+// the delegating routes are carried in parent.
+func (s *Simulator) routeVirtualService(r *request, chain []*VirtualService, parent *networking.HTTPRoute) (*Result, error) {
+	root, vs := chain[0], chain[len(chain)-1]
+	// Delegates are bound to the gateways of their root.
+	gateways := root.Spec.GetGateways()
+	for i, route := range vs.Spec.GetHttp() {
+		match, ok := matchRoute(r, route, root.Namespace, gateways)
+		if !ok {
+			continue
+		}
+		if parent != nil {
+			route = inherit(route, parent)
+		}
+		if route.Delegate == nil {
+			res := s.result(r, root, i, route, match)
+			res.Delegates = chain[1:]
+			return res, nil
+		}
+		delegate, err := s.delegate(chain, route.Delegate)
+		if err != nil {
+			return nil, err
+		}
+		res, err := s.routeVirtualService(r, append(slices.Clip(chain), delegate), route)
+		if err != nil || res != nil {
+			return res, err
+		}
+		// The delegate routes replace the delegating route, so a request matching none of them
+		// goes on to the next route.
+	}
+	return nil, nil
+}
+
+// delegate returns the VirtualService d refers to from the last VirtualService of chain.
+func (s *Simulator) delegate(chain []*VirtualService, d *networking.Delegate) (*VirtualService, error) {
+	vs := chain[len(chain)-1]
+	ns := d.Namespace
+	if ns == "" {
+		ns = vs.Namespace
+	}
+	for _, c := range s.virtualServices {
+		if c.Name != d.Name || c.Namespace != ns {
+			continue
+		}
+		if len(c.Spec.GetHosts()) > 0 {
+			return nil, fmt.Errorf("delegate %v of %v must not set hosts", c, vs)
+		}
+		if slices.Contains(chain, c) {
+			return nil, fmt.Errorf("delegation loop through %v", c)
+		}
+		return c, nil
+	}
+	return nil, fmt.Errorf("delegate %s/%s of %v not found", ns, d.Name, vs)
+}
+
+// inherit returns route with the settings it does not set taken from parent, the route delegating
+// to it, as istiod does when merging delegate VirtualServices.
+func inherit(route, parent *networking.HTTPRoute) *networking.HTTPRoute {
+	route = proto.Clone(route).(*networking.HTTPRoute)
+	if route.Rewrite == nil {
+		route.Rewrite = parent.Rewrite
+	}
+	if route.Timeout == nil {
+		route.Timeout = parent.Timeout
+	}
+	if route.Retries == nil {
+		route.Retries = parent.Retries
+	}
+	if route.Fault == nil {
+		route.Fault = parent.Fault
+	}
+	if route.Mirror == nil && len(route.Mirrors) == 0 {
+		route.Mirror = parent.Mirror
+		route.Mirrors = parent.Mirrors
+	}
+	if route.MirrorPercentage == nil {
+		route.MirrorPercentage = parent.MirrorPercentage
+	}
+	if route.CorsPolicy == nil {
+		route.CorsPolicy = parent.CorsPolicy
+	}
+	if route.Headers == nil {
+		route.Headers = parent.Headers
+	}
+	return route
+}
+
+func (s *Simulator) result(r *request, vs *VirtualService, i int, route *networking.HTTPRoute, match *networking.HTTPMatchRequest) *Result {
+	res := &Result{
+		VirtualService: vs,
+		Route:          route,
+		RouteIndex:     i,
+		Match:          match,
+		Rewrite:        route.Rewrite,
+		Redirect:       route.Redirect,
+		DirectResponse: route.DirectResponse,
+		Headers:        route.Headers,
+		Retries:        route.Retries,
+		Timeout:        route.Timeout,
+		Authority:      r.Authority,
+		Path:           r.Path,
+	}
+	if route.Redirect == nil && route.DirectResponse == nil {
+		res.Destinations = destinations(route.Route)
+	}
+	if rw := route.Rewrite; rw != nil {
+		if rw.Authority != "" {
+			res.Authority = rw.Authority
+		}
+		res.Path = rewritePath(r, match, rw)
+	}
+	return res
+}
+
+// destinations returns the destinations of a route with the probability of each. A single
+// destination receives all requests whatever its weight.
+func destinations(routes []*networking.HTTPRouteDestination) []Destination {
+	var total int64
+	for _, d := range routes {
+		total += int64(d.Weight)
+	}
+	out := make([]Destination, 0, len(routes))
+	for _, d := range routes {
+		p := 0.0
+		switch {
+		case len(routes) == 1:
+			p = 1
+		case total > 0:
+			p = float64(d.Weight) / float64(total)
+		}
+		out = append(out, Destination{HTTPRouteDestination: d, Probability: p})
+	}
+	return out
+}
+
+// envoySubstitution matches the \N references Envoy uses in regex rewrites.
+var envoySubstitution = regexp.MustCompile(`\\([0-9])`)
+
+// rewritePath applies rw to the path of r. As in Envoy, a URI rewrite replaces the matched prefix
+// for prefix matches, and the whole path for other matches.
+func rewritePath(r *request, match *networking.HTTPMatchRequest, rw *networking.HTTPRewrite) string {
+	path, query, hasQuery := strings.Cut(r.Path, "?")
+	switch {
+	case rw.UriRegexRewrite != nil:
+		re, err := regexp.Compile(rw.UriRegexRewrite.Match)
+		if err != nil {
+			return r.Path
+		}
+		path = re.ReplaceAllString(path, envoySubstitution.ReplaceAllString(rw.UriRegexRewrite.Rewrite, "$${$1}"))
+	case rw.Uri != "":
+		prefix := "/"
+		if m := match.GetUri(); m != nil {
+			prefix = ""
+			if p, ok := m.MatchType.(*networking.StringMatch_Prefix); ok {
+				prefix = p.Prefix
+			}
+		}
+		if prefix == "" || len(prefix) > len(path) {
+			path = rw.Uri
+		} else {
+			path = rw.Uri + path[len(prefix):]
+		}
+	default:
+		return r.Path
+	}
+	if hasQuery {
+		path += "?" + query
+	}
+	return path
+}
@@ -0,0 +1,315 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package simulate
+
+import (
+	"errors"
+	"testing"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	networking "istio.io/api/networking/v1alpha3"
+)
+
+func prefix(p string) *networking.StringMatch {
+	return &networking.StringMatch{MatchType: &networking.StringMatch_Prefix{Prefix: p}}
+}
+
+func exact(e string) *networking.StringMatch {
+	return &networking.StringMatch{MatchType: &networking.StringMatch_Exact{Exact: e}}
+}
+
+func regex(r string) *networking.StringMatch {
+	return &networking.StringMatch{MatchType: &networking.StringMatch_Regex{Regex: r}}
+}
+
+func route(host string, weight int32) *networking.HTTPRouteDestination {
+	return &networking.HTTPRouteDestination{Destination: &networking.Destination{Host: host}, Weight: weight}
+}
+
+func reviews() *VirtualService {
+	return &VirtualService{Name: "reviews", Namespace: "default", Spec: &networking.VirtualService{
+		Hosts: []string{"reviews"},
+		Http: []*networking.HTTPRoute{
+			{
+				Name: "jason",
+				Match: []*networking.HTTPMatchRequest{{
+					Headers: map[string]*networking.StringMatch{"end-user": exact("jason")},
+				}},
+				Route: []*networking.HTTPRouteDestination{route("reviews", 0)},
+			},
+			{
+				Name: "api",
+				Match: []*networking.HTTPMatchRequest{
+					{Uri: exact("/health"), Method: exact("GET")},
+					{Uri: prefix("/API/"), IgnoreUriCase: true, WithoutHeaders: map[string]*networking.StringMatch{"x-debug": {}}},
+				},
+				Rewrite: &networking.HTTPRewrite{Uri: "/v2/"},
+				Retries: &networking.HTTPRetry{Attempts: 3},
+				Timeout: durationpb.New(5e9),
+				Route:   []*networking.HTTPRouteDestination{route("reviews-v2", 75), route("reviews-v3", 25)},
+			},
+			{
+				Name:     "legacy",
+				Match:    []*networking.HTTPMatchRequest{{Uri: regex("/legacy/[0-9]+"), SourceNamespace: "frontend"}},
+				Redirect: &networking.HTTPRedirect{Uri: "/v2/"},
+			},
+			{
+				Name:  "default",
+				Route: []*networking.HTTPRouteDestination{route("reviews", 0)},
+			},
+		},
+	}}
+}
+
+func TestRoute(t *testing.T) {
+	sim := New(reviews())
+	cases := []struct {
+		name  string
+		req   *Request
+		route string
+		path  string
+	}{
+		{
+			name:  "header match",
+			req:   &Request{Authority: "reviews:9080", Path: "/", Headers: map[string]string{"End-User": "jason"}, SourceNamespace: "default"},
+			route: "jason",
+			path:  "/",
+		},
+		{
+			name:  "exact uri and method",
+			req:   &Request{Authority: "reviews.default.svc.cluster.local", Path: "/health"},
+			route: "api",
+			path:  "/v2/",
+		},
+		{
+			name:  "method mismatch",
+			req:   &Request{Authority: "reviews.default.svc.cluster.local", Path: "/health", Method: "POST"},
+			route: "default",
+			path:  "/health",
+		},
+		{
+			name:  "prefix ignoring case",
+			req:   &Request{Authority: "reviews.default", Path: "/api/ratings?user=1"},
+			route: "api",
+			path:  "/v2/ratings?user=1",
+		},
+		{
+			name:  "without headers",
+			req:   &Request{Authority: "reviews.default", Path: "/api/ratings", Headers: map[string]string{"x-debug": "1"}},
+			route: "default",
+			path:  "/api/ratings",
+		},
+		{
+			name:  "regex and source namespace",
+			req:   &Request{Authority: "reviews.default.svc", Path: "/legacy/42", SourceNamespace: "frontend"},
+			route: "legacy",
+			path:  "/legacy/42",
+		},
+		{
+			name:  "regex must match the whole path",
+			req:   &Request{Authority: "reviews.default.svc", Path: "/legacy/42/x", SourceNamespace: "frontend"},
+			route: "default",
+			path:  "/legacy/42/x",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := sim.Route(tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Route.Name != tt.route {
+				t.Fatalf("got route %q, want %q", res.Route.Name, tt.route)
+			}
+			if res.Path != tt.path {
+				t.Fatalf("got path %q, want %q", res.Path, tt.path)
+			}
+		})
+	}
+}
+
+func TestRouteResult(t *testing.T) {
+	res, err := New(reviews()).Route(&Request{Authority: "reviews.default", Path: "/API/x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.VirtualService.Name != "reviews" || res.RouteIndex != 1 || res.Match != res.Route.Match[1] {
+		t.Fatalf("unexpected match: %v[%d] %v", res.VirtualService, res.RouteIndex, res.Match)
+	}
+	if len(res.Destinations) != 2 || res.Destinations[0].Probability != 0.75 || res.Destinations[1].Probability != 0.25 {
+		t.Fatalf("unexpected destinations: %v", res.Destinations)
+	}
+	if res.Retries.GetAttempts() != 3 || res.Timeout.AsDuration().Seconds() != 5 || res.Rewrite.GetUri() != "/v2/" {
+		t.Fatalf("unexpected route actions: %v", res)
+	}
+
+	res, err = New(reviews()).Route(&Request{Authority: "reviews.default", Path: "/legacy/1", SourceNamespace: "frontend"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Redirect.GetUri() != "/v2/" || len(res.Destinations) != 0 {
+		t.Fatalf("expected a redirect without destinations, got %v", res)
+	}
+}
+
+func TestRouteHostSelection(t *testing.T) {
+	wildcard := &VirtualService{Name: "wildcard", Namespace: "istio-system", Spec: &networking.VirtualService{
+		Hosts:    []string{"*.example.com"},
+		Gateways: []string{"ingress"},
+		Http:     []*networking.HTTPRoute{{Name: "wildcard", Route: []*networking.HTTPRouteDestination{route("web", 0)}}},
+	}}
+	specific := &VirtualService{Name: "api", Namespace: "api", Spec: &networking.VirtualService{
+		Hosts:    []string{"api.example.com"},
+		Gateways: []string{"istio-system/ingress"},
+		ExportTo: []string{"istio-system"},
+		Http: []*networking.HTTPRoute{{
+			Name:  "api",
+			Match: []*networking.HTTPMatchRequest{{Port: 443}},
+			Route: []*networking.HTTPRouteDestination{route("api", 0)},
+		}},
+	}}
+	private := &VirtualService{Name: "private", Namespace: "api", Spec: &networking.VirtualService{
+		Hosts:    []string{"api.example.com"},
+		Gateways: []string{"istio-system/ingress"},
+		ExportTo: []string{"."},
+		Http:     []*networking.HTTPRoute{{Name: "private"}},
+	}}
+	sim := New(wildcard, private, specific)
+
+	cases := []struct {
+		name  string
+		req   *Request
+		route string
+	}{
+		{"exact host wins", &Request{Authority: "api.example.com", Gateway: "istio-system/ingress", Port: 443}, "api"},
+		{"wildcard host", &Request{Authority: "www.example.com", Gateway: "istio-system/ingress"}, "wildcard"},
+		{"no route for host", &Request{Authority: "api.example.com", Gateway: "istio-system/ingress", Port: 80}, ""},
+		{"not bound to mesh", &Request{Authority: "www.example.com"}, ""},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := sim.Route(tt.req)
+			if tt.route == "" {
+				if !errors.Is(err, ErrNoRoute) {
+					t.Fatalf("expected ErrNoRoute, got %v, %v", res, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Route.Name != tt.route {
+				t.Fatalf("got route %q, want %q", res.Route.Name, tt.route)
+			}
+		})
+	}
+}
+
+func TestRouteSameHost(t *testing.T) {
+	first := &VirtualService{Name: "first", Namespace: "default", Spec: &networking.VirtualService{
+		Hosts:    []string{"reviews"},
+		Gateways: []string{MeshGateway, "istio-system/ingress"},
+		Http: []*networking.HTTPRoute{{
+			Name:  "api",
+			Match: []*networking.HTTPMatchRequest{{Uri: prefix("/api")}},
+			Route: []*networking.HTTPRouteDestination{route("reviews-api", 0)},
+		}},
+	}}
+	second := &VirtualService{Name: "second", Namespace: "default", Spec: &networking.VirtualService{
+		Hosts:    []string{"reviews"},
+		Gateways: []string{MeshGateway, "istio-system/ingress"},
+		Http:     []*networking.HTTPRoute{{Name: "default", Route: []*networking.HTTPRouteDestination{route("reviews", 0)}}},
+	}}
+	sim := New(first, second)
+
+	// Gateways merge the routes of both VirtualServices.
+	res, err := sim.Route(&Request{Authority: "reviews.default.svc.cluster.local", Path: "/", Gateway: "istio-system/ingress"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Route.Name != "default" {
+		t.Fatalf("got route %q, want %q", res.Route.Name, "default")
+	}
+	// Sidecars only use the first one.
+	if res, err := sim.Route(&Request{Authority: "reviews", Path: "/", SourceNamespace: "default"}); !errors.Is(err, ErrNoRoute) {
+		t.Fatalf("expected ErrNoRoute, got %v, %v", res, err)
+	}
+	res, err = sim.Route(&Request{Authority: "reviews", Path: "/api/ratings", SourceNamespace: "default"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Route.Name != "api" {
+		t.Fatalf("got route %q, want %q", res.Route.Name, "api")
+	}
+}
+
+func TestRouteDelegate(t *testing.T) {
+	root := &VirtualService{Name: "root", Namespace: "istio-system", Spec: &networking.VirtualService{
+		Hosts: []string{"bookinfo.example.com"},
+		Http: []*networking.HTTPRoute{
+			{
+				Match:    []*networking.HTTPMatchRequest{{Uri: prefix("/reviews")}},
+				Delegate: &networking.Delegate{Name: "reviews", Namespace: "bookinfo"},
+				Timeout:  durationpb.New(1e9),
+				Headers: &networking.Headers{Request: &networking.Headers_HeaderOperations{
+					Set: map[string]string{"x-root": "true"},
+				}},
+			},
+			{Name: "fallback", Route: []*networking.HTTPRouteDestination{route("productpage", 0)}},
+		},
+	}}
+	delegate := &VirtualService{Name: "reviews", Namespace: "bookinfo", Spec: &networking.VirtualService{
+		Http: []*networking.HTTPRoute{
+			{
+				Name:    "reviews-v2",
+				Match:   []*networking.HTTPMatchRequest{{Uri: prefix("/reviews/v2")}},
+				Timeout: durationpb.New(2e9),
+				Route:   []*networking.HTTPRouteDestination{route("reviews-v2", 0)},
+			},
+		},
+	}}
+	sim := New(root, delegate)
+
+	res, err := sim.Route(&Request{Authority: "bookinfo.example.com", Path: "/reviews/v2/1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Route.Name != "reviews-v2" || len(res.Delegates) != 1 || res.Delegates[0] != delegate {
+		t.Fatalf("expected the delegate route, got %v via %v", res.Route.Name, res.Delegates)
+	}
+	if res.Timeout.AsDuration().Seconds() != 2 || res.Headers.GetRequest().GetSet()["x-root"] != "true" {
+		t.Fatalf("expected the delegate timeout and the root headers, got %v %v", res.Timeout, res.Headers)
+	}
+	if delegate.Spec.Http[0].Headers != nil {
+		t.Fatal("delegate VirtualService was modified")
+	}
+
+	// Requests matching none of the delegate routes fall through to the next root route.
+	res, err = sim.Route(&Request{Authority: "bookinfo.example.com", Path: "/reviews/v1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Route.Name != "fallback" {
+		t.Fatalf("got route %q, want fallback", res.Route.Name)
+	}
+
+	// Delegates may not loop back.
+	delegate.Spec.Http[0].Delegate = &networking.Delegate{Name: "reviews"}
+	delegate.Spec.Http[0].Route = nil
+	if _, err := sim.Route(&Request{Authority: "bookinfo.example.com", Path: "/reviews/v2"}); err == nil {
+		t.Fatal("expected a delegation loop error")
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trafficpolicy
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	networking "istio.io/api/networking/v1alpha3"
+)
+
+// portLevelSettings and port are the fields of TrafficPolicy and PortTrafficPolicy that select
+// ports rather than configure traffic.
+const (
+	portLevelSettingsField protoreflect.Name = "port_level_settings"
+	portField              protoreflect.Name = "port"
+)
+
+// setting is the value of a traffic policy field along with its source.
+type setting struct {
+	value  protoreflect.Message
+	source Source
+}
+
+// apply sets the fields of the traffic policy of c for port and subset in res, and returns the
+// definition of subset if c has one.
+func (c *consolidated) apply(res *Result, port uint32, subset string) (*networking.Subset, bool) {
+	settings := map[protoreflect.Name]setting{}
+	for _, dr := range c.rules {
+		if tp := dr.Spec.TrafficPolicy; tp != nil {
+			overlay(settings, tp, dr, "spec.trafficPolicy", port)
+			break
+		}
+	}
+	var def *networking.Subset
+	if subset != "" {
+	rules:
+		for _, dr := range c.rules {
+			for i, s := range dr.Spec.Subsets {
+				if s.GetName() != subset {
+					continue
+				}
+				def = s
+				if s.TrafficPolicy != nil {
+					overlay(settings, s.TrafficPolicy, dr, fmt.Sprintf("spec.subsets[%d].trafficPolicy", i), port)
+				}
+				break rules
+			}
+		}
+	}
+
+	tp := res.TrafficPolicy.ProtoReflect()
+	fields := tp.Descriptor().Fields()
+	for name, s := range settings {
+		fd := fields.ByName(name)
+		tp.Set(fd, protoreflect.ValueOfMessage(proto.Clone(s.value.Interface()).ProtoReflect()))
+		res.Provenance[fd.JSONName()] = s.source
+	}
+	return def, def != nil
+}
+
+// overlay applies the traffic policy tp of dr, at path, to settings: the fields set in tp
+// override the current settings, and the port level settings of port, if any, then replace
+// all the settings they can configure. As documented for PortTrafficPolicy, fields omitted at
+// the port level revert to their defaults rather than being inherited.
+func overlay(settings map[protoreflect.Name]setting, tp *networking.TrafficPolicy, dr *DestinationRule, path string, port uint32) {
+	tp.ProtoReflect().Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.Name() != portLevelSettingsField {
+			settings[fd.Name()] = setting{v.Message(), Source{dr, path + "." + fd.JSONName()}}
+		}
+		return true
+	})
+	if port == 0 {
+		return
+	}
+	for i, pl := range tp.PortLevelSettings {
+		if pl.GetPort().GetNumber() != port {
+			continue
+		}
+		m := pl.ProtoReflect()
+		fields := m.Descriptor().Fields()
+		for j := 0; j < fields.Len(); j++ {
+			fd := fields.Get(j)
+			if fd.Name() == portField {
+				continue
+			}
+			if !m.Has(fd) {
+				delete(settings, fd.Name())
+				continue
+			}
+			settings[fd.Name()] = setting{m.Get(fd).Message(), Source{dr, fmt.Sprintf("%s.portLevelSettings[%d].%s", path, i, fd.JSONName())}}
+		}
+		return
+	}
+}
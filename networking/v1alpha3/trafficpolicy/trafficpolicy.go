@@ -0,0 +1,273 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package trafficpolicy computes the effective traffic policy of a destination, reproducing how
+// istiod selects and merges DestinationRules for a client.
+//
+// For a client namespace and host, istiod looks up the DestinationRules of the client namespace,
+// then those the namespace of the service exports to the client, then those the root namespace
+// exports to it, and uses the first namespace with a rule for the host. Within a namespace, the
+// rule with the most specific host applies, and rules for the same host are merged. The traffic
+// policy of the rule is then specialized for the port and the subset of the destination.
+package trafficpolicy
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/api/networking/v1alpha3/host"
+)
+
+const (
+	// DefaultRootNamespace is the default root namespace of the mesh.
+	DefaultRootNamespace = "istio-system"
+	// DefaultDomainSuffix is the cluster domain suffix used to expand short host names.
+	DefaultDomainSuffix = host.DefaultDomainSuffix
+)
+
+// ErrUnknownSubset is returned when the subset of a destination is not defined by the
+// DestinationRules that apply to it. In a live mesh, requests to such a subset fail.
+var ErrUnknownSubset = errors.New("unknown subset")
+
+// DestinationRule is a DestinationRule along with its identity.
+type DestinationRule struct {
+	Name      string
+	Namespace string
+	Spec      *networking.DestinationRule
+}
+
+func (dr *DestinationRule) String() string {
+	return dr.Namespace + "/" + dr.Name
+}
+
+// Destination identifies the traffic a policy is resolved for.
+type Destination struct {
+	// Namespace is the namespace of the client workload.
+	Namespace string
+	// Labels are the labels of the client workload, which DestinationRule workload selectors are
+	// matched against.
+	Labels map[string]string
+	// Host is the destination host. Short names are relative to ServiceNamespace.
+	Host string
+	// ServiceNamespace is the namespace of the destination service. It defaults to the namespace
+	// of the Kubernetes service Host is the FQDN of, if any.
+	ServiceNamespace string
+	// Port is the destination port. Zero selects no port level settings.
+	Port uint32
+	// Subset is the destination subset, if any.
+	Subset string
+}
+
+// Source is the origin of a field of an effective traffic policy.
+type Source struct {
+	// DestinationRule is the DestinationRule the field was taken from.
+	DestinationRule *DestinationRule
+	// Path is the path of the field within DestinationRule, such as
+	// "spec.subsets[0].trafficPolicy.loadBalancer".
+	Path string
+}
+
+func (s Source) String() string {
+	return s.DestinationRule.String() + ": " + s.Path
+}
+
+// Result is the effective traffic policy of a destination.
+type Result struct {
+	// TrafficPolicy is the effective traffic policy. It has no port level settings, as those
+	// for the destination port are applied. It is empty when no DestinationRule applies.
+	TrafficPolicy *networking.TrafficPolicy
+	// Provenance maps the JSON name of each field set in TrafficPolicy, such as "loadBalancer",
+	// to its source.
+	Provenance map[string]Source
+	// Subset is the definition of the destination subset, or nil without a subset.
+	Subset *networking.Subset
+	// DestinationRules are the DestinationRules that apply to the destination, in order of
+	// precedence.
+	DestinationRules []*DestinationRule
+}
+
+// Resolver resolves the effective traffic policy of destinations.
+type Resolver struct {
+	// RootNamespace is the root namespace of the mesh. It defaults to DefaultRootNamespace.
+	RootNamespace string
+	// DomainSuffix is the cluster domain suffix. It defaults to DefaultDomainSuffix.
+	DomainSuffix string
+	// Inheritance merges the rules of all the namespaces that have one for the destination,
+	// the client namespace taking precedence over the service namespace, and the service
+	// namespace over the root namespace, instead of only using the first one. This mirrors
+	// istiod with PILOT_ENABLE_DESTINATION_RULE_INHERITANCE.
+	Inheritance bool
+}
+
+// Resolve returns the effective traffic policy of d with the default Resolver.
+func Resolve(drs []*DestinationRule, d Destination) (*Result, error) {
+	return (&Resolver{}).Resolve(drs, d)
+}
+
+// Resolve returns the effective traffic policy of d given all the DestinationRules of the mesh.
+// DestinationRules for the same host in the same namespace are merged in the order of drs,
+// which should be their creation order, oldest first: the first one with a top level traffic
+// policy provides it, and the first definition of each subset wins.
+func (r *Resolver) Resolve(drs []*DestinationRule, d Destination) (*Result, error) {
+	h := host.Name(strings.ToLower(d.Host))
+	svcNamespace := d.ServiceNamespace
+	if svcNamespace == "" {
+		_, svcNamespace, _ = host.KubernetesService(h, r.domainSuffix())
+	}
+	if svcNamespace != "" {
+		h = host.Expand(h, svcNamespace, r.domainSuffix())
+	}
+
+	// The namespaces to look up rules in, in order of precedence. Rules in the client namespace
+	// apply whatever their exportTo.
+	var rules []*consolidated
+	seen := map[string]bool{}
+	for i, ns := range []string{d.Namespace, svcNamespace, r.rootNamespace()} {
+		if ns == "" || seen[ns] {
+			continue
+		}
+		seen[ns] = true
+		c := r.selectRule(drs, ns, h, d, i == 0)
+		if c == nil {
+			continue
+		}
+		rules = append(rules, c)
+		if !r.Inheritance {
+			break
+		}
+	}
+
+	res := &Result{TrafficPolicy: &networking.TrafficPolicy{}, Provenance: map[string]Source{}}
+	found := d.Subset == ""
+	// Apply the rules from the lowest precedence up, so that higher precedence fields override.
+	for i := len(rules) - 1; i >= 0; i-- {
+		c := rules[i]
+		res.DestinationRules = append(slices.Clone(c.rules), res.DestinationRules...)
+		subset, ok := c.apply(res, d.Port, d.Subset)
+		if ok {
+			found = true
+			res.Subset = subset
+		}
+	}
+	if !found {
+		return res, fmt.Errorf("%w %q for host %s", ErrUnknownSubset, d.Subset, h)
+	}
+	return res, nil
+}
+
+func (r *Resolver) rootNamespace() string {
+	if r.RootNamespace == "" {
+		return DefaultRootNamespace
+	}
+	return r.RootNamespace
+}
+
+func (r *Resolver) domainSuffix() string {
+	if r.DomainSuffix == "" {
+		return DefaultDomainSuffix
+	}
+	return r.DomainSuffix
+}
+
+// consolidated is the merge of the DestinationRules of a namespace for the same host, in order.
+type consolidated struct {
+	rules []*DestinationRule
+}
+
+// selectRule returns the rules of namespace that apply to the host h for d. In the client
+// namespace, all the rules apply, and a rule whose workload selector matches the client takes
+// precedence over rules without selectors. In other namespaces, only rules exported to the client
+// namespace and without workload selectors apply. As in istiod, the most specific host is
+// selected before exportTo is checked, so a more specific rule hidden from the client hides the
+// others.
+func (r *Resolver) selectRule(drs []*DestinationRule, namespace string, h host.Name, d Destination, local bool) *consolidated {
+	var candidates []*DestinationRule
+	for _, dr := range drs {
+		if dr.Namespace != namespace || dr.Spec == nil {
+			continue
+		}
+		if sel := dr.Spec.GetWorkloadSelector(); sel != nil && (!local || !selects(sel.GetMatchLabels(), d.Labels)) {
+			continue
+		}
+		if !local && !exported(dr) {
+			continue
+		}
+		candidates = append(candidates, dr)
+	}
+	hosts := make([]host.Name, len(candidates))
+	for i, dr := range candidates {
+		hosts[i] = host.Expand(host.Name(dr.Spec.Host), dr.Namespace, r.domainSuffix())
+	}
+	i := host.MostSpecific(h, hosts)
+	if i < 0 {
+		return nil
+	}
+	best := hosts[i]
+	var selected, withSelector []*DestinationRule
+	for i, dr := range candidates {
+		if hosts[i] != best {
+			continue
+		}
+		if dr.Spec.WorkloadSelector != nil {
+			withSelector = append(withSelector, dr)
+		} else {
+			selected = append(selected, dr)
+		}
+	}
+	if len(withSelector) > 0 {
+		// Rules with different selectors are not merged: the first matching one applies.
+		selected = []*DestinationRule{withSelector[0]}
+		for _, dr := range withSelector[1:] {
+			if sameLabels(dr.Spec.WorkloadSelector.GetMatchLabels(), withSelector[0].Spec.WorkloadSelector.GetMatchLabels()) {
+				selected = append(selected, dr)
+			}
+		}
+	}
+	if !local {
+		var visible []*DestinationRule
+		for _, dr := range selected {
+			if host.Visible(dr.Spec.ExportTo, dr.Namespace, d.Namespace) {
+				visible = append(visible, dr)
+			}
+		}
+		selected = visible
+	}
+	if len(selected) == 0 {
+		return nil
+	}
+	return &consolidated{rules: selected}
+}
+
+// exported reports whether dr is visible outside of its namespace.
+func exported(dr *DestinationRule) bool {
+	e := dr.Spec.ExportTo
+	return !(len(e) == 1 && (e[0] == host.SameNamespace || e[0] == dr.Namespace))
+}
+
+// selects reports whether the selector labels sel select a workload with labels.
+func selects(sel, labels map[string]string) bool {
+	for k, v := range sel {
+		if l, f := labels[k]; !f || l != v {
+			return false
+		}
+	}
+	return true
+}
+
+func sameLabels(a, b map[string]string) bool {
+	return len(a) == len(b) && selects(a, b)
+}
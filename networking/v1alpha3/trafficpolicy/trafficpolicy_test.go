@@ -0,0 +1,295 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trafficpolicy
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/api/type/v1beta1"
+)
+
+func lb(s networking.LoadBalancerSettings_SimpleLB) *networking.LoadBalancerSettings {
+	return &networking.LoadBalancerSettings{LbPolicy: &networking.LoadBalancerSettings_Simple{Simple: s}}
+}
+
+func tls(m networking.ClientTLSSettings_TLSmode) *networking.ClientTLSSettings {
+	return &networking.ClientTLSSettings{Mode: m}
+}
+
+func outlier(n uint32) *networking.OutlierDetection {
+	return &networking.OutlierDetection{Consecutive_5XxErrors: wrapperspb.UInt32(n)}
+}
+
+func dr(ns, name, host string, tp *networking.TrafficPolicy, subsets ...*networking.Subset) *DestinationRule {
+	return &DestinationRule{Name: name, Namespace: ns, Spec: &networking.DestinationRule{Host: host, TrafficPolicy: tp, Subsets: subsets}}
+}
+
+func sources(res *Result) map[string]string {
+	out := map[string]string{}
+	for k, s := range res.Provenance {
+		out[k] = s.String()
+	}
+	return out
+}
+
+func TestResolve(t *testing.T) {
+	reviews := dr("default", "reviews", "reviews", &networking.TrafficPolicy{
+		LoadBalancer:     lb(networking.LoadBalancerSettings_LEAST_REQUEST),
+		OutlierDetection: outlier(5),
+		Tls:              tls(networking.ClientTLSSettings_ISTIO_MUTUAL),
+		PortLevelSettings: []*networking.TrafficPolicy_PortTrafficPolicy{{
+			Port:         &networking.PortSelector{Number: 9080},
+			LoadBalancer: lb(networking.LoadBalancerSettings_RANDOM),
+		}},
+	}, &networking.Subset{
+		Name:   "v1",
+		Labels: map[string]string{"version": "v1"},
+		TrafficPolicy: &networking.TrafficPolicy{
+			OutlierDetection: outlier(10),
+			PortLevelSettings: []*networking.TrafficPolicy_PortTrafficPolicy{{
+				Port: &networking.PortSelector{Number: 9443},
+				Tls:  tls(networking.ClientTLSSettings_SIMPLE),
+			}},
+		},
+	})
+	drs := []*DestinationRule{reviews}
+
+	cases := []struct {
+		name string
+		d    Destination
+		want map[string]string
+	}{
+		{
+			name: "top level",
+			d:    Destination{Namespace: "default", Host: "reviews.default.svc.cluster.local", Port: 80},
+			want: map[string]string{
+				"loadBalancer":     "default/reviews: spec.trafficPolicy.loadBalancer",
+				"outlierDetection": "default/reviews: spec.trafficPolicy.outlierDetection",
+				"tls":              "default/reviews: spec.trafficPolicy.tls",
+			},
+		},
+		{
+			// Port level settings replace all the fields they can set.
+			name: "port level",
+			d:    Destination{Namespace: "default", Host: "reviews", ServiceNamespace: "default", Port: 9080},
+			want: map[string]string{
+				"loadBalancer": "default/reviews: spec.trafficPolicy.portLevelSettings[0].loadBalancer",
+			},
+		},
+		{
+			name: "subset",
+			d:    Destination{Namespace: "default", Host: "reviews.default.svc.cluster.local", Port: 80, Subset: "v1"},
+			want: map[string]string{
+				"loadBalancer":     "default/reviews: spec.trafficPolicy.loadBalancer",
+				"outlierDetection": "default/reviews: spec.subsets[0].trafficPolicy.outlierDetection",
+				"tls":              "default/reviews: spec.trafficPolicy.tls",
+			},
+		},
+		{
+			name: "subset port level",
+			d:    Destination{Namespace: "default", Host: "reviews.default.svc.cluster.local", Port: 9443, Subset: "v1"},
+			want: map[string]string{
+				"tls": "default/reviews: spec.subsets[0].trafficPolicy.portLevelSettings[0].tls",
+			},
+		},
+		{
+			name: "subset on top of port level",
+			d:    Destination{Namespace: "default", Host: "reviews.default.svc.cluster.local", Port: 9080, Subset: "v1"},
+			want: map[string]string{
+				"loadBalancer":     "default/reviews: spec.trafficPolicy.portLevelSettings[0].loadBalancer",
+				"outlierDetection": "default/reviews: spec.subsets[0].trafficPolicy.outlierDetection",
+			},
+		},
+		{
+			name: "other namespace",
+			d:    Destination{Namespace: "frontend", Host: "reviews.default.svc.cluster.local", Port: 80},
+			want: map[string]string{
+				"loadBalancer":     "default/reviews: spec.trafficPolicy.loadBalancer",
+				"outlierDetection": "default/reviews: spec.trafficPolicy.outlierDetection",
+				"tls":              "default/reviews: spec.trafficPolicy.tls",
+			},
+		},
+		{
+			name: "no rule",
+			d:    Destination{Namespace: "default", Host: "ratings.default.svc.cluster.local", Port: 80},
+			want: map[string]string{},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			res, err := Resolve(drs, c.d)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := sources(res); !maps.Equal(got, c.want) {
+				t.Errorf("got provenance %v, want %v", got, c.want)
+			}
+			tp := res.TrafficPolicy.ProtoReflect()
+			for k := range res.Provenance {
+				if !tp.Has(tp.Descriptor().Fields().ByJSONName(k)) {
+					t.Errorf("%s has a source but is not set", k)
+				}
+			}
+			if len(res.TrafficPolicy.PortLevelSettings) != 0 {
+				t.Errorf("port level settings must be applied, got %v", res.TrafficPolicy.PortLevelSettings)
+			}
+		})
+	}
+
+	res, _ := Resolve(drs, Destination{Namespace: "default", Host: "reviews.default.svc.cluster.local", Port: 9080, Subset: "v1"})
+	if !proto.Equal(res.TrafficPolicy.LoadBalancer, lb(networking.LoadBalancerSettings_RANDOM)) || res.Subset.Labels["version"] != "v1" {
+		t.Errorf("got %v, subset %v", res.TrafficPolicy, res.Subset)
+	}
+	// The result does not alias the DestinationRules.
+	res.TrafficPolicy.LoadBalancer.LbPolicy = nil
+	if reviews.Spec.TrafficPolicy.PortLevelSettings[0].LoadBalancer.LbPolicy == nil {
+		t.Errorf("the DestinationRule was modified")
+	}
+
+	if _, err := Resolve(drs, Destination{Namespace: "default", Host: "reviews.default.svc.cluster.local", Subset: "v2"}); !errors.Is(err, ErrUnknownSubset) {
+		t.Errorf("expected an unknown subset error, got %v", err)
+	}
+}
+
+func TestResolveNamespaces(t *testing.T) {
+	root := dr("istio-system", "mesh", "*.local", &networking.TrafficPolicy{
+		Tls:              tls(networking.ClientTLSSettings_ISTIO_MUTUAL),
+		OutlierDetection: outlier(3),
+	})
+	service := dr("default", "reviews", "reviews", &networking.TrafficPolicy{
+		LoadBalancer:     lb(networking.LoadBalancerSettings_ROUND_ROBIN),
+		OutlierDetection: outlier(5),
+	})
+	client := dr("frontend", "reviews", "reviews.default.svc.cluster.local", &networking.TrafficPolicy{
+		LoadBalancer: lb(networking.LoadBalancerSettings_RANDOM),
+	})
+	private := dr("default", "reviews-private", "reviews", &networking.TrafficPolicy{
+		LoadBalancer: lb(networking.LoadBalancerSettings_PASSTHROUGH),
+	})
+	private.Spec.ExportTo = []string{"."}
+	d := Destination{Namespace: "frontend", Host: "reviews.default.svc.cluster.local"}
+
+	cases := []struct {
+		name        string
+		drs         []*DestinationRule
+		inheritance bool
+		want        map[string]string
+		rules       []string
+	}{
+		{
+			name:  "client namespace first",
+			drs:   []*DestinationRule{root, service, client},
+			want:  map[string]string{"loadBalancer": "frontend/reviews: spec.trafficPolicy.loadBalancer"},
+			rules: []string{"frontend/reviews"},
+		},
+		{
+			name: "service namespace",
+			drs:  []*DestinationRule{root, service},
+			want: map[string]string{
+				"loadBalancer":     "default/reviews: spec.trafficPolicy.loadBalancer",
+				"outlierDetection": "default/reviews: spec.trafficPolicy.outlierDetection",
+			},
+			rules: []string{"default/reviews"},
+		},
+		{
+			name: "root namespace",
+			drs:  []*DestinationRule{root, private},
+			want: map[string]string{
+				"tls":              "istio-system/mesh: spec.trafficPolicy.tls",
+				"outlierDetection": "istio-system/mesh: spec.trafficPolicy.outlierDetection",
+			},
+			rules: []string{"istio-system/mesh"},
+		},
+		{
+			name:        "inheritance",
+			drs:         []*DestinationRule{root, service, client},
+			inheritance: true,
+			want: map[string]string{
+				"loadBalancer":     "frontend/reviews: spec.trafficPolicy.loadBalancer",
+				"outlierDetection": "default/reviews: spec.trafficPolicy.outlierDetection",
+				"tls":              "istio-system/mesh: spec.trafficPolicy.tls",
+			},
+			rules: []string{"frontend/reviews", "default/reviews", "istio-system/mesh"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			res, err := (&Resolver{Inheritance: c.inheritance}).Resolve(c.drs, d)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := sources(res); !maps.Equal(got, c.want) {
+				t.Errorf("got provenance %v, want %v", got, c.want)
+			}
+			var rules []string
+			for _, dr := range res.DestinationRules {
+				rules = append(rules, dr.String())
+			}
+			if !slices.Equal(rules, c.rules) {
+				t.Errorf("got rules %v, want %v", rules, c.rules)
+			}
+		})
+	}
+}
+
+func TestResolveMerge(t *testing.T) {
+	first := dr("default", "reviews-subsets", "reviews", nil,
+		&networking.Subset{Name: "v1", Labels: map[string]string{"version": "v1"}})
+	second := dr("default", "reviews-policy", "reviews", &networking.TrafficPolicy{Tls: tls(networking.ClientTLSSettings_DISABLE)},
+		&networking.Subset{Name: "v1", Labels: map[string]string{"version": "other"}},
+		&networking.Subset{Name: "v2", Labels: map[string]string{"version": "v2"}})
+	third := dr("default", "reviews-late", "reviews", &networking.TrafficPolicy{Tls: tls(networking.ClientTLSSettings_SIMPLE)})
+	wildcard := dr("default", "wildcard", "*.svc.cluster.local", &networking.TrafficPolicy{LoadBalancer: lb(networking.LoadBalancerSettings_RANDOM)})
+	selected := dr("default", "reviews-canary", "reviews", &networking.TrafficPolicy{LoadBalancer: lb(networking.LoadBalancerSettings_LEAST_REQUEST)})
+	selected.Spec.WorkloadSelector = &v1beta1.WorkloadSelector{MatchLabels: map[string]string{"app": "canary"}}
+	drs := []*DestinationRule{wildcard, first, second, third, selected}
+
+	res, err := Resolve(drs, Destination{Namespace: "default", Host: "reviews.default.svc.cluster.local", Subset: "v1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The most specific host wins over the wildcard, the first subset definition and the first
+	// top level policy are kept.
+	if res.Subset.Labels["version"] != "v1" || res.TrafficPolicy.Tls.GetMode() != networking.ClientTLSSettings_DISABLE || res.TrafficPolicy.LoadBalancer != nil {
+		t.Errorf("got %v, subset %v", res.TrafficPolicy, res.Subset)
+	}
+	if got := res.Provenance["tls"].String(); got != "default/reviews-policy: spec.trafficPolicy.tls" {
+		t.Errorf("got tls source %s", got)
+	}
+	if len(res.DestinationRules) != 3 {
+		t.Errorf("got rules %v", res.DestinationRules)
+	}
+	if _, err := Resolve(drs, Destination{Namespace: "default", Host: "reviews.default.svc.cluster.local", Subset: "v2"}); err != nil {
+		t.Errorf("subsets of merged rules must be found: %v", err)
+	}
+
+	// A rule whose workload selector matches the client replaces the others.
+	res, _ = Resolve(drs, Destination{Namespace: "default", Labels: map[string]string{"app": "canary"}, Host: "reviews.default.svc.cluster.local"})
+	if got := sources(res); !maps.Equal(got, map[string]string{"loadBalancer": "default/reviews-canary: spec.trafficPolicy.loadBalancer"}) {
+		t.Errorf("got provenance %v", got)
+	}
+	// Workload selectors do not apply across namespaces.
+	res, _ = Resolve(drs, Destination{Namespace: "frontend", Labels: map[string]string{"app": "canary"}, Host: "reviews.default.svc.cluster.local"})
+	if got := res.Provenance["tls"].DestinationRule; got != second {
+		t.Errorf("got tls from %v", got)
+	}
+}
@@ -0,0 +1,273 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validation
+
+import (
+	"strings"
+	"time"
+
+	networking "istio.io/api/networking/v1alpha3"
+)
+
+// ValidateDestinationRule checks the semantic validity of a DestinationRule spec.
+func ValidateDestinationRule(dr *networking.DestinationRule) ErrorList {
+	if dr == nil {
+		return ErrorList{Required(NewPath(""), "spec must not be nil")}
+	}
+	var errs ErrorList
+	if dr.Host == "" {
+		errs = append(errs, Required(NewPath("host"), "host must be set"))
+	} else {
+		errs = append(errs, validateWildcardHost(NewPath("host"), dr.Host)...)
+	}
+	errs = append(errs, validateTrafficPolicy(NewPath("trafficPolicy"), dr.TrafficPolicy)...)
+
+	seenSubsets := map[string]bool{}
+	for i, s := range dr.Subsets {
+		p := NewPath("subsets").Index(i)
+		if s == nil {
+			errs = append(errs, Required(p, "subset must not be nil"))
+			continue
+		}
+		if s.Name == "" {
+			errs = append(errs, Required(p.Child("name"), "subset name must be set"))
+		} else if !isDNS1123Label(s.Name) {
+			errs = append(errs, Invalid(p.Child("name"), s.Name, "must be a valid DNS-1123 label"))
+		} else if seenSubsets[s.Name] {
+			errs = append(errs, Duplicate(p.Child("name"), s.Name))
+		}
+		seenSubsets[s.Name] = true
+		errs = append(errs, validateLabels(p.Child("labels"), s.Labels)...)
+		errs = append(errs, validateTrafficPolicy(p.Child("trafficPolicy"), s.TrafficPolicy)...)
+	}
+	if dr.WorkloadSelector != nil {
+		errs = append(errs, validateLabels(NewPath("workloadSelector").Child("matchLabels"), dr.WorkloadSelector.MatchLabels)...)
+	}
+	errs = append(errs, validateExportTo(NewPath("exportTo"), dr.ExportTo)...)
+	return errs
+}
+
+func validateTrafficPolicy(p Path, tp *networking.TrafficPolicy) ErrorList {
+	if tp == nil {
+		return nil
+	}
+	var errs ErrorList
+	errs = append(errs, validateLoadBalancer(p.Child("loadBalancer"), tp.LoadBalancer)...)
+	errs = append(errs, validateConnectionPool(p.Child("connectionPool"), tp.ConnectionPool)...)
+	errs = append(errs, validateOutlierDetection(p.Child("outlierDetection"), tp.OutlierDetection)...)
+	errs = append(errs, validateClientTLSSettings(p.Child("tls"), tp.Tls)...)
+	if t := tp.Tunnel; t != nil {
+		tunp := p.Child("tunnel")
+		if t.Protocol != "" && !strings.EqualFold(t.Protocol, "CONNECT") && !strings.EqualFold(t.Protocol, "POST") {
+			errs = append(errs, NotSupported(tunp.Child("protocol"), t.Protocol, []string{"CONNECT", "POST"}))
+		}
+		if t.TargetHost == "" {
+			errs = append(errs, Required(tunp.Child("targetHost"), "target host must be set"))
+		} else {
+			errs = append(errs, validateHostOrIP(tunp.Child("targetHost"), t.TargetHost)...)
+		}
+		errs = append(errs, validatePort(tunp.Child("targetPort"), t.TargetPort)...)
+	}
+	if rb := tp.RetryBudget; rb != nil && rb.Percent != nil {
+		errs = append(errs, validatePercent(p.Child("retryBudget").Child("percent"), rb.Percent.Value)...)
+	}
+
+	seenPorts := map[uint32]bool{}
+	for i, pls := range tp.PortLevelSettings {
+		pp := p.Child("portLevelSettings").Index(i)
+		if pls == nil {
+			errs = append(errs, Required(pp, "port traffic policy must not be nil"))
+			continue
+		}
+		if pls.Port == nil {
+			errs = append(errs, Required(pp.Child("port"), "port must be set"))
+		} else {
+			errs = append(errs, validatePort(pp.Child("port").Child("number"), pls.Port.Number)...)
+			if seenPorts[pls.Port.Number] {
+				errs = append(errs, Duplicate(pp.Child("port").Child("number"), pls.Port.Number))
+			}
+			seenPorts[pls.Port.Number] = true
+		}
+		errs = append(errs, validateLoadBalancer(pp.Child("loadBalancer"), pls.LoadBalancer)...)
+		errs = append(errs, validateConnectionPool(pp.Child("connectionPool"), pls.ConnectionPool)...)
+		errs = append(errs, validateOutlierDetection(pp.Child("outlierDetection"), pls.OutlierDetection)...)
+		errs = append(errs, validateClientTLSSettings(pp.Child("tls"), pls.Tls)...)
+	}
+	return errs
+}
+
+func validateLoadBalancer(p Path, lb *networking.LoadBalancerSettings) ErrorList {
+	if lb == nil {
+		return nil
+	}
+	var errs ErrorList
+	if ch := lb.GetConsistentHash(); ch != nil {
+		cp := p.Child("consistentHash")
+		switch hk := ch.HashKey.(type) {
+		case *networking.LoadBalancerSettings_ConsistentHashLB_HttpHeaderName:
+			if hk.HttpHeaderName == "" {
+				errs = append(errs, Required(cp.Child("httpHeaderName"), "header name must be set"))
+			}
+		case *networking.LoadBalancerSettings_ConsistentHashLB_HttpQueryParameterName:
+			if hk.HttpQueryParameterName == "" {
+				errs = append(errs, Required(cp.Child("httpQueryParameterName"), "query parameter name must be set"))
+			}
+		case *networking.LoadBalancerSettings_ConsistentHashLB_HttpCookie:
+			if hk.HttpCookie == nil || hk.HttpCookie.Name == "" {
+				errs = append(errs, Required(cp.Child("httpCookie").Child("name"), "cookie name must be set"))
+			}
+		case nil:
+			errs = append(errs, Required(cp, "one of httpHeaderName, httpCookie, useSourceIp or httpQueryParameterName must be set"))
+		}
+		if ch.MinimumRingSize != 0 && ch.HashAlgorithm != nil {
+			errs = append(errs, Forbidden(cp.Child("minimumRingSize"), "use ringHash.minimumRingSize instead when a hash algorithm is set"))
+		}
+	}
+	if lb.WarmupDurationSecs != nil && lb.Warmup != nil {
+		errs = append(errs, Forbidden(p.Child("warmupDurationSecs"), "warmupDurationSecs and warmup must not be set together"))
+	}
+	errs = append(errs, validateDuration(p.Child("warmupDurationSecs"), lb.WarmupDurationSecs, time.Second)...)
+	if w := lb.Warmup; w != nil {
+		wp := p.Child("warmup")
+		errs = append(errs, validateDuration(wp.Child("duration"), w.Duration, time.Second)...)
+		if w.MinimumPercent != nil {
+			errs = append(errs, validatePercent(wp.Child("minimumPercent"), w.MinimumPercent.Value)...)
+		}
+		if w.Aggression != nil && w.Aggression.Value < 1 {
+			errs = append(errs, Invalid(wp.Child("aggression"), w.Aggression.Value, "must be at least 1"))
+		}
+	}
+	if l := lb.LocalityLbSetting; l != nil {
+		lp := p.Child("localityLbSetting")
+		if len(l.Distribute) > 0 && len(l.Failover) > 0 {
+			errs = append(errs, Forbidden(lp, "distribute and failover must not be set together"))
+		}
+		for i, d := range l.Distribute {
+			var total uint64
+			for _, w := range d.GetTo() {
+				total += uint64(w)
+			}
+			if total != 100 {
+				errs = append(errs, Invalid(lp.Child("distribute").Index(i).Child("to"), total, "total weight must be 100"))
+			}
+		}
+		for i, f := range l.Failover {
+			if f.GetFrom() == f.GetTo() {
+				errs = append(errs, Invalid(lp.Child("failover").Index(i), f.GetFrom(), "failover must be between different regions"))
+			}
+		}
+	}
+	return errs
+}
+
+func validateConnectionPool(p Path, cp *networking.ConnectionPoolSettings) ErrorList {
+	if cp == nil {
+		return nil
+	}
+	var errs ErrorList
+	if cp.Tcp == nil && cp.Http == nil {
+		errs = append(errs, Required(p, "one of tcp or http must be set"))
+	}
+	if t := cp.Tcp; t != nil {
+		tp := p.Child("tcp")
+		if t.MaxConnections < 0 {
+			errs = append(errs, Invalid(tp.Child("maxConnections"), t.MaxConnections, "must not be negative"))
+		}
+		errs = append(errs, validateDuration(tp.Child("connectTimeout"), t.ConnectTimeout, time.Millisecond)...)
+		errs = append(errs, validateDuration(tp.Child("maxConnectionDuration"), t.MaxConnectionDuration, time.Millisecond)...)
+		errs = append(errs, validateDuration(tp.Child("idleTimeout"), t.IdleTimeout, 0)...)
+		if ka := t.TcpKeepalive; ka != nil {
+			errs = append(errs, validateDuration(tp.Child("tcpKeepalive").Child("time"), ka.Time, time.Second)...)
+			errs = append(errs, validateDuration(tp.Child("tcpKeepalive").Child("interval"), ka.Interval, time.Second)...)
+		}
+	}
+	if h := cp.Http; h != nil {
+		hp := p.Child("http")
+		for _, f := range []struct {
+			name  string
+			value int32
+		}{
+			{"http1MaxPendingRequests", h.Http1MaxPendingRequests},
+			{"http2MaxRequests", h.Http2MaxRequests},
+			{"maxRequestsPerConnection", h.MaxRequestsPerConnection},
+			{"maxRetries", h.MaxRetries},
+			{"maxConcurrentStreams", h.MaxConcurrentStreams},
+		} {
+			if f.value < 0 {
+				errs = append(errs, Invalid(hp.Child(f.name), f.value, "must not be negative"))
+			}
+		}
+		errs = append(errs, validateDuration(hp.Child("idleTimeout"), h.IdleTimeout, time.Millisecond)...)
+	}
+	return errs
+}
+
+func validateOutlierDetection(p Path, od *networking.OutlierDetection) ErrorList {
+	if od == nil {
+		return nil
+	}
+	var errs ErrorList
+	if od.ConsecutiveErrors < 0 {
+		errs = append(errs, Invalid(p.Child("consecutiveErrors"), od.ConsecutiveErrors, "must not be negative"))
+	}
+	if od.ConsecutiveLocalOriginFailures != nil && !od.SplitExternalLocalOriginErrors {
+		errs = append(errs, Invalid(p.Child("consecutiveLocalOriginFailures"), od.ConsecutiveLocalOriginFailures.Value,
+			"requires splitExternalLocalOriginErrors to be set"))
+	}
+	errs = append(errs, validateDuration(p.Child("interval"), od.Interval, time.Millisecond)...)
+	errs = append(errs, validateDuration(p.Child("baseEjectionTime"), od.BaseEjectionTime, time.Millisecond)...)
+	errs = append(errs, validatePercent(p.Child("maxEjectionPercent"), float64(od.MaxEjectionPercent))...)
+	errs = append(errs, validatePercent(p.Child("minHealthPercent"), float64(od.MinHealthPercent))...)
+	return errs
+}
+
+func validateClientTLSSettings(p Path, tls *networking.ClientTLSSettings) ErrorList {
+	if tls == nil {
+		return nil
+	}
+	var errs ErrorList
+	switch tls.Mode {
+	case networking.ClientTLSSettings_MUTUAL:
+		if tls.CredentialName == "" {
+			if tls.ClientCertificate == "" {
+				errs = append(errs, Required(p.Child("clientCertificate"), "client certificate is required for mutual TLS"))
+			}
+			if tls.PrivateKey == "" {
+				errs = append(errs, Required(p.Child("privateKey"), "private key is required for mutual TLS"))
+			}
+		}
+	case networking.ClientTLSSettings_ISTIO_MUTUAL:
+		for _, f := range []struct {
+			set  bool
+			name string
+		}{
+			{tls.ClientCertificate != "", "clientCertificate"},
+			{tls.PrivateKey != "", "privateKey"},
+			{tls.CaCertificates != "", "caCertificates"},
+			{tls.CredentialName != "", "credentialName"},
+		} {
+			if f.set {
+				errs = append(errs, Forbidden(p.Child(f.name), "must not be set in ISTIO_MUTUAL mode"))
+			}
+		}
+	}
+	if tls.CredentialName != "" && (tls.ClientCertificate != "" || tls.PrivateKey != "" || tls.CaCertificates != "") {
+		errs = append(errs, Forbidden(p.Child("credentialName"), "credentialName must not be set together with certificate files"))
+	}
+	if tls.Sni != "" {
+		errs = append(errs, validateFQDN(p.Child("sni"), tls.Sni)...)
+	}
+	return errs
+}
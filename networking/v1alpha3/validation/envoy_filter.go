@@ -0,0 +1,613 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validation
+
+import (
+	"encoding/base64"
+	"fmt"
+	"maps"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	alpn "istio.io/api/envoy/config/filter/http/alpn/v2alpha1"
+	jwtauth "istio.io/api/envoy/config/filter/http/jwt_auth/v2alpha1"
+	metadataexchange "istio.io/api/envoy/config/filter/network/metadata_exchange"
+	tcpclusterrewrite "istio.io/api/envoy/config/filter/network/tcp_cluster_rewrite/v2alpha1"
+	stackdriver "istio.io/api/envoy/extensions/stackdriver/config/v1alpha1"
+	"istio.io/api/envoy/extensions/stats"
+	"istio.io/api/internal/suggest"
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/api/strictjson"
+)
+
+// Type URLs of the TypedStruct messages, which carry a configuration as a JSON struct
+// alongside the type URL of the configuration.
+var typedStructURLs = []string{
+	"type.googleapis.com/udpa.type.v1.TypedStruct",
+	"type.googleapis.com/xds.type.v3.TypedStruct",
+}
+
+var (
+	envoyFilterTypesMu sync.RWMutex
+	// envoyFilterTypes holds the descriptors of the configurations checked by ValidateEnvoyFilter,
+	// keyed by full name. It starts with the Envoy filters of the Istio proxy defined in this module.
+	envoyFilterTypes = map[protoreflect.FullName]protoreflect.MessageDescriptor{}
+)
+
+func init() {
+	for _, m := range []protoreflect.ProtoMessage{
+		&alpn.FilterConfig{},
+		&jwtauth.JwtAuthentication{},
+		&metadataexchange.MetadataExchange{},
+		&tcpclusterrewrite.TcpClusterRewrite{},
+		&stackdriver.PluginConfig{},
+		&stats.PluginConfig{},
+	} {
+		RegisterEnvoyFilterType(m.ProtoReflect().Descriptor())
+	}
+}
+
+// RegisterEnvoyFilterType adds md to the configuration types checked by ValidateEnvoyFilter.
+// Registering a type with the name of an already registered type replaces it.
+func RegisterEnvoyFilterType(md protoreflect.MessageDescriptor) {
+	envoyFilterTypesMu.Lock()
+	defer envoyFilterTypesMu.Unlock()
+	envoyFilterTypes[md.FullName()] = md
+}
+
+// lookupEnvoyFilterType returns the registered descriptor for typeURL. As in Any, only the
+// part of the URL following the last '/' is significant.
+func lookupEnvoyFilterType(typeURL string) (protoreflect.MessageDescriptor, bool) {
+	name := typeURL[strings.LastIndex(typeURL, "/")+1:]
+	envoyFilterTypesMu.RLock()
+	defer envoyFilterTypesMu.RUnlock()
+	md, f := envoyFilterTypes[protoreflect.FullName(name)]
+	return md, f
+}
+
+var (
+	allInsertOperations = []networking.EnvoyFilter_Patch_Operation{
+		networking.EnvoyFilter_Patch_INSERT_BEFORE,
+		networking.EnvoyFilter_Patch_INSERT_AFTER,
+		networking.EnvoyFilter_Patch_INSERT_FIRST,
+	}
+	mergeAddRemove = []networking.EnvoyFilter_Patch_Operation{
+		networking.EnvoyFilter_Patch_MERGE,
+		networking.EnvoyFilter_Patch_ADD,
+		networking.EnvoyFilter_Patch_REMOVE,
+	}
+)
+
+// envoyFilterOperations lists the patch operations the control plane implements for each ApplyTo.
+// Ordering only matters for filters and routes, so those are the only objects supporting the
+// INSERT_* operations, and REPLACE is only implemented for filters.
+var envoyFilterOperations = map[networking.EnvoyFilter_ApplyTo][]networking.EnvoyFilter_Patch_Operation{
+	networking.EnvoyFilter_LISTENER:            mergeAddRemove,
+	networking.EnvoyFilter_FILTER_CHAIN:        mergeAddRemove,
+	networking.EnvoyFilter_NETWORK_FILTER:      slices.Concat(mergeAddRemove, allInsertOperations, []networking.EnvoyFilter_Patch_Operation{networking.EnvoyFilter_Patch_REPLACE}),
+	networking.EnvoyFilter_HTTP_FILTER:         slices.Concat(mergeAddRemove, allInsertOperations, []networking.EnvoyFilter_Patch_Operation{networking.EnvoyFilter_Patch_REPLACE}),
+	networking.EnvoyFilter_LISTENER_FILTER:     slices.Concat(mergeAddRemove, allInsertOperations),
+	networking.EnvoyFilter_ROUTE_CONFIGURATION: {networking.EnvoyFilter_Patch_MERGE},
+	networking.EnvoyFilter_VIRTUAL_HOST:        mergeAddRemove,
+	networking.EnvoyFilter_HTTP_ROUTE:          slices.Concat(mergeAddRemove, allInsertOperations),
+	networking.EnvoyFilter_CLUSTER:             mergeAddRemove,
+	networking.EnvoyFilter_EXTENSION_CONFIG:    {networking.EnvoyFilter_Patch_ADD},
+	networking.EnvoyFilter_BOOTSTRAP:           {networking.EnvoyFilter_Patch_MERGE},
+}
+
+// ValidateEnvoyFilter checks the semantic validity of an EnvoyFilter spec.
+//
+// Besides the structure of each patch, the configurations embedded in patch values, either as
+// an Any with an "@type" field or as a TypedStruct, are checked against the descriptors added
+// with RegisterEnvoyFilterType. Configurations of other types are not checked, as the set of
+// Envoy extensions available to a proxy is not known here. The top-level fields of HTTP_FILTER
+// patch values are checked against the fields of the Envoy HttpFilter.
+func ValidateEnvoyFilter(ef *networking.EnvoyFilter) ErrorList {
+	if ef == nil {
+		return ErrorList{Required(NewPath(""), "spec must not be nil")}
+	}
+	var errs ErrorList
+	if ef.WorkloadSelector != nil {
+		p := NewPath("workloadSelector").Child("labels")
+		if len(ef.WorkloadSelector.Labels) == 0 {
+			errs = append(errs, Required(p, "workload selector must have at least one label"))
+		}
+		errs = append(errs, validateLabels(p, ef.WorkloadSelector.Labels)...)
+		if len(ef.TargetRefs) > 0 {
+			errs = append(errs, Forbidden(NewPath("targetRefs"), "only one of workloadSelector or targetRefs may be set"))
+		}
+	}
+	for i, cp := range ef.ConfigPatches {
+		errs = append(errs, validateEnvoyConfigObjectPatch(NewPath("configPatches").Index(i), cp)...)
+	}
+	return errs
+}
+
+func validateEnvoyConfigObjectPatch(p Path, cp *networking.EnvoyFilter_EnvoyConfigObjectPatch) ErrorList {
+	if cp == nil {
+		return ErrorList{Required(p, "config patch must not be nil")}
+	}
+	var errs ErrorList
+	operations, f := envoyFilterOperations[cp.ApplyTo]
+	if !f {
+		errs = append(errs, Required(p.Child("applyTo"), "applyTo must be set"))
+	}
+	errs = append(errs, validateEnvoyFilterMatch(p.Child("match"), cp.ApplyTo, cp.Match)...)
+
+	pp := p.Child("patch")
+	if cp.Patch == nil {
+		return append(errs, Required(pp, "patch must be set"))
+	}
+	op := cp.Patch.Operation
+	switch {
+	case op == networking.EnvoyFilter_Patch_INVALID:
+		errs = append(errs, Required(pp.Child("operation"), "operation must be set"))
+	case f && !slices.Contains(operations, op):
+		names := make([]string, 0, len(operations))
+		for _, o := range operations {
+			names = append(names, o.String())
+		}
+		errs = append(errs, Invalid(pp.Child("operation"), op.String(), fmt.Sprintf(
+			"not supported when applyTo is %v; supported operations: %s", cp.ApplyTo, strings.Join(names, ", "))))
+	}
+	if cp.Patch.FilterClass != networking.EnvoyFilter_Patch_UNSPECIFIED {
+		if op != networking.EnvoyFilter_Patch_ADD {
+			errs = append(errs, Forbidden(pp.Child("filterClass"), "filterClass may only be used with the ADD operation"))
+		}
+		if cp.ApplyTo != networking.EnvoyFilter_HTTP_FILTER && cp.ApplyTo != networking.EnvoyFilter_NETWORK_FILTER {
+			errs = append(errs, Forbidden(pp.Child("filterClass"), "filterClass may only be used with HTTP_FILTER or NETWORK_FILTER"))
+		}
+	}
+	if cp.Patch.Value == nil {
+		if op != networking.EnvoyFilter_Patch_REMOVE && op != networking.EnvoyFilter_Patch_INVALID {
+			errs = append(errs, Required(pp.Child("value"), "value must be set for all operations but REMOVE"))
+		}
+		return errs
+	}
+	if cp.ApplyTo == networking.EnvoyFilter_HTTP_FILTER {
+		errs = append(errs, validateHTTPFilter(pp.Child("value"), cp.Patch.Value)...)
+	}
+	return append(errs, validateTypedConfigs(pp.Child("value"), structpb.NewStructValue(cp.Patch.Value))...)
+}
+
+// httpFilterFields are the proto and JSON names of the fields of the Envoy HttpFilter, the
+// object patched when applyTo is HTTP_FILTER.
+var httpFilterFields = []string{
+	"name",
+	"typed_config", "typedConfig",
+	"config_discovery", "configDiscovery",
+	"is_optional", "isOptional",
+	"disabled",
+}
+
+// validateHTTPFilter checks the top-level fields of an HTTP_FILTER patch value. The Envoy protos
+// are not part of this module, so only the field names and the types of name and typed_config
+// are checked.
+func validateHTTPFilter(p Path, s *structpb.Struct) ErrorList {
+	var errs ErrorList
+	for _, name := range slices.Sorted(maps.Keys(s.GetFields())) {
+		v := s.Fields[name]
+		switch name {
+		case "name":
+			if _, ok := v.GetKind().(*structpb.Value_StringValue); !ok {
+				errs = append(errs, mismatch(p.Child(name), v, "a string"))
+			}
+		case "typed_config", "typedConfig":
+			if _, ok := v.GetKind().(*structpb.Value_StructValue); !ok {
+				errs = append(errs, mismatch(p.Child(name), v, "an object"))
+			}
+		default:
+			if slices.Contains(httpFilterFields, name) {
+				continue
+			}
+			detail := "unknown field in envoy.extensions.filters.network.http_connection_manager.v3.HttpFilter"
+			if s := suggest.Closest(name, httpFilterFields); s != "" {
+				detail += fmt.Sprintf("; did you mean %q?", s)
+			}
+			errs = append(errs, Invalid(p.Child(name), name, detail))
+		}
+	}
+	return errs
+}
+
+// validateEnvoyFilterMatch checks that the object type selected by m is the one patched by applyTo.
+func validateEnvoyFilterMatch(p Path, applyTo networking.EnvoyFilter_ApplyTo, m *networking.EnvoyFilter_EnvoyConfigObjectMatch) ErrorList {
+	if m == nil {
+		return nil
+	}
+	var want string
+	switch applyTo {
+	case networking.EnvoyFilter_LISTENER, networking.EnvoyFilter_FILTER_CHAIN, networking.EnvoyFilter_NETWORK_FILTER,
+		networking.EnvoyFilter_HTTP_FILTER, networking.EnvoyFilter_LISTENER_FILTER:
+		if m.GetRouteConfiguration() == nil && m.GetCluster() == nil {
+			return nil
+		}
+		want = "listener"
+	case networking.EnvoyFilter_ROUTE_CONFIGURATION, networking.EnvoyFilter_VIRTUAL_HOST, networking.EnvoyFilter_HTTP_ROUTE:
+		if m.GetListener() == nil && m.GetCluster() == nil {
+			return nil
+		}
+		want = "routeConfiguration"
+	case networking.EnvoyFilter_CLUSTER:
+		if m.GetListener() == nil && m.GetRouteConfiguration() == nil {
+			return nil
+		}
+		want = "cluster"
+	default:
+		return nil
+	}
+	return ErrorList{Invalid(p, applyTo.String(), fmt.Sprintf("applyTo %v may only be used with a %s match", applyTo, want))}
+}
+
+// validateTypedConfigs checks the typed configurations found anywhere within v.
+func validateTypedConfigs(p Path, v *structpb.Value) ErrorList {
+	var errs ErrorList
+	switch k := v.GetKind().(type) {
+	case *structpb.Value_StructValue:
+		if _, f := k.StructValue.GetFields()["@type"]; f {
+			return validateAny(p, k.StructValue)
+		}
+		for _, name := range slices.Sorted(maps.Keys(k.StructValue.GetFields())) {
+			errs = append(errs, validateTypedConfigs(p.Child(name), k.StructValue.Fields[name])...)
+		}
+	case *structpb.Value_ListValue:
+		for i, e := range k.ListValue.GetValues() {
+			errs = append(errs, validateTypedConfigs(p.Index(i), e)...)
+		}
+	}
+	return errs
+}
+
+// validateAny checks the JSON representation of an Any, whose "@type" field names the type of
+// the other fields. A TypedStruct is resolved to the type of the struct it holds.
+func validateAny(p Path, s *structpb.Struct) ErrorList {
+	typeURL, ok := s.GetFields()["@type"].GetKind().(*structpb.Value_StringValue)
+	if !ok || typeURL.StringValue == "" {
+		return ErrorList{Invalid(p.Child("@type"), jsonValue(s.GetFields()["@type"]), "must be a type URL")}
+	}
+	if slices.Contains(typedStructURLs, typeURL.StringValue) {
+		var errs ErrorList
+		inner := s.Fields["type_url"].GetStringValue()
+		if inner == "" {
+			errs = append(errs, Required(p.Child("type_url"), "TypedStruct must set type_url"))
+		}
+		for _, name := range slices.Sorted(maps.Keys(s.Fields)) {
+			if name != "@type" && name != "type_url" && name != "value" {
+				errs = append(errs, Invalid(p.Child(name), name, "unknown field in TypedStruct"))
+			}
+		}
+		value, f := s.Fields["value"]
+		if !f || inner == "" {
+			return errs
+		}
+		vs, ok := value.GetKind().(*structpb.Value_StructValue)
+		if !ok {
+			return append(errs, mismatch(p.Child("value"), value, "an object"))
+		}
+		return append(errs, validateTypedStruct(p.Child("value"), inner, vs.StructValue)...)
+	}
+	fields := &structpb.Struct{Fields: map[string]*structpb.Value{}}
+	for k, v := range s.Fields {
+		if k != "@type" {
+			fields.Fields[k] = v
+		}
+	}
+	return validateTypedStruct(p, typeURL.StringValue, fields)
+}
+
+// validateTypedStruct checks s against the registered type named by typeURL. If the type is not
+// registered, only the typed configurations nested within s are checked.
+func validateTypedStruct(p Path, typeURL string, s *structpb.Struct) ErrorList {
+	md, f := lookupEnvoyFilterType(typeURL)
+	if !f {
+		var errs ErrorList
+		for _, name := range slices.Sorted(maps.Keys(s.GetFields())) {
+			errs = append(errs, validateTypedConfigs(p.Child(name), s.Fields[name])...)
+		}
+		return errs
+	}
+	return append(validateUnknownFields(p, md, s), validateMessage(p, md, s)...)
+}
+
+// validateUnknownFields reports the fields of s, and of the messages nested within s, that are
+// not part of md. Configurations nested in an Any are checked separately, by validateAny.
+func validateUnknownFields(p Path, md protoreflect.MessageDescriptor, s *structpb.Struct) ErrorList {
+	b, err := protojson.Marshal(s)
+	if err != nil {
+		return ErrorList{Invalid(p, "object", err.Error())}
+	}
+	unknown, err := strictjson.FindUnknownFields(b, md)
+	if err != nil {
+		return ErrorList{Invalid(p, "object", err.Error())}
+	}
+	var errs ErrorList
+	for _, f := range unknown {
+		detail := fmt.Sprintf("unknown field in %v", f.Message.FullName())
+		if s := suggest.Closest(f.Name, fieldNames(f.Message)); s != "" {
+			detail += fmt.Sprintf("; did you mean %q?", s)
+		}
+		errs = append(errs, Invalid(p.Child(f.Path), f.Name, detail))
+	}
+	return errs
+}
+
+// validateMessage checks that the known fields of s are valid protobuf JSON representations of
+// the fields of md. Fields may be named by their JSON or their original proto names, as in
+// protojson, and unknown fields are left to validateUnknownFields.
+func validateMessage(p Path, md protoreflect.MessageDescriptor, s *structpb.Struct) ErrorList {
+	var errs ErrorList
+	oneofs := map[protoreflect.FullName]string{}
+	for _, name := range slices.Sorted(maps.Keys(s.GetFields())) {
+		fp := p.Child(name)
+		fd := md.Fields().ByJSONName(name)
+		if fd == nil {
+			fd = md.Fields().ByTextName(name)
+		}
+		if fd == nil {
+			continue
+		}
+		v := s.Fields[name]
+		if _, null := v.GetKind().(*structpb.Value_NullValue); null && (fd.Message() == nil || fd.Message().FullName() != "google.protobuf.Value") {
+			// As in protojson, null leaves the field unset.
+			continue
+		}
+		if od := fd.ContainingOneof(); od != nil && !od.IsSynthetic() {
+			if other, f := oneofs[od.FullName()]; f {
+				errs = append(errs, Forbidden(fp, fmt.Sprintf("only one of %q and %q may be set", other, name)))
+			}
+			oneofs[od.FullName()] = name
+		}
+		errs = append(errs, validateFieldValue(fp, fd, v)...)
+	}
+	return errs
+}
+
+// fieldNames returns the JSON names of the fields of md.
+func fieldNames(md protoreflect.MessageDescriptor) []string {
+	names := make([]string, 0, md.Fields().Len())
+	for i := 0; i < md.Fields().Len(); i++ {
+		names = append(names, md.Fields().Get(i).JSONName())
+	}
+	return names
+}
+
+func validateFieldValue(p Path, fd protoreflect.FieldDescriptor, v *structpb.Value) ErrorList {
+	switch {
+	case fd.IsMap():
+		s, ok := v.GetKind().(*structpb.Value_StructValue)
+		if !ok {
+			return ErrorList{mismatch(p, v, "an object")}
+		}
+		var errs ErrorList
+		for _, k := range slices.Sorted(maps.Keys(s.StructValue.GetFields())) {
+			errs = append(errs, validateMapKey(p.Key(k), fd.MapKey(), k)...)
+			errs = append(errs, validateSingularValue(p.Key(k), fd.MapValue(), s.StructValue.Fields[k])...)
+		}
+		return errs
+	case fd.IsList():
+		l, ok := v.GetKind().(*structpb.Value_ListValue)
+		if !ok {
+			return ErrorList{mismatch(p, v, "a list")}
+		}
+		var errs ErrorList
+		for i, e := range l.ListValue.GetValues() {
+			errs = append(errs, validateSingularValue(p.Index(i), fd, e)...)
+		}
+		return errs
+	default:
+		return validateSingularValue(p, fd, v)
+	}
+}
+
+func validateMapKey(p Path, fd protoreflect.FieldDescriptor, k string) ErrorList {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		if k != "true" && k != "false" {
+			return ErrorList{Invalid(p, k, "map key must be a boolean")}
+		}
+	case protoreflect.StringKind:
+	default:
+		if _, err := strconv.ParseInt(k, 10, 64); err != nil {
+			if _, err := strconv.ParseUint(k, 10, 64); err != nil {
+				return ErrorList{Invalid(p, k, "map key must be an integer")}
+			}
+		}
+	}
+	return nil
+}
+
+func validateSingularValue(p Path, fd protoreflect.FieldDescriptor, v *structpb.Value) ErrorList {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		if _, ok := v.GetKind().(*structpb.Value_BoolValue); !ok {
+			return ErrorList{mismatch(p, v, "a boolean")}
+		}
+	case protoreflect.StringKind:
+		if _, ok := v.GetKind().(*structpb.Value_StringValue); !ok {
+			return ErrorList{mismatch(p, v, "a string")}
+		}
+	case protoreflect.BytesKind:
+		s, ok := v.GetKind().(*structpb.Value_StringValue)
+		if !ok {
+			return ErrorList{mismatch(p, v, "a base64 encoded string")}
+		}
+		if !isBase64(s.StringValue) {
+			return ErrorList{Invalid(p, s.StringValue, "must be base64 encoded")}
+		}
+	case protoreflect.EnumKind:
+		return validateEnumValue(p, fd.Enum(), v)
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return validateNumber(p, v, true, -math.MaxFloat64, math.MaxFloat64)
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return validateNumber(p, v, false, math.MinInt32, math.MaxInt32)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return validateNumber(p, v, false, 0, math.MaxUint32)
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return validateNumber(p, v, false, math.MinInt64, math.MaxInt64)
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return validateNumber(p, v, false, 0, math.MaxUint64)
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return validateMessageValue(p, fd.Message(), v)
+	}
+	return nil
+}
+
+func validateEnumValue(p Path, ed protoreflect.EnumDescriptor, v *structpb.Value) ErrorList {
+	switch k := v.GetKind().(type) {
+	case *structpb.Value_StringValue:
+		if ed.Values().ByName(protoreflect.Name(k.StringValue)) == nil {
+			names := make([]string, 0, ed.Values().Len())
+			for i := 0; i < ed.Values().Len(); i++ {
+				names = append(names, string(ed.Values().Get(i).Name()))
+			}
+			return ErrorList{NotSupported(p, k.StringValue, names)}
+		}
+	case *structpb.Value_NumberValue:
+		// Unknown numbers are accepted, as open enums may hold values added in later versions.
+		if k.NumberValue != math.Trunc(k.NumberValue) || k.NumberValue < math.MinInt32 || k.NumberValue > math.MaxInt32 {
+			return ErrorList{Invalid(p, k.NumberValue, "enum number must be a 32-bit integer")}
+		}
+	case *structpb.Value_NullValue:
+		if ed.FullName() != "google.protobuf.NullValue" {
+			return ErrorList{mismatch(p, v, "an enum name or number")}
+		}
+	default:
+		return ErrorList{mismatch(p, v, "an enum name or number")}
+	}
+	return nil
+}
+
+// validateNumber checks a numeric field. As in protojson, numbers may also be given as strings,
+// which is required for 64-bit integers that do not fit in a double.
+func validateNumber(p Path, v *structpb.Value, float bool, min, max float64) ErrorList {
+	var n float64
+	switch k := v.GetKind().(type) {
+	case *structpb.Value_NumberValue:
+		n = k.NumberValue
+	case *structpb.Value_StringValue:
+		s := strings.TrimSpace(k.StringValue)
+		if float && (s == "NaN" || s == "Infinity" || s == "-Infinity") {
+			return nil
+		}
+		var err error
+		if n, err = strconv.ParseFloat(s, 64); err != nil || s != k.StringValue {
+			return ErrorList{Invalid(p, k.StringValue, "must be a number")}
+		}
+	default:
+		if float {
+			return ErrorList{mismatch(p, v, "a number")}
+		}
+		return ErrorList{mismatch(p, v, "an integer")}
+	}
+	if !float && n != math.Trunc(n) {
+		return ErrorList{Invalid(p, n, "must be an integer")}
+	}
+	if n < min || n > max {
+		return ErrorList{Invalid(p, n, fmt.Sprintf("must be between %v and %v", min, max))}
+	}
+	return nil
+}
+
+// durationRegexp matches the JSON representation of google.protobuf.Duration.
+var durationRegexp = regexp.MustCompile(`^-?[0-9]+(\.[0-9]{1,9})?s$`)
+
+// validateMessageValue checks a message field, taking the special JSON representation of the
+// well-known types into account.
+func validateMessageValue(p Path, md protoreflect.MessageDescriptor, v *structpb.Value) ErrorList {
+	switch md.FullName() {
+	case "google.protobuf.Value":
+		return nil
+	case "google.protobuf.Duration":
+		s, ok := v.GetKind().(*structpb.Value_StringValue)
+		if !ok {
+			return ErrorList{mismatch(p, v, `a duration string such as "1.5s"`)}
+		}
+		if !durationRegexp.MatchString(s.StringValue) {
+			return ErrorList{Invalid(p, s.StringValue, `must be a duration in seconds such as "1.5s"`)}
+		}
+		return nil
+	case "google.protobuf.Timestamp":
+		s, ok := v.GetKind().(*structpb.Value_StringValue)
+		if !ok {
+			return ErrorList{mismatch(p, v, "an RFC 3339 timestamp")}
+		}
+		if _, err := time.Parse(time.RFC3339Nano, s.StringValue); err != nil {
+			return ErrorList{Invalid(p, s.StringValue, "must be an RFC 3339 timestamp")}
+		}
+		return nil
+	case "google.protobuf.FieldMask":
+		if _, ok := v.GetKind().(*structpb.Value_StringValue); !ok {
+			return ErrorList{mismatch(p, v, "a string")}
+		}
+		return nil
+	case "google.protobuf.ListValue":
+		if _, ok := v.GetKind().(*structpb.Value_ListValue); !ok {
+			return ErrorList{mismatch(p, v, "a list")}
+		}
+		return nil
+	case "google.protobuf.DoubleValue", "google.protobuf.FloatValue", "google.protobuf.Int64Value",
+		"google.protobuf.UInt64Value", "google.protobuf.Int32Value", "google.protobuf.UInt32Value",
+		"google.protobuf.BoolValue", "google.protobuf.StringValue", "google.protobuf.BytesValue":
+		// Wrappers are represented by the value they wrap.
+		return validateSingularValue(p, md.Fields().ByName("value"), v)
+	}
+	s, ok := v.GetKind().(*structpb.Value_StructValue)
+	if !ok {
+		return ErrorList{mismatch(p, v, "an object")}
+	}
+	switch md.FullName() {
+	case "google.protobuf.Struct":
+		return nil
+	case "google.protobuf.Any":
+		return validateAny(p, s.StructValue)
+	}
+	return validateMessage(p, md, s.StructValue)
+}
+
+func isBase64(s string) bool {
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		if _, err := enc.DecodeString(s); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// mismatch returns an error for a value of the wrong JSON type.
+func mismatch(p Path, v *structpb.Value, want string) *Error {
+	return Invalid(p, jsonValue(v), "must be "+want)
+}
+
+// jsonValue returns v for scalars, and the name of its JSON type for objects and lists.
+func jsonValue(v *structpb.Value) any {
+	switch v.GetKind().(type) {
+	case *structpb.Value_StructValue:
+		return "object"
+	case *structpb.Value_ListValue:
+		return "list"
+	case *structpb.Value_NullValue, nil:
+		return "null"
+	}
+	return v.AsInterface()
+}
@@ -0,0 +1,137 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validation
+
+import (
+	"strings"
+
+	networking "istio.io/api/networking/v1alpha3"
+)
+
+// ValidateGateway checks the semantic validity of a Gateway spec.
+func ValidateGateway(gw *networking.Gateway) ErrorList {
+	if gw == nil {
+		return ErrorList{Required(NewPath(""), "spec must not be nil")}
+	}
+	var errs ErrorList
+	if len(gw.Servers) == 0 {
+		errs = append(errs, Required(NewPath("servers"), "at least one server must be set"))
+	}
+	seenNames := map[string]bool{}
+	for i, s := range gw.Servers {
+		p := NewPath("servers").Index(i)
+		if s == nil {
+			errs = append(errs, Required(p, "server must not be nil"))
+			continue
+		}
+		if s.Name != "" {
+			if seenNames[s.Name] {
+				errs = append(errs, Duplicate(p.Child("name"), s.Name))
+			}
+			seenNames[s.Name] = true
+		}
+		errs = append(errs, validateServer(p, s)...)
+	}
+	errs = append(errs, validateLabels(NewPath("selector"), gw.Selector)...)
+	return errs
+}
+
+func validateServer(p Path, s *networking.Server) ErrorList {
+	var errs ErrorList
+	if len(s.Hosts) == 0 {
+		errs = append(errs, Required(p.Child("hosts"), "at least one host must be set"))
+	}
+	for i, h := range s.Hosts {
+		errs = append(errs, validateNamespacedHost(p.Child("hosts").Index(i), h, false)...)
+	}
+	if s.Port == nil {
+		return append(errs, Required(p.Child("port"), "port must be set"))
+	}
+	pp := p.Child("port")
+	errs = append(errs, validatePort(pp.Child("number"), s.Port.Number)...)
+	if s.Port.TargetPort != 0 {
+		errs = append(errs, validatePort(pp.Child("targetPort"), s.Port.TargetPort)...)
+	}
+	if s.Port.Name == "" {
+		errs = append(errs, Required(pp.Child("name"), "port name must be set"))
+	}
+	errs = append(errs, validateProtocol(pp.Child("protocol"), s.Port.Protocol)...)
+	if s.Bind != "" {
+		errs = append(errs, validateBind(p.Child("bind"), s.Bind)...)
+	}
+
+	protocol := strings.ToUpper(s.Port.Protocol)
+	tp := p.Child("tls")
+	isTLS := protocol == "HTTPS" || protocol == "TLS"
+	switch {
+	case isTLS && s.Tls == nil:
+		errs = append(errs, Required(tp, "tls settings are required for "+protocol+" servers"))
+	case !isTLS && s.Tls != nil && !(protocol == "HTTP" && s.Tls.HttpsRedirect):
+		// httpsRedirect is the only TLS setting that applies to plain text servers.
+		errs = append(errs, Forbidden(tp, "tls settings are only allowed for HTTPS or TLS servers"))
+	}
+	errs = append(errs, validateServerTLSSettings(tp, s.Tls, protocol)...)
+	return errs
+}
+
+func validateBind(p Path, bind string) ErrorList {
+	if strings.HasPrefix(bind, "unix://") {
+		return nil
+	}
+	return validateIPOrCIDR(p, bind)
+}
+
+func validateServerTLSSettings(p Path, tls *networking.ServerTLSSettings, protocol string) ErrorList {
+	if tls == nil || (tls.HttpsRedirect && protocol == "HTTP") {
+		return nil
+	}
+	var errs ErrorList
+	hasCredentialName := tls.CredentialName != "" || len(tls.CredentialNames) > 0
+	hasFiles := tls.ServerCertificate != "" || tls.PrivateKey != "" || len(tls.TlsCertificates) > 0
+	if tls.CredentialName != "" && len(tls.CredentialNames) > 0 {
+		errs = append(errs, Forbidden(p.Child("credentialNames"), "credentialName and credentialNames must not be set together"))
+	}
+	if hasCredentialName && hasFiles {
+		errs = append(errs, Forbidden(p.Child("credentialName"), "credentialName must not be set together with certificate files"))
+	}
+	switch tls.Mode {
+	case networking.ServerTLSSettings_SIMPLE, networking.ServerTLSSettings_MUTUAL, networking.ServerTLSSettings_OPTIONAL_MUTUAL:
+		if !hasCredentialName && len(tls.TlsCertificates) == 0 {
+			if tls.ServerCertificate == "" {
+				errs = append(errs, Required(p.Child("serverCertificate"), "server certificate or credentialName is required in "+tls.Mode.String()+" mode"))
+			}
+			if tls.PrivateKey == "" {
+				errs = append(errs, Required(p.Child("privateKey"), "private key or credentialName is required in "+tls.Mode.String()+" mode"))
+			}
+		}
+		if tls.Mode != networking.ServerTLSSettings_SIMPLE && !hasCredentialName && tls.CaCertificates == "" && tls.CaCertCredentialName == "" {
+			errs = append(errs, Required(p.Child("caCertificates"), "CA certificates are required in "+tls.Mode.String()+" mode"))
+		}
+	case networking.ServerTLSSettings_PASSTHROUGH, networking.ServerTLSSettings_AUTO_PASSTHROUGH, networking.ServerTLSSettings_ISTIO_MUTUAL:
+		if hasCredentialName || hasFiles {
+			errs = append(errs, Forbidden(p.Child("mode"), "certificates must not be configured in "+tls.Mode.String()+" mode"))
+		}
+		if tls.Mode == networking.ServerTLSSettings_AUTO_PASSTHROUGH && protocol != "TLS" {
+			errs = append(errs, Invalid(p.Child("mode"), tls.Mode.String(), "AUTO_PASSTHROUGH requires the TLS protocol"))
+		}
+	}
+	if tls.MinProtocolVersion != networking.ServerTLSSettings_TLS_AUTO &&
+		tls.MaxProtocolVersion != networking.ServerTLSSettings_TLS_AUTO &&
+		tls.MinProtocolVersion > tls.MaxProtocolVersion {
+		errs = append(errs, Invalid(p.Child("minProtocolVersion"), tls.MinProtocolVersion.String(),
+			"must not be greater than maxProtocolVersion"))
+	}
+	return errs
+}
@@ -0,0 +1,145 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validation
+
+import (
+	"maps"
+	"net/netip"
+	"slices"
+	"strings"
+
+	networking "istio.io/api/networking/v1alpha3"
+)
+
+// ValidateServiceEntry checks the semantic validity of a ServiceEntry spec.
+func ValidateServiceEntry(se *networking.ServiceEntry) ErrorList {
+	if se == nil {
+		return ErrorList{Required(NewPath(""), "spec must not be nil")}
+	}
+	var errs ErrorList
+	if len(se.Hosts) == 0 {
+		errs = append(errs, Required(NewPath("hosts"), "at least one host must be set"))
+	}
+	hasWildcard := false
+	seenHosts := map[string]bool{}
+	for i, h := range se.Hosts {
+		p := NewPath("hosts").Index(i)
+		if seenHosts[h] {
+			errs = append(errs, Duplicate(p, h))
+			continue
+		}
+		seenHosts[h] = true
+		if h == "*" {
+			errs = append(errs, Invalid(p, h, `"*" is not allowed, use a more specific wildcard such as "*.example.com"`))
+			continue
+		}
+		hasWildcard = hasWildcard || strings.HasPrefix(h, "*")
+		errs = append(errs, validateWildcardHost(p, h)...)
+	}
+	for i, a := range se.Addresses {
+		errs = append(errs, validateIPOrCIDR(NewPath("addresses").Index(i), a)...)
+	}
+
+	seenPortNames := map[string]bool{}
+	seenPortNumbers := map[uint32]bool{}
+	for i, port := range se.Ports {
+		p := NewPath("ports").Index(i)
+		if port == nil {
+			errs = append(errs, Required(p, "port must not be nil"))
+			continue
+		}
+		errs = append(errs, validatePort(p.Child("number"), port.Number)...)
+		if port.TargetPort != 0 {
+			errs = append(errs, validatePort(p.Child("targetPort"), port.TargetPort)...)
+		}
+		if port.Protocol != "" {
+			errs = append(errs, validateProtocol(p.Child("protocol"), port.Protocol)...)
+		}
+		if port.Name == "" {
+			errs = append(errs, Required(p.Child("name"), "port name must be set"))
+		} else if seenPortNames[port.Name] {
+			errs = append(errs, Duplicate(p.Child("name"), port.Name))
+		}
+		if seenPortNumbers[port.Number] {
+			errs = append(errs, Duplicate(p.Child("number"), port.Number))
+		}
+		seenPortNames[port.Name] = true
+		seenPortNumbers[port.Number] = true
+	}
+
+	if len(se.Endpoints) > 0 && se.WorkloadSelector != nil {
+		errs = append(errs, Forbidden(NewPath("workloadSelector"), "endpoints and workloadSelector must not be set together"))
+	}
+	if se.WorkloadSelector != nil {
+		errs = append(errs, validateLabels(NewPath("workloadSelector").Child("labels"), se.WorkloadSelector.Labels)...)
+	}
+
+	switch se.Resolution {
+	case networking.ServiceEntry_NONE:
+		if len(se.Endpoints) > 0 {
+			errs = append(errs, Forbidden(NewPath("endpoints"), "endpoints must not be set when resolution is NONE"))
+		}
+	case networking.ServiceEntry_STATIC:
+		for i, ep := range se.Endpoints {
+			p := NewPath("endpoints").Index(i)
+			if ep == nil {
+				continue
+			}
+			if _, err := netip.ParseAddr(ep.Address); err != nil && !strings.HasPrefix(ep.Address, "unix://") {
+				errs = append(errs, Invalid(p.Child("address"), ep.Address, "endpoints must be IP addresses or unix domain sockets when resolution is STATIC"))
+			}
+		}
+	case networking.ServiceEntry_DNS, networking.ServiceEntry_DNS_ROUND_ROBIN:
+		if len(se.Endpoints) == 0 && hasWildcard {
+			errs = append(errs, Invalid(NewPath("resolution"), se.Resolution.String(), "wildcard hosts require endpoints when resolution is DNS"))
+		}
+		if se.Resolution == networking.ServiceEntry_DNS_ROUND_ROBIN && len(se.Endpoints) > 1 {
+			errs = append(errs, Invalid(NewPath("endpoints"), len(se.Endpoints), "at most one endpoint is allowed when resolution is DNS_ROUND_ROBIN"))
+		}
+		for i, ep := range se.Endpoints {
+			if ep != nil && ep.Address != "" {
+				errs = append(errs, validateHostOrIP(NewPath("endpoints").Index(i).Child("address"), ep.Address)...)
+			}
+		}
+	case networking.ServiceEntry_DYNAMIC_DNS:
+		if len(se.Endpoints) > 0 {
+			errs = append(errs, Forbidden(NewPath("endpoints"), "endpoints must not be set when resolution is DYNAMIC_DNS"))
+		}
+		if !hasWildcard {
+			errs = append(errs, Invalid(NewPath("resolution"), se.Resolution.String(), "DYNAMIC_DNS requires wildcard hosts"))
+		}
+	}
+
+	for i, ep := range se.Endpoints {
+		p := NewPath("endpoints").Index(i)
+		if ep == nil {
+			errs = append(errs, Required(p, "endpoint must not be nil"))
+			continue
+		}
+		if ep.Address == "" && ep.Network == "" {
+			errs = append(errs, Required(p.Child("address"), "address must be set unless a network is specified"))
+		}
+		for _, name := range slices.Sorted(maps.Keys(ep.Ports)) {
+			pp := p.Child("ports").Key(name)
+			if !seenPortNames[name] {
+				errs = append(errs, Invalid(pp, name, "port name does not match any port declared in the ServiceEntry"))
+			}
+			errs = append(errs, validatePort(pp, ep.Ports[name])...)
+		}
+		errs = append(errs, validateLabels(p.Child("labels"), ep.Labels)...)
+	}
+	errs = append(errs, validateExportTo(NewPath("exportTo"), se.ExportTo)...)
+	return errs
+}
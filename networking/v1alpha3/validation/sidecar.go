@@ -0,0 +1,151 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validation
+
+import (
+	"net"
+	"strconv"
+	"strings"
+
+	networking "istio.io/api/networking/v1alpha3"
+)
+
+// ValidateSidecar checks the semantic validity of a Sidecar spec.
+func ValidateSidecar(sc *networking.Sidecar) ErrorList {
+	if sc == nil {
+		return ErrorList{Required(NewPath(""), "spec must not be nil")}
+	}
+	var errs ErrorList
+	if sc.WorkloadSelector != nil {
+		p := NewPath("workloadSelector").Child("labels")
+		if len(sc.WorkloadSelector.Labels) == 0 {
+			errs = append(errs, Required(p, "workload selector must have at least one label"))
+		}
+		errs = append(errs, validateLabels(p, sc.WorkloadSelector.Labels)...)
+	}
+
+	seenIngressPorts := map[uint32]bool{}
+	for i, in := range sc.Ingress {
+		p := NewPath("ingress").Index(i)
+		if in == nil {
+			errs = append(errs, Required(p, "ingress listener must not be nil"))
+			continue
+		}
+		if in.Port == nil {
+			errs = append(errs, Required(p.Child("port"), "port must be set"))
+		} else {
+			errs = append(errs, validateSidecarPort(p.Child("port"), in.Port)...)
+			if seenIngressPorts[in.Port.Number] {
+				errs = append(errs, Duplicate(p.Child("port").Child("number"), in.Port.Number))
+			}
+			seenIngressPorts[in.Port.Number] = true
+		}
+		if in.Bind != "" {
+			errs = append(errs, validateIPOrCIDR(p.Child("bind"), in.Bind)...)
+		}
+		if in.DefaultEndpoint != "" {
+			errs = append(errs, validateDefaultEndpoint(p.Child("defaultEndpoint"), in.DefaultEndpoint)...)
+		}
+		if in.Tls != nil && in.Port != nil {
+			errs = append(errs, validateServerTLSSettings(p.Child("tls"), in.Tls, strings.ToUpper(in.Port.Protocol))...)
+		}
+		errs = append(errs, validateConnectionPool(p.Child("connectionPool"), in.ConnectionPool)...)
+	}
+
+	if len(sc.Egress) == 0 && len(sc.Ingress) == 0 && sc.OutboundTrafficPolicy == nil && sc.InboundConnectionPool == nil {
+		errs = append(errs, Required(NewPath("egress"), "at least one of egress, ingress, outboundTrafficPolicy or inboundConnectionPool must be set"))
+	}
+	catchAll := false
+	seenEgressPorts := map[uint32]bool{}
+	for i, eg := range sc.Egress {
+		p := NewPath("egress").Index(i)
+		if eg == nil {
+			errs = append(errs, Required(p, "egress listener must not be nil"))
+			continue
+		}
+		if eg.Port == nil {
+			// Only a single listener may capture all ports, and it must come last.
+			if catchAll {
+				errs = append(errs, Duplicate(p.Child("port"), nil))
+			}
+			if i != len(sc.Egress)-1 {
+				errs = append(errs, Invalid(p, nil, "the egress listener without a port must be the last listener"))
+			}
+			catchAll = true
+		} else {
+			errs = append(errs, validateSidecarPort(p.Child("port"), eg.Port)...)
+			if seenEgressPorts[eg.Port.Number] {
+				errs = append(errs, Duplicate(p.Child("port").Child("number"), eg.Port.Number))
+			}
+			seenEgressPorts[eg.Port.Number] = true
+		}
+		if eg.Bind != "" {
+			errs = append(errs, validateIPOrCIDR(p.Child("bind"), eg.Bind)...)
+		}
+		if len(eg.Hosts) == 0 {
+			errs = append(errs, Required(p.Child("hosts"), "at least one host must be set"))
+		}
+		for j, h := range eg.Hosts {
+			errs = append(errs, validateNamespacedHost(p.Child("hosts").Index(j), h, true)...)
+		}
+	}
+
+	if otp := sc.OutboundTrafficPolicy; otp != nil && otp.EgressProxy != nil {
+		p := NewPath("outboundTrafficPolicy").Child("egressProxy")
+		if otp.Mode != networking.OutboundTrafficPolicy_ALLOW_ANY {
+			errs = append(errs, Forbidden(p, "egressProxy may only be set in ALLOW_ANY mode"))
+		}
+		errs = append(errs, validateDestination(p, otp.EgressProxy)...)
+	}
+	errs = append(errs, validateConnectionPool(NewPath("inboundConnectionPool"), sc.InboundConnectionPool)...)
+	return errs
+}
+
+func validateSidecarPort(p Path, port *networking.SidecarPort) ErrorList {
+	var errs ErrorList
+	errs = append(errs, validatePort(p.Child("number"), port.Number)...)
+	if port.TargetPort != 0 {
+		errs = append(errs, validatePort(p.Child("targetPort"), port.TargetPort)...)
+	}
+	if port.Protocol != "" {
+		errs = append(errs, validateProtocol(p.Child("protocol"), port.Protocol)...)
+	}
+	return errs
+}
+
+// validateDefaultEndpoint checks the ingress listener default endpoint, which is either
+// 127.0.0.1:PORT, [::1]:PORT, 0.0.0.0:PORT, [::]:PORT or unix:///path/to/socket.
+func validateDefaultEndpoint(p Path, endpoint string) ErrorList {
+	if strings.HasPrefix(endpoint, "unix://") {
+		if len(endpoint) == len("unix://") {
+			return ErrorList{Invalid(p, endpoint, "unix domain socket path must not be empty")}
+		}
+		return nil
+	}
+	host, port, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return ErrorList{Invalid(p, endpoint, err.Error())}
+	}
+	switch host {
+	case "127.0.0.1", "::1", "0.0.0.0", "::":
+	default:
+		return ErrorList{Invalid(p, endpoint, "host must be one of 127.0.0.1, ::1, 0.0.0.0 or ::")}
+	}
+	n, err := strconv.Atoi(port)
+	if err != nil || n <= 0 || n > 65535 {
+		return ErrorList{Invalid(p, endpoint, "port must be between 1 and 65535")}
+	}
+	return nil
+}
@@ -0,0 +1,348 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package validation provides semantic validation for networking/v1alpha3 resources.
+//
+// The CRD OpenAPI schema covers the structure of each resource; this package covers
+// the cross-field rules that the schema cannot express, such as route weights that
+// do not add up or retry policies Envoy does not understand. Validation does not
+// depend on Kubernetes or istiod, so it can be used directly by controllers and CI.
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/netip"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"istio.io/api/networking/v1alpha3/host"
+)
+
+// ErrorType describes the kind of validation failure.
+type ErrorType string
+
+const (
+	ErrorTypeRequired     ErrorType = "Required value"
+	ErrorTypeInvalid      ErrorType = "Invalid value"
+	ErrorTypeDuplicate    ErrorType = "Duplicate value"
+	ErrorTypeForbidden    ErrorType = "Forbidden"
+	ErrorTypeNotSupported ErrorType = "Unsupported value"
+)
+
+// Error is a validation failure for a single field.
+type Error struct {
+	// Type of the failure.
+	Type ErrorType
+	// Field is the path to the offending field, for example "http[0].route[1].weight".
+	Field string
+	// BadValue is the offending value, if any.
+	BadValue any
+	// Detail is a human readable explanation of the failure.
+	Detail string
+}
+
+func (e *Error) Error() string {
+	s := fmt.Sprintf("%s: %s", e.Field, e.Type)
+	if e.BadValue != nil {
+		s += fmt.Sprintf(": %v", e.BadValue)
+	}
+	if e.Detail != "" {
+		s += ": " + e.Detail
+	}
+	return s
+}
+
+// ErrorList is a list of validation failures.
+type ErrorList []*Error
+
+// ToAggregate returns all errors in the list joined into a single error, or nil if the list is empty.
+func (l ErrorList) ToAggregate() error {
+	if len(l) == 0 {
+		return nil
+	}
+	errs := make([]error, 0, len(l))
+	for _, e := range l {
+		errs = append(errs, e)
+	}
+	return errors.Join(errs...)
+}
+
+// Path is the location of a field within a resource spec.
+type Path string
+
+// NewPath returns a root path for the given field name.
+func NewPath(name string) Path {
+	return Path(name)
+}
+
+// Child returns the path of the named field under p.
+func (p Path) Child(name string) Path {
+	if p == "" {
+		return Path(name)
+	}
+	return p + "." + Path(name)
+}
+
+// Index returns the path of the i-th element of the list at p.
+func (p Path) Index(i int) Path {
+	return p + Path("["+strconv.Itoa(i)+"]")
+}
+
+// Key returns the path of the entry with the given key in the map at p.
+func (p Path) Key(key string) Path {
+	return p + Path("["+key+"]")
+}
+
+func (p Path) String() string {
+	return string(p)
+}
+
+// Required returns an error indicating a required field was not set.
+func Required(p Path, detail string) *Error {
+	return &Error{Type: ErrorTypeRequired, Field: p.String(), Detail: detail}
+}
+
+// Invalid returns an error indicating a field holds an invalid value.
+func Invalid(p Path, value any, detail string) *Error {
+	return &Error{Type: ErrorTypeInvalid, Field: p.String(), BadValue: value, Detail: detail}
+}
+
+// Duplicate returns an error indicating a value was repeated where it must be unique.
+func Duplicate(p Path, value any) *Error {
+	return &Error{Type: ErrorTypeDuplicate, Field: p.String(), BadValue: value}
+}
+
+// Forbidden returns an error indicating a field must not be set.
+func Forbidden(p Path, detail string) *Error {
+	return &Error{Type: ErrorTypeForbidden, Field: p.String(), Detail: detail}
+}
+
+// NotSupported returns an error indicating a value is not one of the supported values.
+func NotSupported(p Path, value any, valid []string) *Error {
+	detail := ""
+	if len(valid) > 0 {
+		detail = "supported values: " + strings.Join(valid, ", ")
+	}
+	return &Error{Type: ErrorTypeNotSupported, Field: p.String(), BadValue: value, Detail: detail}
+}
+
+const (
+	dns1123LabelMaxLength  = 63
+	dns1123LabelFmt        = "[a-zA-Z0-9](?:[-a-zA-Z0-9]*[a-zA-Z0-9])?"
+	qualifiedNameMaxLength = 63
+	qualifiedNameFmt       = "(?:[A-Za-z0-9](?:[-A-Za-z0-9_.]*[A-Za-z0-9])?)"
+)
+
+var (
+	dns1123LabelRegexp  = regexp.MustCompile("^" + dns1123LabelFmt + "$")
+	qualifiedNameRegexp = regexp.MustCompile("^" + qualifiedNameFmt + "$")
+)
+
+// Istio protocol names, as accepted in Gateway, Sidecar and ServiceEntry ports.
+var supportedProtocols = []string{
+	"GRPC", "GRPC-Web", "HTTP", "HTTP2", "HTTPS", "Mongo", "MySQL", "Redis", "TCP", "TLS", "UDP",
+}
+
+func isDNS1123Label(s string) bool {
+	return len(s) <= dns1123LabelMaxLength && dns1123LabelRegexp.MatchString(s)
+}
+
+// validateFQDN checks a non wildcard host name.
+func validateFQDN(p Path, h string) ErrorList {
+	if _, err := host.ParseFQDN(h); err != nil {
+		return ErrorList{Invalid(p, h, err.Error())}
+	}
+	return nil
+}
+
+// validateWildcardHost checks a host which may carry a leading wildcard, such as "*.example.com" or "*".
+func validateWildcardHost(p Path, h string) ErrorList {
+	if _, err := host.Parse(h); err != nil {
+		if errors.Is(err, host.ErrEmpty) {
+			return ErrorList{Required(p, err.Error())}
+		}
+		return ErrorList{Invalid(p, h, err.Error())}
+	}
+	return nil
+}
+
+// validateHostOrIP checks a host which may be a wildcard name or an IP address.
+func validateHostOrIP(p Path, h string) ErrorList {
+	if _, err := netip.ParseAddr(h); err == nil {
+		return nil
+	}
+	return validateWildcardHost(p, h)
+}
+
+// validateNamespacedHost checks hosts in the "namespace/dnsName" form used by Gateway servers
+// and Sidecar egress listeners. Gateway servers may omit the namespace.
+func validateNamespacedHost(p Path, h string, requireNamespace bool) ErrorList {
+	_, err := host.ParseNamespaced(h, requireNamespace)
+	if err == nil {
+		return nil
+	}
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+	var out ErrorList
+	for _, err := range errs {
+		if errors.Is(err, host.ErrEmpty) {
+			out = append(out, Required(p, err.Error()))
+		} else {
+			out = append(out, Invalid(p, h, err.Error()))
+		}
+	}
+	return out
+}
+
+// validateGatewayName checks a reference to a gateway, which is either "mesh" or "[namespace/]name".
+func validateGatewayName(p Path, gw string) ErrorList {
+	if gw == "mesh" {
+		return nil
+	}
+	ns, name, found := strings.Cut(gw, "/")
+	if !found {
+		name = gw
+	} else if !isDNS1123Label(ns) {
+		return ErrorList{Invalid(p, gw, fmt.Sprintf("invalid namespace %q", ns))}
+	}
+	if name == "" {
+		return ErrorList{Invalid(p, gw, "gateway name must not be empty")}
+	}
+	return validateFQDN(p, name)
+}
+
+// validateSubnets checks a list of IP addresses or CIDR blocks.
+func validateSubnets(p Path, subnets []string) ErrorList {
+	var errs ErrorList
+	for i, s := range subnets {
+		errs = append(errs, validateIPOrCIDR(p.Index(i), s)...)
+	}
+	return errs
+}
+
+func validateIPOrCIDR(p Path, s string) ErrorList {
+	if strings.Contains(s, "/") {
+		if _, err := netip.ParsePrefix(s); err != nil {
+			return ErrorList{Invalid(p, s, "must be a valid CIDR block")}
+		}
+		return nil
+	}
+	if _, err := netip.ParseAddr(s); err != nil {
+		return ErrorList{Invalid(p, s, "must be a valid IP address")}
+	}
+	return nil
+}
+
+func validatePort(p Path, port uint32) ErrorList {
+	if port == 0 || port > 65535 {
+		return ErrorList{Invalid(p, port, "must be between 1 and 65535")}
+	}
+	return nil
+}
+
+func validateProtocol(p Path, protocol string) ErrorList {
+	for _, proto := range supportedProtocols {
+		if strings.EqualFold(proto, protocol) {
+			return nil
+		}
+	}
+	return ErrorList{NotSupported(p, protocol, supportedProtocols)}
+}
+
+func validatePercent(p Path, v float64) ErrorList {
+	if v < 0 || v > 100 {
+		return ErrorList{Invalid(p, v, "must be between 0 and 100")}
+	}
+	return nil
+}
+
+// validateDuration checks that d, when set, is well-formed and at least min.
+func validateDuration(p Path, d *durationpb.Duration, min time.Duration) ErrorList {
+	if d == nil {
+		return nil
+	}
+	if err := d.CheckValid(); err != nil {
+		return ErrorList{Invalid(p, d.String(), err.Error())}
+	}
+	if d.AsDuration() < min {
+		return ErrorList{Invalid(p, d.AsDuration().String(), fmt.Sprintf("must be at least %v", min))}
+	}
+	return nil
+}
+
+// validateLabels checks label selectors, following the Kubernetes label syntax.
+func validateLabels(p Path, labels map[string]string) ErrorList {
+	var errs ErrorList
+	for _, k := range slices.Sorted(maps.Keys(labels)) {
+		v := labels[k]
+		errs = append(errs, validateQualifiedName(p.Key(k), k)...)
+		if v != "" && (len(v) > qualifiedNameMaxLength || !qualifiedNameRegexp.MatchString(v)) {
+			errs = append(errs, Invalid(p.Key(k), v, "label values must be 63 characters or less, "+
+				"consist of alphanumeric characters, '-', '_' or '.', and start and end with an alphanumeric character"))
+		}
+	}
+	return errs
+}
+
+func validateQualifiedName(p Path, name string) ErrorList {
+	prefix, local, found := strings.Cut(name, "/")
+	if !found {
+		local = name
+	} else if errs := validateFQDN(p, prefix); len(errs) > 0 {
+		return errs
+	}
+	if len(local) > qualifiedNameMaxLength || !qualifiedNameRegexp.MatchString(local) {
+		return ErrorList{Invalid(p, name, "name part must be 63 characters or less, consist of alphanumeric "+
+			"characters, '-', '_' or '.', and start and end with an alphanumeric character")}
+	}
+	return nil
+}
+
+// validateExportTo checks the exportTo field shared by VirtualService, DestinationRule and ServiceEntry.
+func validateExportTo(p Path, exportTo []string) ErrorList {
+	var errs ErrorList
+	seen := map[string]bool{}
+	for i, e := range exportTo {
+		ip := p.Index(i)
+		if seen[e] {
+			errs = append(errs, Duplicate(ip, e))
+			continue
+		}
+		seen[e] = true
+		switch e {
+		case ".", "*":
+		case "~":
+			if len(exportTo) > 1 {
+				errs = append(errs, Invalid(ip, e, `"~" must be the only entry in exportTo`))
+			}
+		default:
+			if !isDNS1123Label(e) {
+				errs = append(errs, Invalid(ip, e, "must be '.', '*', '~' or a namespace name"))
+			}
+		}
+	}
+	if seen["*"] && len(exportTo) > 1 {
+		errs = append(errs, Invalid(p, exportTo, `"*" must be the only entry in exportTo`))
+	}
+	return errs
+}
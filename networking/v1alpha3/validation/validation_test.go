@@ -0,0 +1,614 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	networking "istio.io/api/networking/v1alpha3"
+)
+
+// checkErrors verifies that errs is empty when want is empty, and otherwise that some error
+// is reported for the field want.
+func checkErrors(t *testing.T, errs ErrorList, want string) {
+	t.Helper()
+	if want == "" {
+		if len(errs) > 0 {
+			t.Fatalf("expected no errors, got %v", errs.ToAggregate())
+		}
+		return
+	}
+	for _, e := range errs {
+		if e.Field == want {
+			return
+		}
+	}
+	t.Fatalf("expected an error for field %q, got %v", want, errs.ToAggregate())
+}
+
+func destination(host string, weight int32) *networking.HTTPRouteDestination {
+	return &networking.HTTPRouteDestination{Destination: &networking.Destination{Host: host}, Weight: weight}
+}
+
+func TestValidateVirtualService(t *testing.T) {
+	cases := []struct {
+		name  string
+		vs    *networking.VirtualService
+		field string
+	}{
+		{
+			name: "valid",
+			vs: &networking.VirtualService{
+				Hosts:    []string{"reviews.default.svc.cluster.local"},
+				Gateways: []string{"mesh", "istio-system/ingress"},
+				Http: []*networking.HTTPRoute{{
+					Match: []*networking.HTTPMatchRequest{{
+						Uri:     &networking.StringMatch{MatchType: &networking.StringMatch_Prefix{Prefix: "/api"}},
+						Headers: map[string]*networking.StringMatch{"x-user": {MatchType: &networking.StringMatch_Exact{Exact: "jason"}}},
+					}},
+					Route:   []*networking.HTTPRouteDestination{destination("reviews", 75), destination("ratings", 25)},
+					Timeout: durationpb.New(0),
+					Retries: &networking.HTTPRetry{Attempts: 3, RetryOn: "5xx,connect-failure,503"},
+				}},
+			},
+		},
+		{
+			name: "weights do not sum to 100",
+			vs: &networking.VirtualService{
+				Hosts: []string{"reviews"},
+				Http: []*networking.HTTPRoute{{
+					Route: []*networking.HTTPRouteDestination{destination("reviews", 50), destination("ratings", 20)},
+				}},
+			},
+			field: "http[0].route",
+		},
+		{
+			name: "unknown retry policy",
+			vs: &networking.VirtualService{
+				Hosts: []string{"reviews"},
+				Http: []*networking.HTTPRoute{{
+					Route:   []*networking.HTTPRouteDestination{destination("reviews", 0)},
+					Retries: &networking.HTTPRetry{Attempts: 3, RetryOn: "5xx,retry-forever"},
+				}},
+			},
+			field: "http[0].retries.retryOn",
+		},
+		{
+			// istiod accepts ignoreUriCase with uri.regex.
+			name: "regex with ignoreUriCase",
+			vs: &networking.VirtualService{
+				Hosts: []string{"reviews"},
+				Http: []*networking.HTTPRoute{{
+					Match: []*networking.HTTPMatchRequest{{
+						Uri:           &networking.StringMatch{MatchType: &networking.StringMatch_Regex{Regex: "/api/.*"}},
+						IgnoreUriCase: true,
+					}},
+					Route: []*networking.HTTPRouteDestination{destination("reviews", 0)},
+				}},
+			},
+		},
+		{
+			name: "invalid regex",
+			vs: &networking.VirtualService{
+				Hosts: []string{"reviews"},
+				Http: []*networking.HTTPRoute{{
+					Match: []*networking.HTTPMatchRequest{{
+						Uri: &networking.StringMatch{MatchType: &networking.StringMatch_Regex{Regex: "(?<=a)b"}},
+					}},
+					Route: []*networking.HTTPRouteDestination{destination("reviews", 0)},
+				}},
+			},
+			field: "http[0].match[0].uri.regex",
+		},
+		{
+			name: "route and redirect",
+			vs: &networking.VirtualService{
+				Hosts: []string{"reviews"},
+				Http: []*networking.HTTPRoute{{
+					Route:    []*networking.HTTPRouteDestination{destination("reviews", 0)},
+					Redirect: &networking.HTTPRedirect{Uri: "/v2"},
+				}},
+			},
+			field: "http[0]",
+		},
+		{
+			name: "delegate with gateways",
+			vs: &networking.VirtualService{
+				Gateways: []string{"ingress"},
+				Http: []*networking.HTTPRoute{{
+					Route: []*networking.HTTPRouteDestination{destination("reviews", 0)},
+				}},
+			},
+			field: "gateways",
+		},
+		{
+			name: "uppercase header match",
+			vs: &networking.VirtualService{
+				Hosts: []string{"reviews"},
+				Http: []*networking.HTTPRoute{{
+					Match: []*networking.HTTPMatchRequest{{
+						Headers: map[string]*networking.StringMatch{"X-User": {MatchType: &networking.StringMatch_Exact{Exact: "jason"}}},
+					}},
+					Route: []*networking.HTTPRouteDestination{destination("reviews", 0)},
+				}},
+			},
+			field: "http[0].match[0].headers[X-User]",
+		},
+		{
+			name: "tls route without sni",
+			vs: &networking.VirtualService{
+				Hosts: []string{"*.example.com"},
+				Tls: []*networking.TLSRoute{{
+					Match: []*networking.TLSMatchAttributes{{Port: 443}},
+					Route: []*networking.RouteDestination{{Destination: &networking.Destination{Host: "example.com"}}},
+				}},
+			},
+			field: "tls[0].match[0].sniHosts",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			checkErrors(t, ValidateVirtualService(tt.vs), tt.field)
+		})
+	}
+}
+
+func TestValidateDestinationRule(t *testing.T) {
+	cases := []struct {
+		name  string
+		dr    *networking.DestinationRule
+		field string
+	}{
+		{
+			name: "valid",
+			dr: &networking.DestinationRule{
+				Host: "reviews.default.svc.cluster.local",
+				TrafficPolicy: &networking.TrafficPolicy{
+					Tls: &networking.ClientTLSSettings{Mode: networking.ClientTLSSettings_ISTIO_MUTUAL},
+				},
+				Subsets: []*networking.Subset{{Name: "v1", Labels: map[string]string{"version": "v1"}}},
+			},
+		},
+		{
+			name: "duplicate subset",
+			dr: &networking.DestinationRule{
+				Host:    "reviews",
+				Subsets: []*networking.Subset{{Name: "v1"}, {Name: "v1"}},
+			},
+			field: "subsets[1].name",
+		},
+		{
+			name: "mutual without certificates",
+			dr: &networking.DestinationRule{
+				Host: "reviews",
+				TrafficPolicy: &networking.TrafficPolicy{
+					Tls: &networking.ClientTLSSettings{Mode: networking.ClientTLSSettings_MUTUAL},
+				},
+			},
+			field: "trafficPolicy.tls.clientCertificate",
+		},
+		{
+			name: "duplicate port level settings",
+			dr: &networking.DestinationRule{
+				Host: "reviews",
+				TrafficPolicy: &networking.TrafficPolicy{
+					PortLevelSettings: []*networking.TrafficPolicy_PortTrafficPolicy{
+						{Port: &networking.PortSelector{Number: 80}},
+						{Port: &networking.PortSelector{Number: 80}},
+					},
+				},
+			},
+			field: "trafficPolicy.portLevelSettings[1].port.number",
+		},
+		{
+			name: "ejection percent out of range",
+			dr: &networking.DestinationRule{
+				Host: "reviews",
+				TrafficPolicy: &networking.TrafficPolicy{
+					OutlierDetection: &networking.OutlierDetection{MaxEjectionPercent: 150},
+				},
+			},
+			field: "trafficPolicy.outlierDetection.maxEjectionPercent",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			checkErrors(t, ValidateDestinationRule(tt.dr), tt.field)
+		})
+	}
+}
+
+func TestValidateGateway(t *testing.T) {
+	cases := []struct {
+		name  string
+		gw    *networking.Gateway
+		field string
+	}{
+		{
+			name: "valid",
+			gw: &networking.Gateway{
+				Selector: map[string]string{"istio": "ingressgateway"},
+				Servers: []*networking.Server{
+					{
+						Port:  &networking.Port{Number: 80, Name: "http", Protocol: "HTTP"},
+						Hosts: []string{"bookinfo/*.example.com"},
+						Tls:   &networking.ServerTLSSettings{HttpsRedirect: true},
+					},
+					{
+						Port:  &networking.Port{Number: 443, Name: "https", Protocol: "HTTPS"},
+						Hosts: []string{"*.example.com"},
+						Tls:   &networking.ServerTLSSettings{Mode: networking.ServerTLSSettings_SIMPLE, CredentialName: "example-cert"},
+					},
+				},
+			},
+		},
+		{
+			name: "https without tls",
+			gw: &networking.Gateway{
+				Servers: []*networking.Server{{
+					Port:  &networking.Port{Number: 443, Name: "https", Protocol: "HTTPS"},
+					Hosts: []string{"*"},
+				}},
+			},
+			field: "servers[0].tls",
+		},
+		{
+			name: "unknown protocol",
+			gw: &networking.Gateway{
+				Servers: []*networking.Server{{
+					Port:  &networking.Port{Number: 80, Name: "http", Protocol: "HTTP3"},
+					Hosts: []string{"*"},
+				}},
+			},
+			field: "servers[0].port.protocol",
+		},
+		{
+			name: "invalid host namespace",
+			gw: &networking.Gateway{
+				Servers: []*networking.Server{{
+					Port:  &networking.Port{Number: 80, Name: "http", Protocol: "HTTP"},
+					Hosts: []string{"Bad_NS/*"},
+				}},
+			},
+			field: "servers[0].hosts[0]",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			checkErrors(t, ValidateGateway(tt.gw), tt.field)
+		})
+	}
+}
+
+func TestValidateSidecar(t *testing.T) {
+	cases := []struct {
+		name  string
+		sc    *networking.Sidecar
+		field string
+	}{
+		{
+			name: "valid",
+			sc: &networking.Sidecar{
+				Ingress: []*networking.IstioIngressListener{{
+					Port:            &networking.SidecarPort{Number: 9080, Protocol: "HTTP", Name: "http"},
+					DefaultEndpoint: "127.0.0.1:8080",
+				}},
+				Egress: []*networking.IstioEgressListener{{Hosts: []string{"./*", "istio-system/*"}}},
+			},
+		},
+		{
+			name: "egress host without namespace",
+			sc: &networking.Sidecar{
+				Egress: []*networking.IstioEgressListener{{Hosts: []string{"*.example.com"}}},
+			},
+			field: "egress[0].hosts[0]",
+		},
+		{
+			name: "catch all egress not last",
+			sc: &networking.Sidecar{
+				Egress: []*networking.IstioEgressListener{
+					{Hosts: []string{"*/*"}},
+					{Port: &networking.SidecarPort{Number: 80, Protocol: "HTTP"}, Hosts: []string{"*/*"}},
+				},
+			},
+			field: "egress[0]",
+		},
+		{
+			name: "bad default endpoint",
+			sc: &networking.Sidecar{
+				Ingress: []*networking.IstioIngressListener{{
+					Port:            &networking.SidecarPort{Number: 9080, Protocol: "HTTP"},
+					DefaultEndpoint: "10.0.0.1:8080",
+				}},
+			},
+			field: "ingress[0].defaultEndpoint",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			checkErrors(t, ValidateSidecar(tt.sc), tt.field)
+		})
+	}
+}
+
+func TestValidateServiceEntry(t *testing.T) {
+	cases := []struct {
+		name  string
+		se    *networking.ServiceEntry
+		field string
+	}{
+		{
+			name: "valid",
+			se: &networking.ServiceEntry{
+				Hosts:      []string{"api.example.com"},
+				Ports:      []*networking.ServicePort{{Number: 443, Name: "https", Protocol: "TLS"}},
+				Resolution: networking.ServiceEntry_DNS,
+				Location:   networking.ServiceEntry_MESH_EXTERNAL,
+			},
+		},
+		{
+			name: "wildcard dns without endpoints",
+			se: &networking.ServiceEntry{
+				Hosts:      []string{"*.example.com"},
+				Ports:      []*networking.ServicePort{{Number: 443, Name: "https", Protocol: "TLS"}},
+				Resolution: networking.ServiceEntry_DNS,
+			},
+			field: "resolution",
+		},
+		{
+			name: "static endpoint with hostname",
+			se: &networking.ServiceEntry{
+				Hosts:      []string{"db.internal"},
+				Ports:      []*networking.ServicePort{{Number: 5432, Name: "tcp", Protocol: "TCP"}},
+				Resolution: networking.ServiceEntry_STATIC,
+				Endpoints:  []*networking.WorkloadEntry{{Address: "db.example.com"}},
+			},
+			field: "endpoints[0].address",
+		},
+		{
+			name: "endpoint references unknown port",
+			se: &networking.ServiceEntry{
+				Hosts:      []string{"db.internal"},
+				Ports:      []*networking.ServicePort{{Number: 5432, Name: "tcp", Protocol: "TCP"}},
+				Resolution: networking.ServiceEntry_STATIC,
+				Endpoints:  []*networking.WorkloadEntry{{Address: "10.0.0.1", Ports: map[string]uint32{"postgres": 5433}}},
+			},
+			field: "endpoints[0].ports[postgres]",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			checkErrors(t, ValidateServiceEntry(tt.se), tt.field)
+		})
+	}
+}
+
+func envoyFilterPatch(applyTo networking.EnvoyFilter_ApplyTo, op networking.EnvoyFilter_Patch_Operation,
+	value map[string]any,
+) *networking.EnvoyFilter_EnvoyConfigObjectPatch {
+	cp := &networking.EnvoyFilter_EnvoyConfigObjectPatch{
+		ApplyTo: applyTo,
+		Patch:   &networking.EnvoyFilter_Patch{Operation: op},
+	}
+	if value != nil {
+		v, err := structpb.NewStruct(value)
+		if err != nil {
+			panic(err)
+		}
+		cp.Patch.Value = v
+	}
+	return cp
+}
+
+func alpnFilter(config map[string]any) map[string]any {
+	config["@type"] = "type.googleapis.com/istio.envoy.config.filter.http.alpn.v2alpha1.FilterConfig"
+	return map[string]any{"name": "istio.alpn", "typed_config": config}
+}
+
+func TestValidateEnvoyFilter(t *testing.T) {
+	alpnOverride := map[string]any{"alpnOverride": []any{
+		map[string]any{"upstreamProtocol": "HTTP2", "alpn_override": []any{"h2"}},
+	}}
+	cases := []struct {
+		name  string
+		ef    *networking.EnvoyFilter
+		field string
+	}{
+		{
+			name: "valid",
+			ef: &networking.EnvoyFilter{
+				WorkloadSelector: &networking.WorkloadSelector{Labels: map[string]string{"app": "reviews"}},
+				ConfigPatches: []*networking.EnvoyFilter_EnvoyConfigObjectPatch{
+					envoyFilterPatch(networking.EnvoyFilter_HTTP_FILTER, networking.EnvoyFilter_Patch_INSERT_BEFORE, alpnFilter(alpnOverride)),
+					envoyFilterPatch(networking.EnvoyFilter_CLUSTER, networking.EnvoyFilter_Patch_REMOVE, nil),
+					envoyFilterPatch(networking.EnvoyFilter_NETWORK_FILTER, networking.EnvoyFilter_Patch_ADD, map[string]any{
+						"name": "istio.stats",
+						"typed_config": map[string]any{
+							"@type":    "type.googleapis.com/udpa.type.v1.TypedStruct",
+							"type_url": "type.googleapis.com/stats.PluginConfig",
+							"value":    map[string]any{"tcpReportingDuration": "15s", "metrics": []any{map[string]any{"name": "requests_total"}}},
+						},
+					}),
+					// Configurations of unregistered types are not checked.
+					envoyFilterPatch(networking.EnvoyFilter_HTTP_FILTER, networking.EnvoyFilter_Patch_INSERT_FIRST, map[string]any{
+						"name": "envoy.filters.http.router",
+						"typed_config": map[string]any{
+							"@type":                  "type.googleapis.com/envoy.extensions.filters.http.router.v3.Router",
+							"suppress_envoy_headers": true,
+						},
+					}),
+				},
+			},
+		},
+		{
+			name: "insert on cluster",
+			ef: &networking.EnvoyFilter{ConfigPatches: []*networking.EnvoyFilter_EnvoyConfigObjectPatch{
+				envoyFilterPatch(networking.EnvoyFilter_CLUSTER, networking.EnvoyFilter_Patch_INSERT_BEFORE, map[string]any{"name": "outbound"}),
+			}},
+			field: "configPatches[0].patch.operation",
+		},
+		{
+			name: "replace on listener",
+			ef: &networking.EnvoyFilter{ConfigPatches: []*networking.EnvoyFilter_EnvoyConfigObjectPatch{
+				envoyFilterPatch(networking.EnvoyFilter_LISTENER, networking.EnvoyFilter_Patch_REPLACE, map[string]any{"name": "listener"}),
+			}},
+			field: "configPatches[0].patch.operation",
+		},
+		{
+			name: "missing apply to",
+			ef: &networking.EnvoyFilter{ConfigPatches: []*networking.EnvoyFilter_EnvoyConfigObjectPatch{
+				envoyFilterPatch(networking.EnvoyFilter_INVALID, networking.EnvoyFilter_Patch_MERGE, map[string]any{}),
+			}},
+			field: "configPatches[0].applyTo",
+		},
+		{
+			name: "missing value",
+			ef: &networking.EnvoyFilter{ConfigPatches: []*networking.EnvoyFilter_EnvoyConfigObjectPatch{
+				envoyFilterPatch(networking.EnvoyFilter_HTTP_FILTER, networking.EnvoyFilter_Patch_ADD, nil),
+			}},
+			field: "configPatches[0].patch.value",
+		},
+		{
+			name: "filter class with merge",
+			ef: &networking.EnvoyFilter{ConfigPatches: []*networking.EnvoyFilter_EnvoyConfigObjectPatch{{
+				ApplyTo: networking.EnvoyFilter_HTTP_FILTER,
+				Patch: &networking.EnvoyFilter_Patch{
+					Operation:   networking.EnvoyFilter_Patch_MERGE,
+					FilterClass: networking.EnvoyFilter_Patch_AUTHZ,
+					Value:       &structpb.Struct{},
+				},
+			}}},
+			field: "configPatches[0].patch.filterClass",
+		},
+		{
+			name: "cluster match on http filter",
+			ef: &networking.EnvoyFilter{ConfigPatches: []*networking.EnvoyFilter_EnvoyConfigObjectPatch{{
+				ApplyTo: networking.EnvoyFilter_HTTP_FILTER,
+				Match: &networking.EnvoyFilter_EnvoyConfigObjectMatch{
+					ObjectTypes: &networking.EnvoyFilter_EnvoyConfigObjectMatch_Cluster{Cluster: &networking.EnvoyFilter_ClusterMatch{}},
+				},
+				Patch: &networking.EnvoyFilter_Patch{Operation: networking.EnvoyFilter_Patch_REMOVE},
+			}}},
+			field: "configPatches[0].match",
+		},
+		{
+			name: "unknown field",
+			ef: &networking.EnvoyFilter{ConfigPatches: []*networking.EnvoyFilter_EnvoyConfigObjectPatch{
+				envoyFilterPatch(networking.EnvoyFilter_HTTP_FILTER, networking.EnvoyFilter_Patch_ADD, alpnFilter(map[string]any{"alpnOverides": []any{}})),
+			}},
+			field: "configPatches[0].patch.value.typed_config.alpnOverides",
+		},
+		{
+			name: "unknown nested field",
+			ef: &networking.EnvoyFilter{ConfigPatches: []*networking.EnvoyFilter_EnvoyConfigObjectPatch{
+				envoyFilterPatch(networking.EnvoyFilter_HTTP_FILTER, networking.EnvoyFilter_Patch_ADD, alpnFilter(map[string]any{"alpnOverride": []any{
+					map[string]any{"upstreamProtocol": "HTTP2", "alpn": []any{"h2"}},
+				}})),
+			}},
+			field: "configPatches[0].patch.value.typed_config.alpnOverride[0].alpn",
+		},
+		{
+			name: "unknown http filter field",
+			ef: &networking.EnvoyFilter{ConfigPatches: []*networking.EnvoyFilter_EnvoyConfigObjectPatch{
+				envoyFilterPatch(networking.EnvoyFilter_HTTP_FILTER, networking.EnvoyFilter_Patch_INSERT_BEFORE, map[string]any{
+					"name":         "envoy.filters.http.lua",
+					"typed_conifg": map[string]any{"@type": "type.googleapis.com/envoy.extensions.filters.http.lua.v3.Lua"},
+				}),
+			}},
+			field: "configPatches[0].patch.value.typed_conifg",
+		},
+		{
+			name: "http filter name is not a string",
+			ef: &networking.EnvoyFilter{ConfigPatches: []*networking.EnvoyFilter_EnvoyConfigObjectPatch{
+				envoyFilterPatch(networking.EnvoyFilter_HTTP_FILTER, networking.EnvoyFilter_Patch_MERGE, map[string]any{"name": map[string]any{}}),
+			}},
+			field: "configPatches[0].patch.value.name",
+		},
+		{
+			name: "unknown enum value",
+			ef: &networking.EnvoyFilter{ConfigPatches: []*networking.EnvoyFilter_EnvoyConfigObjectPatch{
+				envoyFilterPatch(networking.EnvoyFilter_HTTP_FILTER, networking.EnvoyFilter_Patch_ADD, alpnFilter(map[string]any{"alpnOverride": []any{
+					map[string]any{"upstreamProtocol": "HTTP3"},
+				}})),
+			}},
+			field: "configPatches[0].patch.value.typed_config.alpnOverride[0].upstreamProtocol",
+		},
+		{
+			name: "wrong field type in typed struct",
+			ef: &networking.EnvoyFilter{ConfigPatches: []*networking.EnvoyFilter_EnvoyConfigObjectPatch{
+				envoyFilterPatch(networking.EnvoyFilter_NETWORK_FILTER, networking.EnvoyFilter_Patch_ADD, map[string]any{
+					"name": "istio.stats",
+					"typed_config": map[string]any{
+						"@type":    "type.googleapis.com/udpa.type.v1.TypedStruct",
+						"type_url": "type.googleapis.com/stats.PluginConfig",
+						"value":    map[string]any{"tcpReportingDuration": 15},
+					},
+				}),
+			}},
+			field: "configPatches[0].patch.value.typed_config.value.tcpReportingDuration",
+		},
+		{
+			name: "nested in unregistered type",
+			ef: &networking.EnvoyFilter{ConfigPatches: []*networking.EnvoyFilter_EnvoyConfigObjectPatch{
+				envoyFilterPatch(networking.EnvoyFilter_EXTENSION_CONFIG, networking.EnvoyFilter_Patch_ADD, map[string]any{
+					"name": "composite",
+					"typed_config": map[string]any{
+						"@type":   "type.googleapis.com/envoy.extensions.filters.http.composite.v3.Composite",
+						"filters": []any{alpnFilter(map[string]any{"debug": true})},
+					},
+				}),
+			}},
+			field: "configPatches[0].patch.value.typed_config.filters[0].typed_config.debug",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			checkErrors(t, ValidateEnvoyFilter(tt.ef), tt.field)
+		})
+	}
+}
+
+func TestRegisterEnvoyFilterType(t *testing.T) {
+	value := map[string]any{"typed_config": map[string]any{
+		"@type": "type.googleapis.com/istio.networking.v1alpha3.Delegate",
+		"nam":   "reviews",
+	}}
+	ef := &networking.EnvoyFilter{ConfigPatches: []*networking.EnvoyFilter_EnvoyConfigObjectPatch{
+		envoyFilterPatch(networking.EnvoyFilter_HTTP_FILTER, networking.EnvoyFilter_Patch_ADD, value),
+	}}
+	checkErrors(t, ValidateEnvoyFilter(ef), "")
+
+	RegisterEnvoyFilterType((&networking.Delegate{}).ProtoReflect().Descriptor())
+	errs := ValidateEnvoyFilter(ef)
+	checkErrors(t, errs, "configPatches[0].patch.value.typed_config.nam")
+	if want := `did you mean "name"?`; !strings.Contains(errs.ToAggregate().Error(), want) {
+		t.Fatalf("expected a suggestion %s, got %v", want, errs.ToAggregate())
+	}
+}
+
+func TestErrorString(t *testing.T) {
+	err := Invalid(NewPath("http").Index(0).Child("route"), 70, "total destination weight must be 100")
+	want := "http[0].route: Invalid value: 70: total destination weight must be 100"
+	if got := err.Error(); got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if !strings.Contains(ErrorList{err}.ToAggregate().Error(), want) {
+		t.Fatalf("aggregate does not contain %q", want)
+	}
+}
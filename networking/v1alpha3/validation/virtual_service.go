@@ -0,0 +1,601 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validation
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	networking "istio.io/api/networking/v1alpha3"
+)
+
+// Retry policies accepted in HTTPRetry.RetryOn, in addition to HTTP status codes.
+// See https://www.envoyproxy.io/docs/envoy/latest/configuration/http/http_filters/router_filter#x-envoy-retry-on
+// and https://www.envoyproxy.io/docs/envoy/latest/configuration/http/http_filters/router_filter#x-envoy-retry-grpc-on.
+var supportedRetryOnPolicies = []string{
+	// HTTP
+	"5xx",
+	"gateway-error",
+	"reset",
+	"reset-before-request",
+	"connect-failure",
+	"envoy-ratelimited",
+	"retriable-4xx",
+	"refused-stream",
+	"retriable-status-codes",
+	"retriable-headers",
+	"http3-post-connect-failure",
+	// gRPC
+	"cancelled",
+	"deadline-exceeded",
+	"internal",
+	"resource-exhausted",
+	"unavailable",
+}
+
+var supportedRedirectCodes = []uint32{301, 302, 303, 307, 308}
+
+// Headers that Envoy does not allow to be modified through header operations.
+var immutableHeaders = []string{"host", ":authority", ":method", ":path", ":scheme", ":status"}
+
+const minTimeout = time.Millisecond
+
+// ValidateVirtualService checks the semantic validity of a VirtualService spec.
+func ValidateVirtualService(vs *networking.VirtualService) ErrorList {
+	if vs == nil {
+		return ErrorList{Required(NewPath(""), "spec must not be nil")}
+	}
+	var errs ErrorList
+	// A VirtualService without hosts can only be used as a delegate, and delegates
+	// are not bound to gateways.
+	isDelegate := len(vs.Hosts) == 0
+	if isDelegate && len(vs.Gateways) > 0 {
+		errs = append(errs, Forbidden(NewPath("gateways"), "delegate virtual services must not set gateways"))
+	}
+
+	seenHosts := map[string]bool{}
+	for i, h := range vs.Hosts {
+		p := NewPath("hosts").Index(i)
+		if seenHosts[h] {
+			errs = append(errs, Duplicate(p, h))
+			continue
+		}
+		seenHosts[h] = true
+		errs = append(errs, validateHostOrIP(p, h)...)
+	}
+
+	seenGateways := map[string]bool{}
+	for i, gw := range vs.Gateways {
+		p := NewPath("gateways").Index(i)
+		if seenGateways[gw] {
+			errs = append(errs, Duplicate(p, gw))
+			continue
+		}
+		seenGateways[gw] = true
+		errs = append(errs, validateGatewayName(p, gw)...)
+	}
+
+	if len(vs.Http) == 0 && len(vs.Tcp) == 0 && len(vs.Tls) == 0 {
+		errs = append(errs, Required(NewPath("http"), "at least one of http, tls or tcp routes must be set"))
+	}
+	if isDelegate && (len(vs.Tcp) > 0 || len(vs.Tls) > 0) {
+		errs = append(errs, Forbidden(NewPath("tcp"), "delegate virtual services only support http routes"))
+	}
+
+	seenRouteNames := map[string]bool{}
+	for i, r := range vs.Http {
+		p := NewPath("http").Index(i)
+		if r.Name != "" {
+			if seenRouteNames[r.Name] {
+				errs = append(errs, Duplicate(p.Child("name"), r.Name))
+			}
+			seenRouteNames[r.Name] = true
+		}
+		errs = append(errs, validateHTTPRoute(p, r, isDelegate)...)
+	}
+	for i, r := range vs.Tls {
+		errs = append(errs, validateTLSRoute(NewPath("tls").Index(i), r)...)
+	}
+	for i, r := range vs.Tcp {
+		errs = append(errs, validateTCPRoute(NewPath("tcp").Index(i), r)...)
+	}
+	errs = append(errs, validateExportTo(NewPath("exportTo"), vs.ExportTo)...)
+	return errs
+}
+
+func validateHTTPRoute(p Path, r *networking.HTTPRoute, isDelegate bool) ErrorList {
+	if r == nil {
+		return ErrorList{Required(p, "route must not be nil")}
+	}
+	var errs ErrorList
+
+	// Exactly one action may be taken by a route.
+	actions := 0
+	for _, set := range []bool{len(r.Route) > 0, r.Redirect != nil, r.DirectResponse != nil, r.Delegate != nil} {
+		if set {
+			actions++
+		}
+	}
+	switch {
+	case actions == 0:
+		errs = append(errs, Required(p.Child("route"), "one of route, redirect, directResponse or delegate must be set"))
+	case actions > 1:
+		errs = append(errs, Invalid(p, nil, "only one of route, redirect, directResponse or delegate may be set"))
+	}
+
+	if r.Delegate != nil {
+		if isDelegate {
+			errs = append(errs, Forbidden(p.Child("delegate"), "delegate virtual services cannot delegate further"))
+		}
+		if r.Delegate.Name == "" {
+			errs = append(errs, Required(p.Child("delegate").Child("name"), "delegate name must be set"))
+		} else {
+			errs = append(errs, validateFQDN(p.Child("delegate").Child("name"), r.Delegate.Name)...)
+		}
+		if r.Delegate.Namespace != "" && !isDNS1123Label(r.Delegate.Namespace) {
+			errs = append(errs, Invalid(p.Child("delegate").Child("namespace"), r.Delegate.Namespace, "must be a valid namespace name"))
+		}
+		for _, f := range []struct {
+			set  bool
+			name string
+		}{
+			{r.Rewrite != nil, "rewrite"},
+			{r.Timeout != nil, "timeout"},
+			{r.Retries != nil, "retries"},
+			{r.Fault != nil, "fault"},
+			{r.Mirror != nil || len(r.Mirrors) > 0, "mirror"},
+			{r.CorsPolicy != nil, "corsPolicy"},
+			{r.Headers != nil, "headers"},
+		} {
+			if f.set {
+				errs = append(errs, Forbidden(p.Child(f.name), "must not be set on a route that delegates"))
+			}
+		}
+	}
+	if r.Rewrite != nil && r.Redirect != nil {
+		errs = append(errs, Forbidden(p.Child("rewrite"), "rewrite must not be used together with redirect"))
+	}
+
+	for i, m := range r.Match {
+		errs = append(errs, validateHTTPMatchRequest(p.Child("match").Index(i), m)...)
+	}
+	errs = append(errs, validateHTTPRouteDestinations(p.Child("route"), r.Route)...)
+	errs = append(errs, validateHTTPRedirect(p.Child("redirect"), r.Redirect)...)
+	errs = append(errs, validateHTTPDirectResponse(p.Child("directResponse"), r.DirectResponse)...)
+	errs = append(errs, validateHTTPRewrite(p.Child("rewrite"), r.Rewrite)...)
+	// A zero timeout disables the timeout.
+	errs = append(errs, validateDuration(p.Child("timeout"), r.Timeout, 0)...)
+	errs = append(errs, validateHTTPRetry(p.Child("retries"), r.Retries)...)
+	errs = append(errs, validateHTTPFaultInjection(p.Child("fault"), r.Fault)...)
+	errs = append(errs, validateHeaders(p.Child("headers"), r.Headers)...)
+	errs = append(errs, validateCorsPolicy(p.Child("corsPolicy"), r.CorsPolicy)...)
+
+	if r.Mirror != nil {
+		if len(r.Mirrors) > 0 {
+			errs = append(errs, Forbidden(p.Child("mirror"), "mirror and mirrors must not be set together"))
+		}
+		errs = append(errs, validateDestination(p.Child("mirror"), r.Mirror)...)
+	} else if r.MirrorPercentage != nil || r.MirrorPercent != nil {
+		errs = append(errs, Forbidden(p.Child("mirrorPercentage"), "must only be set together with mirror"))
+	}
+	if r.MirrorPercent != nil && r.MirrorPercent.Value > 100 {
+		errs = append(errs, Invalid(p.Child("mirrorPercent"), r.MirrorPercent.Value, "must be between 0 and 100"))
+	}
+	if r.MirrorPercentage != nil {
+		errs = append(errs, validatePercent(p.Child("mirrorPercentage"), r.MirrorPercentage.Value)...)
+	}
+	for i, m := range r.Mirrors {
+		mp := p.Child("mirrors").Index(i)
+		if m == nil || m.Destination == nil {
+			errs = append(errs, Required(mp.Child("destination"), "mirror destination must be set"))
+			continue
+		}
+		errs = append(errs, validateDestination(mp.Child("destination"), m.Destination)...)
+		if m.Percentage != nil {
+			errs = append(errs, validatePercent(mp.Child("percentage"), m.Percentage.Value)...)
+		}
+	}
+	return errs
+}
+
+func validateHTTPMatchRequest(p Path, m *networking.HTTPMatchRequest) ErrorList {
+	if m == nil {
+		return nil
+	}
+	var errs ErrorList
+	errs = append(errs, validateStringMatch(p.Child("uri"), m.Uri, true)...)
+	errs = append(errs, validateStringMatch(p.Child("scheme"), m.Scheme, true)...)
+	errs = append(errs, validateStringMatch(p.Child("method"), m.Method, true)...)
+	errs = append(errs, validateStringMatch(p.Child("authority"), m.Authority, true)...)
+	errs = append(errs, validateHeaderMatches(p.Child("headers"), m.Headers)...)
+	errs = append(errs, validateHeaderMatches(p.Child("withoutHeaders"), m.WithoutHeaders)...)
+	for _, k := range slices.Sorted(maps.Keys(m.QueryParams)) {
+		errs = append(errs, validateStringMatch(p.Child("queryParams").Key(k), m.QueryParams[k], false)...)
+	}
+	if m.Port != 0 {
+		errs = append(errs, validatePort(p.Child("port"), m.Port)...)
+	}
+	errs = append(errs, validateLabels(p.Child("sourceLabels"), m.SourceLabels)...)
+	for i, gw := range m.Gateways {
+		errs = append(errs, validateGatewayName(p.Child("gateways").Index(i), gw)...)
+	}
+	if m.SourceNamespace != "" && !isDNS1123Label(m.SourceNamespace) {
+		errs = append(errs, Invalid(p.Child("sourceNamespace"), m.SourceNamespace, "must be a valid namespace name"))
+	}
+	return errs
+}
+
+func validateHeaderMatches(p Path, headers map[string]*networking.StringMatch) ErrorList {
+	var errs ErrorList
+	for _, k := range slices.Sorted(maps.Keys(headers)) {
+		hp := p.Key(k)
+		if k == "" {
+			errs = append(errs, Invalid(hp, k, "header name must not be empty"))
+			continue
+		}
+		if strings.ToLower(k) != k {
+			errs = append(errs, Invalid(hp, k, "header names must be lowercase"))
+		}
+		// An empty match checks only for the presence of the header.
+		errs = append(errs, validateStringMatch(hp, headers[k], false)...)
+	}
+	return errs
+}
+
+// validateStringMatch checks a StringMatch. When nonEmpty is set an empty match value is rejected,
+// as it would either match everything or nothing.
+func validateStringMatch(p Path, m *networking.StringMatch, nonEmpty bool) ErrorList {
+	if m == nil {
+		return nil
+	}
+	switch mt := m.MatchType.(type) {
+	case *networking.StringMatch_Exact:
+		if nonEmpty && mt.Exact == "" {
+			return ErrorList{Invalid(p.Child("exact"), mt.Exact, "must not be empty")}
+		}
+	case *networking.StringMatch_Prefix:
+		if nonEmpty && mt.Prefix == "" {
+			return ErrorList{Invalid(p.Child("prefix"), mt.Prefix, "must not be empty")}
+		}
+	case *networking.StringMatch_Regex:
+		return validateRegex(p.Child("regex"), mt.Regex)
+	}
+	return nil
+}
+
+// validateRegex checks that re is a valid RE2 expression, as required by Envoy.
+func validateRegex(p Path, re string) ErrorList {
+	if re == "" {
+		return ErrorList{Invalid(p, re, "must not be empty")}
+	}
+	if _, err := regexp.Compile(re); err != nil {
+		return ErrorList{Invalid(p, re, err.Error())}
+	}
+	return nil
+}
+
+func validateHTTPRouteDestinations(p Path, routes []*networking.HTTPRouteDestination) ErrorList {
+	var errs ErrorList
+	var total int64
+	for i, r := range routes {
+		rp := p.Index(i)
+		if r == nil || r.Destination == nil {
+			errs = append(errs, Required(rp.Child("destination"), "destination must be set"))
+			continue
+		}
+		errs = append(errs, validateDestination(rp.Child("destination"), r.Destination)...)
+		errs = append(errs, validateHeaders(rp.Child("headers"), r.Headers)...)
+		if r.Weight < 0 {
+			errs = append(errs, Invalid(rp.Child("weight"), r.Weight, "must not be negative"))
+		}
+		total += int64(r.Weight)
+	}
+	return append(errs, validateTotalWeight(p, len(routes), total)...)
+}
+
+// validateTotalWeight checks destination weights. A single destination implicitly receives all traffic;
+// otherwise the weights must sum to 100.
+func validateTotalWeight(p Path, destinations int, total int64) ErrorList {
+	if destinations <= 1 {
+		return nil
+	}
+	if total == 0 {
+		return ErrorList{Invalid(p, total, "total destination weight must not be 0")}
+	}
+	if total != 100 {
+		return ErrorList{Invalid(p, total, "total destination weight must be 100")}
+	}
+	return nil
+}
+
+func validateDestination(p Path, d *networking.Destination) ErrorList {
+	if d == nil {
+		return ErrorList{Required(p, "destination must be set")}
+	}
+	var errs ErrorList
+	if d.Host == "" {
+		errs = append(errs, Required(p.Child("host"), "destination host must be set"))
+	} else if d.Host == "*" || strings.HasPrefix(d.Host, "*") {
+		errs = append(errs, Invalid(p.Child("host"), d.Host, "destination host must not be a wildcard"))
+	} else {
+		errs = append(errs, validateHostOrIP(p.Child("host"), d.Host)...)
+	}
+	if d.Subset != "" && !isDNS1123Label(d.Subset) {
+		errs = append(errs, Invalid(p.Child("subset"), d.Subset, "must be a valid DNS-1123 label"))
+	}
+	if d.Port != nil {
+		errs = append(errs, validatePort(p.Child("port").Child("number"), d.Port.Number)...)
+	}
+	return errs
+}
+
+func validateHTTPRedirect(p Path, r *networking.HTTPRedirect) ErrorList {
+	if r == nil {
+		return nil
+	}
+	var errs ErrorList
+	if r.Uri == "" && r.Authority == "" && r.Scheme == "" && r.RedirectPort == nil {
+		errs = append(errs, Required(p, "one of uri, authority, scheme or port must be set"))
+	}
+	if r.RedirectCode != 0 && !slices.Contains(supportedRedirectCodes, r.RedirectCode) {
+		valid := make([]string, 0, len(supportedRedirectCodes))
+		for _, c := range supportedRedirectCodes {
+			valid = append(valid, strconv.Itoa(int(c)))
+		}
+		errs = append(errs, NotSupported(p.Child("redirectCode"), r.RedirectCode, valid))
+	}
+	if port, ok := r.RedirectPort.(*networking.HTTPRedirect_Port); ok {
+		errs = append(errs, validatePort(p.Child("port"), port.Port)...)
+	}
+	return errs
+}
+
+func validateHTTPDirectResponse(p Path, r *networking.HTTPDirectResponse) ErrorList {
+	if r == nil {
+		return nil
+	}
+	if r.Status < 200 || r.Status > 599 {
+		return ErrorList{Invalid(p.Child("status"), r.Status, "must be between 200 and 599")}
+	}
+	return nil
+}
+
+func validateHTTPRewrite(p Path, r *networking.HTTPRewrite) ErrorList {
+	if r == nil {
+		return nil
+	}
+	var errs ErrorList
+	if r.Uri == "" && r.Authority == "" && r.UriRegexRewrite == nil {
+		errs = append(errs, Required(p, "one of uri, authority or uriRegexRewrite must be set"))
+	}
+	if r.UriRegexRewrite != nil {
+		if r.Uri != "" {
+			errs = append(errs, Forbidden(p.Child("uriRegexRewrite"), "uri and uriRegexRewrite must not be set together"))
+		}
+		errs = append(errs, validateRegex(p.Child("uriRegexRewrite").Child("match"), r.UriRegexRewrite.Match)...)
+	}
+	return errs
+}
+
+func validateHTTPRetry(p Path, r *networking.HTTPRetry) ErrorList {
+	if r == nil {
+		return nil
+	}
+	var errs ErrorList
+	if r.Attempts < 0 {
+		errs = append(errs, Invalid(p.Child("attempts"), r.Attempts, "must not be negative"))
+	}
+	errs = append(errs, validateDuration(p.Child("perTryTimeout"), r.PerTryTimeout, minTimeout)...)
+	errs = append(errs, validateDuration(p.Child("backoff"), r.Backoff, minTimeout)...)
+	if r.RetryOn != "" {
+		for _, policy := range strings.Split(r.RetryOn, ",") {
+			policy = strings.TrimSpace(policy)
+			if slices.Contains(supportedRetryOnPolicies, policy) {
+				continue
+			}
+			// Individual HTTP status codes are accepted as well, which implies retriable-status-codes.
+			if code, err := strconv.Atoi(policy); err == nil && code >= 100 && code <= 599 {
+				continue
+			}
+			errs = append(errs, NotSupported(p.Child("retryOn"), policy, supportedRetryOnPolicies))
+		}
+	}
+	return errs
+}
+
+func validateHTTPFaultInjection(p Path, f *networking.HTTPFaultInjection) ErrorList {
+	if f == nil {
+		return nil
+	}
+	var errs ErrorList
+	if f.Abort == nil && f.Delay == nil {
+		errs = append(errs, Required(p, "one of abort or delay must be set"))
+	}
+	if a := f.Abort; a != nil {
+		ap := p.Child("abort")
+		if a.Percentage != nil {
+			errs = append(errs, validatePercent(ap.Child("percentage"), a.Percentage.Value)...)
+		}
+		switch et := a.ErrorType.(type) {
+		case *networking.HTTPFaultInjection_Abort_HttpStatus:
+			if et.HttpStatus < 200 || et.HttpStatus > 599 {
+				errs = append(errs, Invalid(ap.Child("httpStatus"), et.HttpStatus, "must be between 200 and 599"))
+			}
+		case *networking.HTTPFaultInjection_Abort_GrpcStatus:
+			if et.GrpcStatus == "" {
+				errs = append(errs, Invalid(ap.Child("grpcStatus"), et.GrpcStatus, "must not be empty"))
+			}
+		case nil:
+			errs = append(errs, Required(ap, "one of httpStatus, grpcStatus or http2Error must be set"))
+		}
+	}
+	if d := f.Delay; d != nil {
+		dp := p.Child("delay")
+		if d.Percentage != nil {
+			errs = append(errs, validatePercent(dp.Child("percentage"), d.Percentage.Value)...)
+		}
+		if d.Percent < 0 || d.Percent > 100 {
+			errs = append(errs, Invalid(dp.Child("percent"), d.Percent, "must be between 0 and 100"))
+		}
+		switch dt := d.HttpDelayType.(type) {
+		case *networking.HTTPFaultInjection_Delay_FixedDelay:
+			errs = append(errs, validateDuration(dp.Child("fixedDelay"), dt.FixedDelay, minTimeout)...)
+		case *networking.HTTPFaultInjection_Delay_ExponentialDelay:
+			errs = append(errs, validateDuration(dp.Child("exponentialDelay"), dt.ExponentialDelay, minTimeout)...)
+		case nil:
+			errs = append(errs, Required(dp.Child("fixedDelay"), "fixedDelay must be set"))
+		}
+	}
+	return errs
+}
+
+func validateHeaders(p Path, h *networking.Headers) ErrorList {
+	if h == nil {
+		return nil
+	}
+	var errs ErrorList
+	errs = append(errs, validateHeaderOperations(p.Child("request"), h.Request)...)
+	errs = append(errs, validateHeaderOperations(p.Child("response"), h.Response)...)
+	return errs
+}
+
+func validateHeaderOperations(p Path, ops *networking.Headers_HeaderOperations) ErrorList {
+	if ops == nil {
+		return nil
+	}
+	var errs ErrorList
+	check := func(hp Path, name string) {
+		switch {
+		case name == "":
+			errs = append(errs, Invalid(hp, name, "header name must not be empty"))
+		case slices.Contains(immutableHeaders, strings.ToLower(name)):
+			errs = append(errs, Invalid(hp, name, fmt.Sprintf("header %q cannot be modified", name)))
+		}
+	}
+	for _, k := range slices.Sorted(maps.Keys(ops.Set)) {
+		check(p.Child("set").Key(k), k)
+	}
+	for _, k := range slices.Sorted(maps.Keys(ops.Add)) {
+		check(p.Child("add").Key(k), k)
+	}
+	for i, k := range ops.Remove {
+		check(p.Child("remove").Index(i), k)
+	}
+	return errs
+}
+
+var supportedCorsMethods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS", "TRACE", "CONNECT"}
+
+func validateCorsPolicy(p Path, c *networking.CorsPolicy) ErrorList {
+	if c == nil {
+		return nil
+	}
+	var errs ErrorList
+	if len(c.AllowOrigin) > 0 && len(c.AllowOrigins) > 0 {
+		errs = append(errs, Forbidden(p.Child("allowOrigin"), "allowOrigin and allowOrigins must not be set together"))
+	}
+	for i, o := range c.AllowOrigins {
+		errs = append(errs, validateStringMatch(p.Child("allowOrigins").Index(i), o, true)...)
+	}
+	for i, m := range c.AllowMethods {
+		if !slices.Contains(supportedCorsMethods, m) {
+			errs = append(errs, NotSupported(p.Child("allowMethods").Index(i), m, supportedCorsMethods))
+		}
+	}
+	errs = append(errs, validateDuration(p.Child("maxAge"), c.MaxAge, time.Second)...)
+	return errs
+}
+
+func validateTLSRoute(p Path, r *networking.TLSRoute) ErrorList {
+	if r == nil {
+		return ErrorList{Required(p, "route must not be nil")}
+	}
+	var errs ErrorList
+	if len(r.Match) == 0 {
+		errs = append(errs, Required(p.Child("match"), "tls routes must have at least one match"))
+	}
+	for i, m := range r.Match {
+		mp := p.Child("match").Index(i)
+		if m == nil {
+			continue
+		}
+		if len(m.SniHosts) == 0 {
+			errs = append(errs, Required(mp.Child("sniHosts"), "at least one SNI host must be set"))
+		}
+		for j, h := range m.SniHosts {
+			errs = append(errs, validateWildcardHost(mp.Child("sniHosts").Index(j), h)...)
+		}
+		errs = append(errs, validateSubnets(mp.Child("destinationSubnets"), m.DestinationSubnets)...)
+		if m.Port != 0 {
+			errs = append(errs, validatePort(mp.Child("port"), m.Port)...)
+		}
+		errs = append(errs, validateLabels(mp.Child("sourceLabels"), m.SourceLabels)...)
+		for j, gw := range m.Gateways {
+			errs = append(errs, validateGatewayName(mp.Child("gateways").Index(j), gw)...)
+		}
+	}
+	return append(errs, validateRouteDestinations(p.Child("route"), r.Route)...)
+}
+
+func validateTCPRoute(p Path, r *networking.TCPRoute) ErrorList {
+	if r == nil {
+		return ErrorList{Required(p, "route must not be nil")}
+	}
+	var errs ErrorList
+	for i, m := range r.Match {
+		mp := p.Child("match").Index(i)
+		if m == nil {
+			continue
+		}
+		errs = append(errs, validateSubnets(mp.Child("destinationSubnets"), m.DestinationSubnets)...)
+		if m.SourceSubnet != "" {
+			errs = append(errs, validateSubnets(mp.Child("sourceSubnet"), []string{m.SourceSubnet})...)
+		}
+		if m.Port != 0 {
+			errs = append(errs, validatePort(mp.Child("port"), m.Port)...)
+		}
+		errs = append(errs, validateLabels(mp.Child("sourceLabels"), m.SourceLabels)...)
+		for j, gw := range m.Gateways {
+			errs = append(errs, validateGatewayName(mp.Child("gateways").Index(j), gw)...)
+		}
+	}
+	return append(errs, validateRouteDestinations(p.Child("route"), r.Route)...)
+}
+
+func validateRouteDestinations(p Path, routes []*networking.RouteDestination) ErrorList {
+	var errs ErrorList
+	if len(routes) == 0 {
+		errs = append(errs, Required(p, "at least one destination must be set"))
+	}
+	var total int64
+	for i, r := range routes {
+		rp := p.Index(i)
+		if r == nil || r.Destination == nil {
+			errs = append(errs, Required(rp.Child("destination"), "destination must be set"))
+			continue
+		}
+		errs = append(errs, validateDestination(rp.Child("destination"), r.Destination)...)
+		if r.Weight < 0 {
+			errs = append(errs, Invalid(rp.Child("weight"), r.Weight, "must not be negative"))
+		}
+		total += int64(r.Weight)
+	}
+	return append(errs, validateTotalWeight(p, len(routes), total)...)
+}
@@ -0,0 +1,160 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package resource lists the kinds of Kubernetes resources Istio annotations and labels apply to.
+package resource
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Type is a kind of Kubernetes resource annotations and labels apply to.
+type Type int
+
+// The values of existing types are part of the API and must not change: add new types at the end.
+// The first ones match the values of annotation.ResourceTypes.
+const (
+	Unknown Type = iota
+	Any
+	AuthorizationPolicy
+	Gateway
+	GatewayClass
+	Ingress
+	Namespace
+	Pod
+	Service
+	ServiceEntry
+	WorkloadEntry
+	Deployment
+	HorizontalPodAutoscaler
+	MutatingWebhookConfiguration
+	Node
+	PodDisruptionBudget
+	ServiceAccount
+)
+
+// types is indexed by Type.
+var types = []GroupKind{
+	Unknown:                      {},
+	Any:                          {Kind: "Any"},
+	AuthorizationPolicy:          {Group: "security.istio.io", Kind: "AuthorizationPolicy"},
+	Gateway:                      {Group: "gateway.networking.k8s.io", Kind: "Gateway"},
+	GatewayClass:                 {Group: "gateway.networking.k8s.io", Kind: "GatewayClass"},
+	Ingress:                      {Group: "networking.k8s.io", Kind: "Ingress"},
+	Namespace:                    {Group: "", Kind: "Namespace"},
+	Pod:                          {Group: "", Kind: "Pod"},
+	Service:                      {Group: "", Kind: "Service"},
+	ServiceEntry:                 {Group: "networking.istio.io", Kind: "ServiceEntry"},
+	WorkloadEntry:                {Group: "networking.istio.io", Kind: "WorkloadEntry"},
+	Deployment:                   {Group: "apps", Kind: "Deployment"},
+	HorizontalPodAutoscaler:      {Group: "autoscaling", Kind: "HorizontalPodAutoscaler"},
+	MutatingWebhookConfiguration: {Group: "admissionregistration.k8s.io", Kind: "MutatingWebhookConfiguration"},
+	Node:                         {Group: "", Kind: "Node"},
+	PodDisruptionBudget:          {Group: "policy", Kind: "PodDisruptionBudget"},
+	ServiceAccount:               {Group: "", Kind: "ServiceAccount"},
+}
+
+// GroupKind identifies a kind of Kubernetes resource. The group of core resources, such as Pod, is empty.
+type GroupKind struct {
+	Group string
+	Kind  string
+}
+
+func (gk GroupKind) String() string {
+	if gk.Group == "" {
+		return gk.Kind
+	}
+	return gk.Kind + "." + gk.Group
+}
+
+func (t Type) String() string {
+	if t <= Unknown || int(t) >= len(types) {
+		return "Unknown"
+	}
+	return types[t].Kind
+}
+
+// GroupKind returns the group and kind of t. Any and Unknown have no group.
+func (t Type) GroupKind() GroupKind {
+	if t <= Unknown || int(t) >= len(types) {
+		return GroupKind{Kind: "Unknown"}
+	}
+	return types[t]
+}
+
+// All returns all the resource types, except Unknown and Any.
+func All() []Type {
+	out := make([]Type, 0, len(types)-2)
+	for t := Any + 1; int(t) < len(types); t++ {
+		out = append(out, t)
+	}
+	return out
+}
+
+// FromKind returns the resource type of the given kind, as named by the ResourceTypes of the
+// annotation and label packages, or Unknown. Kinds are unique among the types.
+func FromKind(kind string) Type {
+	if kind == "Any" {
+		return Any
+	}
+	for t := Any + 1; int(t) < len(types); t++ {
+		if types[t].Kind == kind {
+			return t
+		}
+	}
+	return Unknown
+}
+
+// FromGroupKind returns the resource type with the given group and kind, or Unknown.
+func FromGroupKind(group, kind string) Type {
+	for t := Any + 1; int(t) < len(types); t++ {
+		if types[t].Group == group && types[t].Kind == kind {
+			return t
+		}
+	}
+	return Unknown
+}
+
+// FromAPIVersionKind returns the resource type of an object with the given apiVersion, such as
+// "apps/v1" or "v1", and kind, or Unknown.
+func FromAPIVersionKind(apiVersion, kind string) Type {
+	group, _, f := strings.Cut(apiVersion, "/")
+	if !f {
+		group = ""
+	}
+	return FromGroupKind(group, kind)
+}
+
+// ParseGroupVersionKind parses a group, version and kind, in the form printed by Kubernetes,
+// "apps/v1, Kind=Deployment", or as an apiVersion and kind, "apps/v1/Deployment". Core resources
+// have no group, as in "/v1, Kind=Pod" or "v1/Pod". An error is returned if the kind is not known.
+func ParseGroupVersionKind(gvk string) (Type, error) {
+	var apiVersion, kind string
+	if gv, k, f := strings.Cut(gvk, ", Kind="); f {
+		apiVersion, kind = strings.TrimPrefix(gv, "/"), k
+	} else if i := strings.LastIndex(gvk, "/"); i > 0 {
+		apiVersion, kind = gvk[:i], gvk[i+1:]
+	} else {
+		return Unknown, fmt.Errorf("invalid group version kind %q", gvk)
+	}
+	if apiVersion == "" || kind == "" || strings.Count(apiVersion, "/") > 1 {
+		return Unknown, fmt.Errorf("invalid group version kind %q", gvk)
+	}
+	t := FromAPIVersionKind(apiVersion, kind)
+	if t == Unknown {
+		return Unknown, fmt.Errorf("unknown resource type %q", gvk)
+	}
+	return t, nil
+}
@@ -0,0 +1,88 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import "testing"
+
+func TestParseGroupVersionKind(t *testing.T) {
+	cases := map[string]Type{
+		"apps/v1, Kind=Deployment":                         Deployment,
+		"/v1, Kind=Pod":                                    Pod,
+		"gateway.networking.k8s.io/v1/GatewayClass":        GatewayClass,
+		"v1/ServiceAccount":                                ServiceAccount,
+		"networking.istio.io/v1, Kind=ServiceEntry":        ServiceEntry,
+		"networking.istio.io/v1alpha3, Kind=WorkloadEntry": WorkloadEntry,
+		"gateway.networking.k8s.io/v1, Kind=Gateway":       Gateway,
+	}
+	for in, want := range cases {
+		got, err := ParseGroupVersionKind(in)
+		if err != nil || got != want {
+			t.Errorf("ParseGroupVersionKind(%q) = %v, %v, want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "Pod", "/Pod", "v1/", "apps/v1/extra/Deployment", "apps/v1, Kind=Pod", "networking.istio.io/v1/Gateway"} {
+		if got, err := ParseGroupVersionKind(in); err == nil {
+			t.Errorf("ParseGroupVersionKind(%q) = %v, want error", in, got)
+		}
+	}
+}
+
+func TestGroupKind(t *testing.T) {
+	for _, r := range All() {
+		gk := r.GroupKind()
+		if gk.Kind != r.String() {
+			t.Errorf("%v: kind %q", r, gk.Kind)
+		}
+		if FromGroupKind(gk.Group, gk.Kind) != r {
+			t.Errorf("%v: FromGroupKind(%q, %q) = %v", r, gk.Group, gk.Kind, FromGroupKind(gk.Group, gk.Kind))
+		}
+	}
+	if got := GatewayClass.GroupKind().String(); got != "GatewayClass.gateway.networking.k8s.io" {
+		t.Errorf("got %q", got)
+	}
+	if got := Any.String(); got != "Any" {
+		t.Errorf("got %q", got)
+	}
+	if got := Type(100).String(); got != "Unknown" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestFromKind(t *testing.T) {
+	for _, r := range All() {
+		if got := FromKind(r.String()); got != r {
+			t.Errorf("FromKind(%q) = %v, want %v", r.String(), got, r)
+		}
+	}
+	if got := FromKind("Any"); got != Any {
+		t.Errorf("FromKind(Any) = %v", got)
+	}
+	if got := FromKind("VirtualService"); got != Unknown {
+		t.Errorf("FromKind(VirtualService) = %v", got)
+	}
+}
+
+func TestStableValues(t *testing.T) {
+	// The values are part of the API: existing ones must never be renumbered.
+	for want, r := range []Type{
+		Unknown, Any, AuthorizationPolicy, Gateway, GatewayClass, Ingress, Namespace, Pod, Service, ServiceEntry,
+		WorkloadEntry, Deployment, HorizontalPodAutoscaler, MutatingWebhookConfiguration, Node, PodDisruptionBudget,
+		ServiceAccount,
+	} {
+		if int(r) != want {
+			t.Errorf("%v = %d, want %d", r, r, want)
+		}
+	}
+}
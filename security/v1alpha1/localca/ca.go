@@ -0,0 +1,217 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package localca is an in-process implementation of the IstioCertificateService, signing
+// certificates from an in-memory root. It is meant to test workload identity flows without istiod.
+package localca
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "istio.io/api/security/v1alpha1"
+)
+
+// Metadata keys of IstioCertificateRequest honored by the CA.
+const (
+	// MetadataImpersonatedIdentity requests a certificate for another SPIFFE identity than the one
+	// of the CSR, as node agents do on behalf of the workloads they serve.
+	MetadataImpersonatedIdentity = "ImpersonatedIdentity"
+	// MetadataWorkloadIP adds an IP address SAN to the certificate.
+	MetadataWorkloadIP = "WorkloadIP"
+)
+
+// Options configure a CA.
+type Options struct {
+	// TrustDomain of the identities the CA signs certificates for. Defaults to "cluster.local".
+	TrustDomain string
+	// DefaultValidity of the certificates, used when the request does not set one. Defaults to one hour.
+	DefaultValidity time.Duration
+	// MaxValidity of the certificates. Requests for longer validities are rejected. Defaults to one day.
+	MaxValidity time.Duration
+	// AllowImpersonation allows requests to set MetadataImpersonatedIdentity.
+	AllowImpersonation bool
+}
+
+// CA signs certificate requests with a self-signed root generated when it is created.
+type CA struct {
+	pb.UnimplementedIstioCertificateServiceServer
+
+	opts    Options
+	root    *x509.Certificate
+	rootPEM string
+	key     crypto.Signer
+	// now is replaced in tests.
+	now func() time.Time
+}
+
+var _ pb.IstioCertificateServiceServer = &CA{}
+
+// New returns a CA with a new root.
+func New(opts Options) (*CA, error) {
+	if opts.TrustDomain == "" {
+		opts.TrustDomain = "cluster.local"
+	}
+	if opts.DefaultValidity == 0 {
+		opts.DefaultValidity = time.Hour
+	}
+	if opts.MaxValidity == 0 {
+		opts.MaxValidity = 24 * time.Hour
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber(),
+		Subject:               pkix.Name{Organization: []string{opts.TrustDomain}},
+		NotBefore:             now.Add(-time.Minute),
+		NotAfter:              now.Add(10 * 365 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return nil, err
+	}
+	root, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return &CA{
+		opts:    opts,
+		root:    root,
+		rootPEM: encodeCertificate(der),
+		key:     key,
+		now:     time.Now,
+	}, nil
+}
+
+// Root returns the root certificate of the CA.
+func (ca *CA) Root() *x509.Certificate {
+	return ca.root
+}
+
+// RootPool returns a pool containing the root certificate of the CA.
+func (ca *CA) RootPool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.root)
+	return pool
+}
+
+// CreateCertificate signs the CSR of the request. The CSR must carry a single SPIFFE URI SAN
+// in the trust domain of the CA. The returned chain holds the leaf and root certificates.
+func (ca *CA) CreateCertificate(_ context.Context, req *pb.IstioCertificateRequest) (*pb.IstioCertificateResponse, error) {
+	block, _ := pem.Decode([]byte(req.GetCsr()))
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return nil, status.Error(codes.InvalidArgument, "csr is not a PEM encoded certificate request")
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid csr: %v", err)
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid csr signature: %v", err)
+	}
+	if len(csr.URIs) != 1 {
+		return nil, status.Errorf(codes.InvalidArgument, "csr must have exactly one URI SAN, got %d", len(csr.URIs))
+	}
+	identity := csr.URIs[0]
+	var ips []net.IP
+
+	for k, v := range req.GetMetadata().GetFields() {
+		switch k {
+		case MetadataImpersonatedIdentity:
+			if !ca.opts.AllowImpersonation {
+				return nil, status.Error(codes.PermissionDenied, "impersonation is not allowed")
+			}
+			if identity, err = url.Parse(v.GetStringValue()); err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid %s: %v", k, err)
+			}
+		case MetadataWorkloadIP:
+			ip := net.ParseIP(v.GetStringValue())
+			if ip == nil {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid %s %q", k, v.GetStringValue())
+			}
+			ips = append(ips, ip)
+		}
+	}
+	if err := ca.checkIdentity(identity); err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	validity := min(ca.opts.DefaultValidity, ca.opts.MaxValidity)
+	switch seconds := req.GetValidityDuration(); {
+	case seconds < 0:
+		return nil, status.Error(codes.InvalidArgument, "validity duration must not be negative")
+	case seconds > int64(ca.opts.MaxValidity/time.Second):
+		// Compared in seconds, as larger durations overflow time.Duration.
+		return nil, status.Errorf(codes.InvalidArgument, "validity duration of %ds exceeds the maximum of %v", seconds, ca.opts.MaxValidity)
+	case seconds > 0:
+		validity = time.Duration(seconds) * time.Second
+	}
+
+	now := ca.now()
+	template := &x509.Certificate{
+		SerialNumber: serialNumber(),
+		NotBefore:    now.Add(-time.Minute),
+		NotAfter:     now.Add(validity),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		URIs:         []*url.URL{identity},
+		IPAddresses:  ips,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.root, csr.PublicKey, ca.key)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to sign certificate: %v", err)
+	}
+	return &pb.IstioCertificateResponse{CertChain: []string{encodeCertificate(der), ca.rootPEM}}, nil
+}
+
+// checkIdentity returns an error if u is not a SPIFFE identity in the trust domain of the CA.
+func (ca *CA) checkIdentity(u *url.URL) error {
+	if u.Scheme != "spiffe" || u.Host != ca.opts.TrustDomain || !strings.HasPrefix(u.Path, "/") {
+		return fmt.Errorf("identity %q is not in trust domain %q", u, ca.opts.TrustDomain)
+	}
+	return nil
+}
+
+func serialNumber() *big.Int {
+	n, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+func encodeCertificate(der []byte) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
@@ -0,0 +1,86 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package localca
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/url"
+	"time"
+
+	"google.golang.org/protobuf/types/known/structpb"
+
+	pb "istio.io/api/security/v1alpha1"
+)
+
+// RequestCertificate generates a key and a CSR for identity, such as
+// "spiffe://cluster.local/ns/default/sa/reviews", and has it signed by client. A zero validity
+// lets the CA choose one. The returned certificate holds the leaf and any intermediate, but not the root.
+func RequestCertificate(ctx context.Context, client pb.IstioCertificateServiceClient, identity string,
+	validity time.Duration, metadata map[string]any,
+) (tls.Certificate, error) {
+	uri, err := url.Parse(identity)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{URIs: []*url.URL{uri}}, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	req := &pb.IstioCertificateRequest{
+		Csr:              string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr})),
+		ValidityDuration: int64(validity / time.Second),
+	}
+	if len(metadata) > 0 {
+		if req.Metadata, err = structpb.NewStruct(metadata); err != nil {
+			return tls.Certificate{}, err
+		}
+	}
+	resp, err := client.CreateCertificate(ctx, req)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	if len(resp.GetCertChain()) == 0 {
+		return tls.Certificate{}, fmt.Errorf("empty certificate chain")
+	}
+
+	cert := tls.Certificate{PrivateKey: key}
+	// The root is the last element of the chain, and is not sent by peers.
+	chain := resp.GetCertChain()
+	if len(chain) > 1 {
+		chain = chain[:len(chain)-1]
+	}
+	for _, c := range chain {
+		block, _ := pem.Decode([]byte(c))
+		if block == nil || block.Type != "CERTIFICATE" {
+			return tls.Certificate{}, fmt.Errorf("invalid certificate in chain")
+		}
+		cert.Certificate = append(cert.Certificate, block.Bytes)
+	}
+	if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+		return tls.Certificate{}, err
+	}
+	return cert, nil
+}
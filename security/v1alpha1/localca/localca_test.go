@@ -0,0 +1,196 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package localca
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"math"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	pb "istio.io/api/security/v1alpha1"
+)
+
+const reviews = "spiffe://cluster.local/ns/default/sa/reviews"
+
+func newClient(t *testing.T, opts Options) (*CA, pb.IstioCertificateServiceClient) {
+	t.Helper()
+	ca, err := New(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, stop, err := ca.ServeBufconn()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(stop)
+	return ca, pb.NewIstioCertificateServiceClient(conn)
+}
+
+func TestRequestCertificate(t *testing.T) {
+	ca, client := newClient(t, Options{MaxValidity: 2 * time.Hour})
+	ctx := context.Background()
+
+	cert, err := RequestCertificate(ctx, client, reviews, 0, map[string]any{MetadataWorkloadIP: "10.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cert.Leaf.Verify(x509.VerifyOptions{Roots: ca.RootPool()}); err != nil {
+		t.Fatal(err)
+	}
+	if got := cert.Leaf.URIs[0].String(); got != reviews {
+		t.Errorf("got identity %v", got)
+	}
+	if len(cert.Leaf.IPAddresses) != 1 || !cert.Leaf.IPAddresses[0].Equal(net.ParseIP("10.0.0.1")) {
+		t.Errorf("got IP addresses %v", cert.Leaf.IPAddresses)
+	}
+	if got := time.Until(cert.Leaf.NotAfter); got > time.Hour || got < 59*time.Minute {
+		t.Errorf("got default validity %v", got)
+	}
+
+	cert, err = RequestCertificate(ctx, client, reviews, 2*time.Hour, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := time.Until(cert.Leaf.NotAfter); got > 2*time.Hour || got < 119*time.Minute {
+		t.Errorf("got maximum validity %v", got)
+	}
+}
+
+// validityClient overrides the validity duration of the requests, which RequestCertificate
+// cannot set beyond the range of time.Duration.
+type validityClient struct {
+	pb.IstioCertificateServiceClient
+	seconds int64
+}
+
+func (c validityClient) CreateCertificate(ctx context.Context, req *pb.IstioCertificateRequest, opts ...grpc.CallOption) (*pb.IstioCertificateResponse, error) {
+	req.ValidityDuration = c.seconds
+	return c.IstioCertificateServiceClient.CreateCertificate(ctx, req, opts...)
+}
+
+func TestRequestCertificateValidity(t *testing.T) {
+	_, client := newClient(t, Options{MaxValidity: 2 * time.Hour})
+	for _, seconds := range []int64{-1, 2*3600 + 1, math.MaxInt64} {
+		_, err := RequestCertificate(context.Background(), validityClient{client, seconds}, reviews, 0, nil)
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("validity of %ds: got %v, want code %v", seconds, err, codes.InvalidArgument)
+		}
+	}
+}
+
+func TestRequestCertificateErrors(t *testing.T) {
+	_, client := newClient(t, Options{})
+	ctx := context.Background()
+	for name, tc := range map[string]struct {
+		identity string
+		metadata map[string]any
+		code     codes.Code
+	}{
+		"trust domain":  {"spiffe://example.com/ns/default/sa/reviews", nil, codes.PermissionDenied},
+		"not spiffe":    {"https://cluster.local/ns/default", nil, codes.PermissionDenied},
+		"impersonation": {reviews, map[string]any{MetadataImpersonatedIdentity: "spiffe://cluster.local/ns/default/sa/admin"}, codes.PermissionDenied},
+		"workload ip":   {reviews, map[string]any{MetadataWorkloadIP: "nope"}, codes.InvalidArgument},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := RequestCertificate(ctx, client, tc.identity, 0, tc.metadata)
+			if status.Code(err) != tc.code {
+				t.Fatalf("got %v, want code %v", err, tc.code)
+			}
+		})
+	}
+	if _, err := client.CreateCertificate(ctx, &pb.IstioCertificateRequest{Csr: "garbage"}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("got %v, want code %v", err, codes.InvalidArgument)
+	}
+}
+
+func TestImpersonation(t *testing.T) {
+	_, client := newClient(t, Options{AllowImpersonation: true})
+	admin := "spiffe://cluster.local/ns/default/sa/admin"
+	cert, err := RequestCertificate(context.Background(), client, reviews, 0, map[string]any{MetadataImpersonatedIdentity: admin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cert.Leaf.URIs[0].String(); got != admin {
+		t.Errorf("got identity %v, want %v", got, admin)
+	}
+}
+
+// TestMutualTLS runs a handshake between two workloads, using certificates obtained over a Unix socket.
+func TestMutualTLS(t *testing.T) {
+	ca, err := New(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "ca.sock")
+	stop, err := ca.ServeUnix(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+	conn, err := grpc.NewClient("unix://"+path, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := pb.NewIstioCertificateServiceClient(conn)
+
+	ctx := context.Background()
+	serverCert, err := RequestCertificate(ctx, client, reviews, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientCert, err := RequestCertificate(ctx, client, "spiffe://cluster.local/ns/default/sa/productpage", 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, s := net.Pipe()
+	server := tls.Server(s, &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientCAs:    ca.RootPool(),
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	})
+	errs := make(chan error, 1)
+	go func() { errs <- server.Handshake() }()
+	tlsClient := tls.Client(c, &tls.Config{
+		Certificates: []tls.Certificate{clientCert},
+		RootCAs:      ca.RootPool(),
+		// Workload certificates carry SPIFFE identities, not DNS names.
+		InsecureSkipVerify: true,
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			_, err := cs.PeerCertificates[0].Verify(x509.VerifyOptions{Roots: ca.RootPool()})
+			return err
+		},
+	})
+	if err := tlsClient.Handshake(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	if got := server.ConnectionState().PeerCertificates[0].URIs[0].String(); got != "spiffe://cluster.local/ns/default/sa/productpage" {
+		t.Errorf("got peer identity %v", got)
+	}
+}
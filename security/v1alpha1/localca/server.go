@@ -0,0 +1,71 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package localca
+
+import (
+	"context"
+	"net"
+	"os"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	pb "istio.io/api/security/v1alpha1"
+)
+
+const bufconnSize = 1 << 20
+
+// Serve serves the CA on lis in the background. The returned function stops the server.
+func (ca *CA) Serve(lis net.Listener) (stop func()) {
+	s := grpc.NewServer()
+	pb.RegisterIstioCertificateServiceServer(s, ca)
+	go func() {
+		_ = s.Serve(lis)
+	}()
+	return s.Stop
+}
+
+// ServeBufconn serves the CA over an in-memory connection and returns a client connection to it.
+// The returned function closes the connection and stops the server.
+func (ca *CA) ServeBufconn() (*grpc.ClientConn, func(), error) {
+	lis := bufconn.Listen(bufconnSize)
+	stop := ca.Serve(lis)
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		stop()
+		return nil, nil, err
+	}
+	return conn, func() {
+		_ = conn.Close()
+		stop()
+	}, nil
+}
+
+// ServeUnix serves the CA on a Unix domain socket at path, which clients can dial as
+// "unix://" + path. The returned function stops the server and removes the socket.
+func (ca *CA) ServeUnix(path string) (func(), error) {
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	stop := ca.Serve(lis)
+	return func() {
+		stop()
+		_ = os.Remove(path)
+	}, nil
+}
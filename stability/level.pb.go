@@ -0,0 +1,149 @@
+// Copyright Istio Authors
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: stability/level.proto
+
+package stability
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Level defines the stability levels Istio uses.
+type Level int32
+
+const (
+	Level_EXPERIMENTAL Level = 0
+	Level_ALPHA        Level = 1
+	Level_BETA         Level = 2
+	Level_STABLE       Level = 3
+)
+
+// Enum value maps for Level.
+var (
+	Level_name = map[int32]string{
+		0: "EXPERIMENTAL",
+		1: "ALPHA",
+		2: "BETA",
+		3: "STABLE",
+	}
+	Level_value = map[string]int32{
+		"EXPERIMENTAL": 0,
+		"ALPHA":        1,
+		"BETA":         2,
+		"STABLE":       3,
+	}
+)
+
+func (x Level) Enum() *Level {
+	p := new(Level)
+	*p = x
+	return p
+}
+
+func (x Level) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Level) Descriptor() protoreflect.EnumDescriptor {
+	return file_stability_level_proto_enumTypes[0].Descriptor()
+}
+
+func (Level) Type() protoreflect.EnumType {
+	return &file_stability_level_proto_enumTypes[0]
+}
+
+func (x Level) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Level.Descriptor instead.
+func (Level) EnumDescriptor() ([]byte, []int) {
+	return file_stability_level_proto_rawDescGZIP(), []int{0}
+}
+
+var File_stability_level_proto protoreflect.FileDescriptor
+
+const file_stability_level_proto_rawDesc = "" +
+	"\n" +
+	"\x15stability/level.proto\x12\x0fistio.stability*:\n" +
+	"\x05Level\x12\x10\n" +
+	"\fEXPERIMENTAL\x10\x00\x12\t\n" +
+	"\x05ALPHA\x10\x01\x12\b\n" +
+	"\x04BETA\x10\x02\x12\n" +
+	"\n" +
+	"\x06STABLE\x10\x03B\x18Z\x16istio.io/api/stabilityb\x06proto3"
+
+var (
+	file_stability_level_proto_rawDescOnce sync.Once
+	file_stability_level_proto_rawDescData []byte
+)
+
+func file_stability_level_proto_rawDescGZIP() []byte {
+	file_stability_level_proto_rawDescOnce.Do(func() {
+		file_stability_level_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_stability_level_proto_rawDesc), len(file_stability_level_proto_rawDesc)))
+	})
+	return file_stability_level_proto_rawDescData
+}
+
+var file_stability_level_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_stability_level_proto_goTypes = []any{
+	(Level)(0), // 0: istio.stability.Level
+}
+var file_stability_level_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_stability_level_proto_init() }
+func file_stability_level_proto_init() {
+	if File_stability_level_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_stability_level_proto_rawDesc), len(file_stability_level_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   0,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_stability_level_proto_goTypes,
+		DependencyIndexes: file_stability_level_proto_depIdxs,
+		EnumInfos:         file_stability_level_proto_enumTypes,
+	}.Build()
+	File_stability_level_proto = out.File
+	file_stability_level_proto_goTypes = nil
+	file_stability_level_proto_depIdxs = nil
+}
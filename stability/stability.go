@@ -0,0 +1,112 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package stability reports the stability Level of Istio API messages and fields.
+//
+// The level of a field is, in order of precedence: the level recorded for the field itself,
+// the level of the message that declares it, or the level of its proto package. Packages
+// default to the level implied by their version (v1alpha1 is ALPHA, v1beta1 is BETA, v1 is
+// STABLE), unless the package is served at a more stable Kubernetes version, as is the case
+// for networking.istio.io/v1alpha3. Messages from outside of Istio, such as well known
+// types, are STABLE.
+package stability
+
+import (
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// packageLevels records the packages whose level differs from the one implied by their version,
+// because their types are served as a more stable API version.
+var packageLevels = map[protoreflect.FullName]Level{
+	"istio.networking.v1alpha3": Level_STABLE, // served as networking.istio.io/v1
+	"istio.security.v1beta1":    Level_STABLE, // served as security.istio.io/v1
+	"istio.telemetry.v1alpha1":  Level_STABLE, // served as telemetry.istio.io/v1
+}
+
+// messageLevels records the messages less stable than their package. Nested messages inherit the
+// level of their parent.
+var messageLevels = map[protoreflect.FullName]Level{
+	"istio.networking.v1alpha3.EnvoyFilter":               Level_ALPHA,
+	"istio.networking.v1alpha3.EnvoyFilter.WaypointMatch": Level_EXPERIMENTAL,
+}
+
+// fieldLevels records the fields less stable than their message. Fields tagged in the proto sources
+// with "+cue-gen:<Kind>:releaseChannel:extended" or "$hide_from_docs", unless deprecated, belong here;
+// TestLevelsMatchProtoSources fails when one is missing.
+var fieldLevels = map[protoreflect.FullName]Level{
+	"istio.networking.v1alpha3.EnvoyFilter.ListenerMatch.port_name":              Level_EXPERIMENTAL,
+	"istio.networking.v1alpha3.EnvoyFilter.EnvoyConfigObjectMatch.waypoint":      Level_EXPERIMENTAL,
+	"istio.networking.v1alpha3.HTTPFaultInjection.Abort.http2_error":             Level_ALPHA,
+	"istio.networking.v1alpha3.HTTPFaultInjection.Delay.exponential_delay":       Level_ALPHA,
+	"istio.networking.v1alpha3.IstioIngressListener.tls":                         Level_ALPHA,
+	"istio.networking.v1alpha3.L4MatchAttributes.source_subnet":                  Level_ALPHA,
+	"istio.networking.v1alpha3.OutboundTrafficPolicy.egress_proxy":               Level_ALPHA,
+	"istio.networking.v1alpha3.Server.default_endpoint":                          Level_ALPHA,
+	"istio.networking.v1alpha3.ServerTLSSettings.TLSCertificate.ca_certificates": Level_ALPHA,
+	"istio.security.v1beta1.AuthorizationPolicy.targetRef":                       Level_ALPHA,
+	"istio.security.v1beta1.RequestAuthentication.targetRef":                     Level_ALPHA,
+	"istio.security.v1beta1.Source.not_trust_domains":                            Level_ALPHA,
+	"istio.security.v1beta1.Source.trust_domains":                                Level_ALPHA,
+	"istio.telemetry.v1alpha1.AccessLogging.filter":                              Level_ALPHA,
+	"istio.telemetry.v1alpha1.Metrics.reporting_interval":                        Level_ALPHA,
+	"istio.telemetry.v1alpha1.Telemetry.targetRef":                               Level_ALPHA,
+	"istio.telemetry.v1alpha1.Tracing.use_request_id_for_trace_sampling":         Level_ALPHA,
+}
+
+// FieldLevel returns the stability level of a field.
+func FieldLevel(fd protoreflect.FieldDescriptor) Level {
+	if l, f := fieldLevels[fd.FullName()]; f {
+		return l
+	}
+	return MessageLevel(fd.ContainingMessage())
+}
+
+// MessageLevel returns the stability level of a message.
+func MessageLevel(md protoreflect.MessageDescriptor) Level {
+	for d := protoreflect.Descriptor(md); d != nil; d = d.Parent() {
+		if l, f := messageLevels[d.FullName()]; f {
+			return l
+		}
+		if _, ok := d.(protoreflect.MessageDescriptor); !ok {
+			break
+		}
+	}
+	return PackageLevel(md.ParentFile().Package())
+}
+
+// PackageLevel returns the stability level of a proto package, such as "istio.networking.v1alpha3".
+func PackageLevel(pkg protoreflect.FullName) Level {
+	if l, f := packageLevels[pkg]; f {
+		return l
+	}
+	if !strings.HasPrefix(string(pkg), "istio.") {
+		return Level_STABLE
+	}
+	version := string(pkg.Name())
+	switch {
+	case strings.Contains(version, "alpha"):
+		return Level_ALPHA
+	case strings.Contains(version, "beta"):
+		return Level_BETA
+	}
+	return Level_STABLE
+}
+
+// ParseLevel returns the level with the given name, such as "ALPHA". Names are case-insensitive.
+func ParseLevel(name string) (Level, bool) {
+	l, f := Level_value[strings.ToUpper(name)]
+	return Level(l), f
+}
@@ -0,0 +1,252 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stability
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	networking "istio.io/api/networking/v1alpha3"
+	_ "istio.io/api/security/v1beta1"
+	_ "istio.io/api/telemetry/v1alpha1"
+)
+
+func TestOverridesExist(t *testing.T) {
+	for name := range packageLevels {
+		found := false
+		protoregistry.GlobalFiles.RangeFilesByPackage(name, func(protoreflect.FileDescriptor) bool {
+			found = true
+			return false
+		})
+		if !found {
+			t.Errorf("unknown package %v", name)
+		}
+	}
+	for name := range messageLevels {
+		if _, err := protoregistry.GlobalFiles.FindDescriptorByName(name); err != nil {
+			t.Errorf("unknown message %v: %v", name, err)
+		}
+	}
+	for name := range fieldLevels {
+		if _, err := protoregistry.GlobalFiles.FindDescriptorByName(name); err != nil {
+			t.Errorf("unknown field %v: %v", name, err)
+		}
+	}
+}
+
+func fieldDescriptor(t *testing.T, name protoreflect.FullName) protoreflect.FieldDescriptor {
+	t.Helper()
+	d, err := protoregistry.GlobalFiles.FindDescriptorByName(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d.(protoreflect.FieldDescriptor)
+}
+
+func TestFieldLevel(t *testing.T) {
+	cases := map[protoreflect.FullName]Level{
+		"istio.networking.v1alpha3.TrafficPolicy.tunnel":                        Level_STABLE,
+		"istio.networking.v1alpha3.L4MatchAttributes.source_subnet":             Level_ALPHA,
+		"istio.networking.v1alpha3.EnvoyFilter.config_patches":                  Level_ALPHA,
+		"istio.networking.v1alpha3.EnvoyFilter.Patch.value":                     Level_ALPHA,
+		"istio.networking.v1alpha3.EnvoyFilter.WaypointMatch.port_number":       Level_EXPERIMENTAL,
+		"istio.networking.v1alpha3.EnvoyFilter.EnvoyConfigObjectMatch.waypoint": Level_EXPERIMENTAL,
+		"istio.security.v1beta1.AuthorizationPolicy.action":                     Level_STABLE,
+		"istio.telemetry.v1alpha1.Telemetry.metrics":                            Level_STABLE,
+		"google.protobuf.Duration.seconds":                                      Level_STABLE,
+	}
+	for name, want := range cases {
+		if got := FieldLevel(fieldDescriptor(t, name)); got != want {
+			t.Errorf("%v: got %v, want %v", name, got, want)
+		}
+	}
+	if got := PackageLevel("istio.extensions.v1alpha1"); got != Level_ALPHA {
+		t.Errorf("got %v, want ALPHA", got)
+	}
+	if got := PackageLevel("istio.networking.v1beta1"); got != Level_BETA {
+		t.Errorf("got %v, want BETA", got)
+	}
+}
+
+func TestFieldsBelow(t *testing.T) {
+	vs := &networking.VirtualService{
+		Hosts: []string{"reviews"},
+		Http: []*networking.HTTPRoute{{
+			Fault: &networking.HTTPFaultInjection{
+				Delay: &networking.HTTPFaultInjection_Delay{
+					HttpDelayType: &networking.HTTPFaultInjection_Delay_ExponentialDelay{ExponentialDelay: durationpb.New(1)},
+				},
+			},
+		}},
+		Tcp: []*networking.TCPRoute{{
+			Match: []*networking.L4MatchAttributes{{Port: 9080}, {SourceSubnet: "10.0.0.0/8"}},
+		}},
+	}
+	var got []string
+	for _, f := range NonStableFields(vs) {
+		got = append(got, f.String())
+	}
+	want := []string{
+		"http[0].fault.delay.exponentialDelay (ALPHA)",
+		"tcp[0].match[1].sourceSubnet (ALPHA)",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if f := FieldsBelow(vs, Level_ALPHA); len(f) != 0 {
+		t.Fatalf("expected no experimental fields, got %v", f)
+	}
+
+	ef := &networking.EnvoyFilter{
+		ConfigPatches: []*networking.EnvoyFilter_EnvoyConfigObjectPatch{{
+			ApplyTo: networking.EnvoyFilter_HTTP_FILTER,
+			Match: &networking.EnvoyFilter_EnvoyConfigObjectMatch{
+				ObjectTypes: &networking.EnvoyFilter_EnvoyConfigObjectMatch_Waypoint{
+					Waypoint: &networking.EnvoyFilter_WaypointMatch{PortNumber: 80},
+				},
+			},
+		}},
+	}
+	if f := NonStableFields(ef); len(f) != 1 || f[0].Path != "configPatches" {
+		t.Fatalf("expected only the outermost alpha field, got %v", f)
+	}
+	if f := FieldsBelow(ef, Level_ALPHA); len(f) != 1 || f[0].Path != "configPatches[0].match.waypoint" {
+		t.Fatalf("expected the experimental waypoint match, got %v", f)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	if l, ok := ParseLevel("beta"); !ok || l != Level_BETA {
+		t.Fatalf("got %v %v", l, ok)
+	}
+	if _, ok := ParseLevel("gamma"); ok {
+		t.Fatal("expected an unknown level")
+	}
+}
+
+var (
+	declarationRegexp = regexp.MustCompile(`^(message|enum|oneof|service)\s+(\w+)\s*\{`)
+	fieldRegexp       = regexp.MustCompile(`^(?:repeated\s+|optional\s+)?(?:map<[^>]+>|[\w.]+)\s+(\w+)\s*=\s*\d+`)
+)
+
+// unstableDeclarations returns the messages and fields of a proto source whose leading comment
+// marks them as not stable: CRD generation serves fields tagged with
+// "+cue-gen:<Kind>:releaseChannel:extended" only in the extended channel, and fields tagged with
+// "$hide_from_docs" are not documented.
+func unstableDeclarations(pkg protoreflect.FullName, src []byte) []protoreflect.FullName {
+	type scope struct {
+		kind, name string
+	}
+	var (
+		out     []protoreflect.FullName
+		scopes  []scope
+		comment []string
+	)
+	fullName := func(name string) protoreflect.FullName {
+		n := pkg
+		for _, s := range scopes {
+			if s.kind == "message" {
+				n = n.Append(protoreflect.Name(s.name))
+			}
+		}
+		return n.Append(protoreflect.Name(name))
+	}
+	unstable := func() bool {
+		c := strings.Join(comment, "\n")
+		return strings.Contains(c, ":releaseChannel:extended") || strings.Contains(c, "$hide_from_docs")
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(src))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "//") {
+			comment = append(comment, line)
+			continue
+		}
+		opens, closes := strings.Count(line, "{"), strings.Count(line, "}")
+		if m := declarationRegexp.FindStringSubmatch(line); m != nil {
+			if m[1] == "message" && unstable() {
+				out = append(out, fullName(m[2]))
+			}
+			scopes = append(scopes, scope{kind: m[1], name: m[2]})
+			opens--
+		} else if m := fieldRegexp.FindStringSubmatch(line); m != nil && !strings.HasPrefix(line, "option") &&
+			len(scopes) > 0 && (scopes[len(scopes)-1].kind == "message" || scopes[len(scopes)-1].kind == "oneof") {
+			if unstable() {
+				out = append(out, fullName(m[1]))
+			}
+		}
+		for ; opens > 0; opens-- {
+			scopes = append(scopes, scope{})
+		}
+		for ; closes > 0 && len(scopes) > 0; closes-- {
+			scopes = scopes[:len(scopes)-1]
+		}
+		comment = nil
+	}
+	return out
+}
+
+// TestLevelsMatchProtoSources fails when a message or field the proto sources mark as not
+// stable is reported as STABLE, which means it is missing from messageLevels or fieldLevels.
+// Deprecated fields are hidden from the docs too, but keep the level of their message.
+func TestLevelsMatchProtoSources(t *testing.T) {
+	checked := 0
+	protoregistry.GlobalFiles.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		if !strings.HasPrefix(string(fd.Package()), "istio.") {
+			return true
+		}
+		src, err := os.ReadFile(filepath.Join("..", fd.Path()))
+		if err != nil {
+			t.Error(err)
+			return true
+		}
+		for _, name := range unstableDeclarations(fd.Package(), src) {
+			checked++
+			d, err := protoregistry.GlobalFiles.FindDescriptorByName(name)
+			if err != nil {
+				t.Errorf("%s: %v", fd.Path(), err)
+				continue
+			}
+			var level Level
+			switch d := d.(type) {
+			case protoreflect.FieldDescriptor:
+				if d.Options().(*descriptorpb.FieldOptions).GetDeprecated() {
+					continue
+				}
+				level = FieldLevel(d)
+			case protoreflect.MessageDescriptor:
+				level = MessageLevel(d)
+			}
+			if level == Level_STABLE {
+				t.Errorf("%v is not stable in %s, but is reported as STABLE", name, fd.Path())
+			}
+		}
+		return true
+	})
+	if checked == 0 {
+		t.Fatal("no unstable declarations found in the proto sources")
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stability
+
+import (
+	"fmt"
+	"sort"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Field is a field set on a message instance.
+type Field struct {
+	// Path is the JSON path of the field within the message, for example "tcp[0].match[0].sourceSubnet".
+	Path string
+	// Descriptor of the field.
+	Descriptor protoreflect.FieldDescriptor
+	// Level is the stability level of the field.
+	Level Level
+}
+
+func (f Field) String() string {
+	return fmt.Sprintf("%v (%v)", f.Path, f.Level)
+}
+
+// NonStableFields returns the fields set on m that are not STABLE.
+func NonStableFields(m proto.Message) []Field {
+	return FieldsBelow(m, Level_STABLE)
+}
+
+// FieldsBelow returns the fields set on m whose level is below min, depth first, in field number order.
+// The fields nested within a reported field are not reported.
+func FieldsBelow(m proto.Message, min Level) []Field {
+	var out []Field
+	walk(m.ProtoReflect(), "", func(fd protoreflect.FieldDescriptor, path string) bool {
+		if l := FieldLevel(fd); l < min {
+			out = append(out, Field{Path: path, Descriptor: fd, Level: l})
+			return false
+		}
+		return true
+	})
+	return out
+}
+
+// walk calls fn for every populated field of m, depth first, in field number order. Fields nested within
+// a field are skipped if fn returns false.
+func walk(m protoreflect.Message, prefix string, fn func(fd protoreflect.FieldDescriptor, path string) bool) {
+	type entry struct {
+		fd protoreflect.FieldDescriptor
+		v  protoreflect.Value
+	}
+	var fields []entry
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		fields = append(fields, entry{fd, v})
+		return true
+	})
+	sort.Slice(fields, func(i, j int) bool { return fields[i].fd.Number() < fields[j].fd.Number() })
+
+	for _, f := range fields {
+		path := f.fd.JSONName()
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+		if !fn(f.fd, path) {
+			continue
+		}
+		switch {
+		case f.fd.IsList():
+			if f.fd.Message() == nil {
+				continue
+			}
+			l := f.v.List()
+			for i := 0; i < l.Len(); i++ {
+				walk(l.Get(i).Message(), fmt.Sprintf("%s[%d]", path, i), fn)
+			}
+		case f.fd.IsMap():
+			if f.fd.MapValue().Message() == nil {
+				continue
+			}
+			var keys []protoreflect.MapKey
+			f.v.Map().Range(func(k protoreflect.MapKey, _ protoreflect.Value) bool {
+				keys = append(keys, k)
+				return true
+			})
+			sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+			for _, k := range keys {
+				walk(f.v.Map().Get(k).Message(), fmt.Sprintf("%s[%s]", path, k.String()), fn)
+			}
+		case f.fd.Message() != nil:
+			walk(f.v.Message(), path, fn)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package strictjson decodes the JSON representation of Istio API messages, reporting the fields
+// that are not part of the message schema instead of silently dropping them.
+//
+// The generated UnmarshalJSON methods ignore unknown fields, so that older clients can read
+// resources written by newer ones. Unmarshal is meant for linters and CI, where a misspelled
+// field such as "retires" should be an error:
+//
+//	vs := &networking.VirtualService{}
+//	err := strictjson.Unmarshal(data, vs)
+package strictjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// UnknownFieldsError is returned when the JSON input contains fields that are not part of the message.
+type UnknownFieldsError struct {
+	// Paths are the JSON paths of the unknown fields, for example "http[0].retires".
+	Paths []string
+}
+
+func (e *UnknownFieldsError) Error() string {
+	return fmt.Sprintf("unknown fields: %v", strings.Join(e.Paths, ", "))
+}
+
+// Unmarshal decodes b into m. Known fields are always decoded; if b contains unknown fields,
+// an *UnknownFieldsError listing all of them is returned.
+func Unmarshal(b []byte, m proto.Message) error {
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(b, m); err != nil {
+		return err
+	}
+	paths, err := UnknownFields(b, m.ProtoReflect().Descriptor())
+	if err != nil {
+		return err
+	}
+	if len(paths) > 0 {
+		return &UnknownFieldsError{Paths: paths}
+	}
+	return nil
+}
+
+// UnknownField is a field of the JSON input that is not part of the message schema.
+type UnknownField struct {
+	// Path is the JSON path of the field, for example "http[0].retires".
+	Path string
+	// Name is the key of the field.
+	Name string
+	// Message is the message the field was found in.
+	Message protoreflect.MessageDescriptor
+}
+
+// UnknownFields returns the JSON paths of the fields in b that are not part of the message md.
+// Fields are visited depth first, in key order.
+func UnknownFields(b []byte, md protoreflect.MessageDescriptor) ([]string, error) {
+	fields, err := FindUnknownFields(b, md)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, f := range fields {
+		paths = append(paths, f.Path)
+	}
+	return paths, nil
+}
+
+// FindUnknownFields is like UnknownFields, but also returns the message holding each unknown
+// field, for example to suggest the closest known field.
+func FindUnknownFields(b []byte, md protoreflect.MessageDescriptor) ([]UnknownField, error) {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var v any
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+	var fields []UnknownField
+	walkMessage(v, md, "", &fields)
+	return fields, nil
+}
+
+func walkMessage(v any, md protoreflect.MessageDescriptor, path string, fields *[]UnknownField) {
+	obj, ok := v.(map[string]any)
+	// Well known types have a custom JSON representation, which protojson validates.
+	if !ok || md.FullName().Parent() == "google.protobuf" {
+		return
+	}
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		p := k
+		if path != "" {
+			p = path + "." + k
+		}
+		fd := md.Fields().ByJSONName(k)
+		if fd == nil {
+			fd = md.Fields().ByTextName(k)
+		}
+		if fd == nil {
+			*fields = append(*fields, UnknownField{Path: p, Name: k, Message: md})
+			continue
+		}
+		walkField(obj[k], fd, p, fields)
+	}
+}
+
+func walkField(v any, fd protoreflect.FieldDescriptor, path string, fields *[]UnknownField) {
+	switch {
+	case fd.IsList():
+		if fd.Message() == nil {
+			return
+		}
+		l, _ := v.([]any)
+		for i, e := range l {
+			walkMessage(e, fd.Message(), fmt.Sprintf("%s[%d]", path, i), fields)
+		}
+	case fd.IsMap():
+		if fd.MapValue().Message() == nil {
+			return
+		}
+		m, _ := v.(map[string]any)
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			walkMessage(m[k], fd.MapValue().Message(), fmt.Sprintf("%s[%s]", path, k), fields)
+		}
+	case fd.Message() != nil:
+		walkMessage(v, fd.Message(), path, fields)
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strictjson_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/api/strictjson"
+)
+
+func TestUnmarshal(t *testing.T) {
+	cases := []struct {
+		name      string
+		in        string
+		want      []string
+		wantError bool
+	}{
+		{
+			name: "valid",
+			in: `{"hosts":["reviews"],"http":[{"retries":{"attempts":3,"perTryTimeout":"2s"},
+				"match":[{"headers":{"end-user":{"exact":"jason"}}}]}]}`,
+		},
+		{
+			name: "proto names",
+			in:   `{"hosts":["reviews"],"export_to":["."],"http":[{"mirror_percentage":{"value":1}}]}`,
+		},
+		{
+			name: "unknown fields",
+			in: `{"hosts":["reviews"],"http":[{"retires":{"attempts":3}},{"match":[{"headers":{"end-user":{"exakt":"jason"}}}]}],
+				"tcp":[{"route":[{"destination":{"host":"mongo","weight":1}}]}],"zzz":true}`,
+			want: []string{"http[0].retires", "http[1].match[0].headers[end-user].exakt", "tcp[0].route[0].destination.weight", "zzz"},
+		},
+		{
+			name:      "type mismatch",
+			in:        `{"hosts":"reviews"}`,
+			wantError: true,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			vs := &networking.VirtualService{}
+			err := strictjson.Unmarshal([]byte(tt.in), vs)
+			var unknown *strictjson.UnknownFieldsError
+			switch {
+			case tt.wantError:
+				if err == nil || errors.As(err, &unknown) {
+					t.Fatalf("expected a decoding error, got %v", err)
+				}
+			case tt.want == nil:
+				if err != nil {
+					t.Fatal(err)
+				}
+			default:
+				if !errors.As(err, &unknown) {
+					t.Fatalf("expected unknown fields, got %v", err)
+				}
+				if !reflect.DeepEqual(unknown.Paths, tt.want) {
+					t.Fatalf("got %v, want %v", unknown.Paths, tt.want)
+				}
+				if len(vs.Hosts) != 1 {
+					t.Fatalf("known fields must still be decoded, got %v", vs)
+				}
+				// The lenient unmarshaler still accepts the input.
+				if err := json.Unmarshal([]byte(tt.in), &networking.VirtualService{}); err != nil {
+					t.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestUnknownFieldsIgnoresStructs(t *testing.T) {
+	in := `{"configPatches":[{"applyTo":"HTTP_FILTER","patch":{"operation":"MERGE","value":{"anything":{"goes":1}}},"tpyo":1}]}`
+	err := strictjson.Unmarshal([]byte(in), &networking.EnvoyFilter{})
+	var unknown *strictjson.UnknownFieldsError
+	if !errors.As(err, &unknown) || !reflect.DeepEqual(unknown.Paths, []string{"configPatches[0].tpyo"}) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestFindUnknownFields(t *testing.T) {
+	in := `{"hosts":["reviews"],"http":[{"retires":{}}],"tpc":[]}`
+	fields, err := strictjson.FindUnknownFields([]byte(in), (&networking.VirtualService{}).ProtoReflect().Descriptor())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range fields {
+		got = append(got, fmt.Sprintf("%s %s %s", f.Path, f.Name, f.Message.FullName()))
+	}
+	want := []string{
+		"http[0].retires retires istio.networking.v1alpha3.HTTPRoute",
+		"tpc tpc istio.networking.v1alpha3.VirtualService",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
@@ -22,6 +22,8 @@ import (
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/yaml"
+
+	"istio.io/api/kubernetes/crd"
 )
 
 type TestExpectation struct {
@@ -29,7 +31,10 @@ type TestExpectation struct {
 }
 
 func TestCRDs(t *testing.T) {
-	v := NewIstioValidator(t)
+	v, err := crd.NewIstioValidator()
+	if err != nil {
+		t.Fatal(err)
+	}
 	d, err := os.ReadDir("testdata")
 	if err != nil {
 		t.Fatal(err)
@@ -40,7 +45,7 @@ func TestCRDs(t *testing.T) {
 			if err != nil {
 				t.Fatal(err)
 			}
-			for _, item := range SplitString(string(f)) {
+			for _, item := range crd.SplitString(string(f)) {
 				obj := &unstructured.Unstructured{}
 				if err := yaml.Unmarshal([]byte(item), obj); err != nil {
 					t.Fatal(err)